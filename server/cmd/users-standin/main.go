@@ -0,0 +1,38 @@
+// Command users-standin serves the stand-in users service so the feed can run
+// without the real one: point USERS_HOST at the address it listens on.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"server/src/standin"
+)
+
+func main() {
+	address := flag.String("addr", "localhost:8081", "address to listen on")
+	seed := flag.String("seed", "", "JSON file with the users to serve")
+	flag.Parse()
+
+	users := []standin.User{}
+
+	if *seed != "" {
+		data, err := os.ReadFile(*seed)
+		if err != nil {
+			log.Fatal("Error reading seed: ", err)
+		}
+		if err := json.Unmarshal(data, &users); err != nil {
+			log.Fatal("Error parsing seed: ", err)
+		}
+	}
+
+	service := standin.NewUsersService(users...)
+
+	log.Println("Stand-in users service running on:", *address, "with", len(users), "users")
+
+	if err := http.ListenAndServe(*address, service.Handler()); err != nil {
+		log.Fatal("Error running server: ", err)
+	}
+}
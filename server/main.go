@@ -8,6 +8,7 @@ import (
 	"context"
 	"server/src/database"
 	"server/src/router"
+	"server/src/service"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -34,6 +35,13 @@ func main() {
 		}
 	}()
 
+	migrationCtx, cancelMigrations := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancelMigrations()
+
+	if err := database.RunMigrations(migrationCtx, client); err != nil {
+		log.Fatal("Error running migrations: ", err)
+	}
+
 	db := database.NewAppDatabase(client)
 
 	// err = db.ClearDB()
@@ -42,7 +50,29 @@ func main() {
 	// 	log.Fatal("Error clearing database: ", err)
 	// }
 
-	r := router.CreateRouter(db)
+	users := service.NewHTTPUsersClient(os.Getenv("USERS_HOST"), service.LoadOutboundConfig("USERS"))
+	notifications := service.NewHTTPNotificationClient(os.Getenv("NOTIF_HOST"), service.LoadOutboundConfig("NOTIF"))
+
+	var events service.EventPublisher = service.NewInProcessPublisher()
+
+	if amqpURL := os.Getenv("AMQP_URL"); amqpURL != "" {
+		exchange := os.Getenv("AMQP_EXCHANGE")
+		if exchange == "" {
+			exchange = service.DEFAULT_EVENTS_EXCHANGE
+		}
+
+		channel := service.NewAMQPChannel(amqpURL)
+		defer channel.Close()
+
+		events = service.NewBrokerPublisher(channel, exchange)
+	}
+
+	dispatcher := service.NewOutboxDispatcher(db, notifications, events, service.LoadOutboxConfig())
+	go dispatcher.Run(context.Background())
+
+	go service.NewTrendingRefresher(db, service.LoadTrendingConfig()).Run(context.Background())
+
+	r := router.CreateRouter(db, users, notifications)
 
 	address := fmt.Sprintf("%s:%s", os.Getenv("HOST"), os.Getenv("PORT"))
 
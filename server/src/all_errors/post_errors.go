@@ -3,6 +3,7 @@ package allerrors
 import (
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	validator "github.com/go-playground/validator/v10"
@@ -31,6 +32,19 @@ func (e TwitSnapError) Status() int {
 
 var ErrTwitsnapNotFound = errors.New("twitsnap not found")
 
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// ErrEditConflict is returned when the post was edited by someone else while
+// an edit was being saved.
+var ErrEditConflict = errors.New("post edited concurrently")
+
+// ErrAlreadyRetweeted is returned when the user already retweeted the post.
+var ErrAlreadyRetweeted = errors.New("already retweeted")
+
+// ErrOutboxLeaseLost is returned when an outbox message is updated by a
+// dispatcher whose lease on it ran out or was taken over.
+var ErrOutboxLeaseLost = errors.New("outbox message lease lost")
+
 func TwitsnapNotFound(id string) TwitSnapError {
 	error := TwitSnapError{
 		"about:blank",
@@ -116,6 +130,17 @@ func AlreadyLiked(postID string) TwitSnapError {
 	return error
 }
 
+func AlreadyRetweeted(postID string) TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Already Retweeted",
+		http.StatusBadRequest,
+		"You have already retweeted this twitsnap: " + postID,
+		"/twitsnap",
+	}
+	return error
+}
+
 func UserInfoError(err string) TwitSnapError {
 	error := TwitSnapError{
 		"about:blank",
@@ -182,12 +207,12 @@ func DatabaseError(err string) TwitSnapError {
 	return error
 }
 
-func AccssDenied() TwitSnapError {
+func ActionDenied(action string) TwitSnapError {
 	error := TwitSnapError{
 		"about:blank",
 		"Access Denied",
 		http.StatusForbidden,
-		"Access Denied, user not Admin",
+		"Access Denied, user may not " + action,
 		"/twitsnap",
 	}
 	return error
@@ -202,4 +227,124 @@ func NotFound() TwitSnapError {
 		"/twitsnap",
 	}
 	return error
-}
\ No newline at end of file
+}
+func RequestTimeout(err string) TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Request Timeout",
+		http.StatusGatewayTimeout,
+		"The request could not be completed in time: " + err,
+		"/twitsnap",
+	}
+	return error
+}
+
+func InvalidCursor(cursor string) TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Invalid Cursor",
+		http.StatusBadRequest,
+		"The pagination cursor is not valid: " + cursor,
+		"/twitsnap",
+	}
+	return error
+}
+
+func DependencyUnavailable(dependency string) TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Service Unavailable",
+		http.StatusServiceUnavailable,
+		"The " + dependency + " service is unavailable, try again later",
+		"/twitsnap",
+	}
+	return error
+}
+
+func BadOutboxStatus(status string) TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Unexpected Format",
+		http.StatusBadRequest,
+		"There is no outbox status like that: " + status,
+		"/twitsnap",
+	}
+	return error
+}
+
+func InvalidTagPrefix() TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Unexpected Format",
+		http.StatusBadRequest,
+		"The prefix must have at least a letter or a digit to suggest hashtags",
+		"/twitsnap",
+	}
+	return error
+}
+
+func InvalidMetricsRange(detail string) TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Unexpected Format",
+		http.StatusBadRequest,
+		"The metrics range is not valid: " + detail,
+		"/twitsnap",
+	}
+	return error
+}
+
+func BadTrendingWindow(window string) TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Unexpected Format",
+		http.StatusBadRequest,
+		"There is no trending window like that: " + window,
+		"/twitsnap/trending",
+	}
+	return error
+}
+
+func InvalidSearchQuery(detail string) TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Unexpected Format",
+		http.StatusBadRequest,
+		"The search is not valid: " + detail,
+		"/twitsnap",
+	}
+	return error
+}
+
+func BadConversationMode(mode string) TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Unexpected Format",
+		http.StatusBadRequest,
+		"A conversation can be seen as a tree or a thread, not as " + mode,
+		"/twitsnap/conversation",
+	}
+	return error
+}
+
+func RevisionNotFound(postID string, revision int) TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Revision Not Found",
+		http.StatusNotFound,
+		"The twitsnap with ID " + postID + " has no revision " + strconv.Itoa(revision),
+		"/twitsnap/revisions/" + postID,
+	}
+	return error
+}
+
+func EditConflict(postID string) TwitSnapError {
+	error := TwitSnapError{
+		"about:blank",
+		"Edit Conflict",
+		http.StatusConflict,
+		"The twitsnap with ID " + postID + " was edited meanwhile, try again",
+		"/twitsnap/" + postID,
+	}
+	return error
+}
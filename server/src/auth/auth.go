@@ -5,6 +5,7 @@ import (
 	"os"
 	"time"
 	"strconv"
+	"sync"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -12,9 +13,10 @@ import (
 var (
 	jwtSecret          string
 	jwtExpirationHours int
+	configOnce         sync.Once
 )
 
-func init() {
+func loadConfig() {
 	jwtSecret = os.Getenv("JWT_SECRET")
 	jwtExpirationHoursStr := os.Getenv("JWT_DURATION_HOURS")
 
@@ -30,6 +32,8 @@ type Claims struct {
 }
 
 func GenerateToken(userId string, username string, userAdmin bool) (string, error) {
+	configOnce.Do(loadConfig)
+
 	if jwtSecret == "" {
 		return "", fmt.Errorf("JWT_SECRET environment variable is not set")
 	}
@@ -58,6 +62,8 @@ func GenerateToken(userId string, username string, userAdmin bool) (string, erro
 }
 
 func ValidateToken(tokenString string) (*Claims, error) {
+	configOnce.Do(loadConfig)
+
 	if jwtSecret == "" {
 		return nil, fmt.Errorf("JWT_SECRET environment variable is not set")
 	}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	postErrors "server/src/all_errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	READ_OPERATION    = "read"
+	WRITE_OPERATION   = "write"
+	FEED_OPERATION    = "feed"
+	SEARCH_OPERATION  = "search"
+	METRICS_OPERATION = "metrics"
+)
+
+var defaultDeadlines = map[string]time.Duration{
+	READ_OPERATION:    5 * time.Second,
+	WRITE_OPERATION:   5 * time.Second,
+	FEED_OPERATION:    10 * time.Second,
+	SEARCH_OPERATION:  10 * time.Second,
+	METRICS_OPERATION: 15 * time.Second,
+}
+
+// loadDeadlines reads the per-operation deadlines from the environment.
+// Each one can be overridden with TIMEOUT_<OPERATION>, e.g. TIMEOUT_FEED=3s.
+func loadDeadlines() map[string]time.Duration {
+	deadlines := map[string]time.Duration{}
+
+	for operation, deadline := range defaultDeadlines {
+		deadlines[operation] = deadline
+
+		value := os.Getenv("TIMEOUT_" + strings.ToUpper(operation))
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			slog.Warn("Invalid timeout, using default", "operation", operation, "value", value, "default", deadline)
+			continue
+		}
+
+		deadlines[operation] = parsed
+	}
+
+	return deadlines
+}
+
+// operationContext derives the context for a handler from the gin request so
+// that a client disconnect or the operation deadline cancels downstream work.
+func (c *PostController) operationContext(ginContext *gin.Context, operation string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ginContext.Request.Context(), c.deadlines[operation])
+}
+
+// abortWithError records err on the gin context, replacing it with a timeout
+// error when the operation context was cancelled or ran out of time.
+func abortWithError(ginContext *gin.Context, ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		slog.Warn("Request cancelled", "path", ginContext.Request.URL.Path, "reason", ctx.Err())
+		err = postErrors.RequestTimeout(ctx.Err().Error())
+	}
+	_ = ginContext.Error(err)
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// limitConfigFromQuery reads the paging parameters of a listing. A cursor, when
+// given, takes the place of the time and skip parameters.
+func limitConfigFromQuery(ginContext *gin.Context) (models.LimitConfig, error) {
+	limitParams := models.NewLimitConfig(ginContext.Query(TIME), ginContext.Query(SKIP), ginContext.Query(LIMIT))
+
+	encoded := ginContext.Query(CURSOR)
+
+	if encoded == "" {
+		return limitParams, nil
+	}
+
+	cursor, err := models.DecodePostCursor(encoded)
+
+	if err != nil {
+		return models.LimitConfig{}, postErrors.InvalidCursor(encoded)
+	}
+
+	limitParams.Cursor = &cursor
+
+	return limitParams, nil
+}
+
+// newPagination describes how to ask for the page after posts: next_offset
+// for offset paging and next_cursor for both modes.
+func newPagination(posts []models.FrontPost, hasMore bool, limitParams models.LimitConfig) models.Pagination {
+	pagination := models.Pagination{Limit: limitParams.Limit}
+
+	if !hasMore {
+		return pagination
+	}
+
+	if limitParams.Cursor == nil {
+		pagination.Next_Offset = limitParams.Skip + limitParams.Limit
+	}
+
+	if len(posts) > 0 {
+		cursor, err := models.NewPostCursor(posts[len(posts)-1])
+
+		if err == nil {
+			pagination.Next_Cursor = cursor.Encode()
+		}
+	}
+
+	return pagination
+}
+
+// newOffsetPagination describes the page after a ranked listing, which can
+// only be paged by offset.
+func newOffsetPagination(hasMore bool, limitParams models.LimitConfig) models.Pagination {
+	pagination := models.Pagination{Limit: limitParams.Limit}
+
+	if hasMore {
+		pagination.Next_Offset = limitParams.Skip + limitParams.Limit
+	}
+
+	return pagination
+}
+
+// trendingCursorFromQuery reads the cursor of a page of a ranking, if any.
+func trendingCursorFromQuery(ginContext *gin.Context) (*models.TrendingCursor, error) {
+	encoded := ginContext.Query(CURSOR)
+
+	if encoded == "" {
+		return nil, nil
+	}
+
+	cursor, err := models.DecodeTrendingCursor(encoded)
+
+	if err != nil {
+		return nil, postErrors.InvalidCursor(encoded)
+	}
+
+	return &cursor, nil
+}
+
+// newTrendingPagination describes the page of a ranking computed at
+// computedAt after this one: next_offset, which ranks again against the time
+// of the next request, and next_cursor, which keeps computedAt.
+func newTrendingPagination(hasMore bool, limitParams models.LimitConfig, computedAt time.Time) models.Pagination {
+	pagination := newOffsetPagination(hasMore, limitParams)
+
+	if hasMore {
+		pagination.Next_Cursor = models.TrendingCursor{Computed_At: computedAt, Skip: pagination.Next_Offset}.Encode()
+	}
+
+	return pagination
+}
+
+// threadCursorFromQuery reads the cursor of a page of a thread, if any.
+func threadCursorFromQuery(ginContext *gin.Context) (*models.ThreadCursor, error) {
+	encoded := ginContext.Query(CURSOR)
+
+	if encoded == "" {
+		return nil, nil
+	}
+
+	cursor, err := models.DecodeThreadCursor(encoded)
+
+	if err != nil {
+		return nil, postErrors.InvalidCursor(encoded)
+	}
+
+	return &cursor, nil
+}
+
+// newThreadPagination describes the page of a thread after the one that
+// ends at next: next_offset when it was asked by offset and next_cursor in
+// both modes.
+func newThreadPagination(next *models.ThreadCursor, after *models.ThreadCursor, limitParams models.LimitConfig) models.Pagination {
+	pagination := models.Pagination{Limit: limitParams.Limit}
+
+	if next == nil {
+		return pagination
+	}
+
+	if after == nil {
+		pagination.Next_Offset = limitParams.Skip + limitParams.Limit
+	}
+
+	pagination.Next_Cursor = next.Encode()
+
+	return pagination
+}
@@ -21,14 +21,25 @@ const (
 	HASTAGS = "tags"
 	WANTED_ID = "wanted_user_id"
 	END_TIME = "end_time"
+	CURSOR = "cursor"
+	STATUS = "status"
+	INTERVAL = "interval"
+	TIMEZONE = "timezone"
+	VISIBILITY = "visibility"
+	WINDOW = "window"
+	SORT = "sort"
+	QUERY = "q"
+	PREFIX = "prefix"
+	MODE = "mode"
 )
 
 type PostController struct {
-	sv *service.Service
+	sv        *service.Service
+	deadlines map[string]time.Duration
 }
 
-func NewPostController(sv database.Database) *PostController {
-	return &PostController{sv: service.NewService(sv)}
+func NewPostController(db database.Database, users service.UsersClient, notifications service.NotificationClient) *PostController {
+	return &PostController{sv: service.NewService(db, users, notifications), deadlines: loadDeadlines()}
 }
 
 func (c *PostController) NewPost(context *gin.Context) {
@@ -42,10 +53,13 @@ func (c *PostController) NewPost(context *gin.Context) {
 		return
 	}
 
-	postNew, err := c.sv.CreatePost(&newPost, author_id.(string), token.(string))
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	postNew, err := c.sv.CreatePost(ctx, &newPost, author_id.(string), token.(string))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
@@ -58,10 +72,13 @@ func (c *PostController) GetPostByID(context *gin.Context) {
 	token, _ := context.Get("tokenString")
 	author_id, _ := context.Get("session_user_id")
 
-	post, err := c.sv.FetchPostByID(postID, token.(string), author_id.(string))
+	ctx, cancel := c.operationContext(context, READ_OPERATION)
+	defer cancel()
+
+	post, err := c.sv.FetchPostByID(ctx, postID, token.(string), author_id.(string))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
@@ -72,10 +89,13 @@ func (c *PostController) DeletePostByID(context *gin.Context) {
 
 	postID := context.Param("id")
 
-	err := c.sv.RemovePostByID(postID)
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	err := c.sv.RemovePostByID(ctx, postID, sessionOf(context))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
@@ -86,7 +106,6 @@ func (c *PostController) UpdatePostByID(context *gin.Context) {
 	
 	postID := context.Param("id")
 	token, _ := context.Get("tokenString")
-	author_id, _ := context.Get("session_user_id")
 
 	var editInfo models.EditPostExpectedFormat
 	if err := context.ShouldBind(&editInfo); err != nil {
@@ -94,44 +113,191 @@ func (c *PostController) UpdatePostByID(context *gin.Context) {
 		return
 	}
 
-	modPost, err := c.sv.ModifyPostByID(postID, editInfo, token.(string), author_id.(string))
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	modPost, err := c.sv.ModifyPostByID(ctx, postID, editInfo, token.(string), sessionOf(context))
 
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
 	context.JSON(http.StatusOK, modPost)
 }
 
+func (c *PostController) GetRevisions(context *gin.Context) {
+	postID := context.Param("id")
+
+	limitParams := models.NewLimitConfig("", context.Query(SKIP), context.Query(LIMIT))
+
+	if limitParams.Limit <= 0 {
+		limitParams.Limit = service.REVISIONS_PAGE_LIMIT
+	}
+
+	ctx, cancel := c.operationContext(context, READ_OPERATION)
+	defer cancel()
+
+	revisions, hasMore, err := c.sv.FetchRevisions(ctx, postID, limitParams)
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
+	}
+
+	result := models.ReturnPaginatedRevisions{
+		Post_ID:    postID,
+		Data:       revisions,
+		Pagination: newOffsetPagination(hasMore, limitParams),
+	}
+
+	context.JSON(http.StatusOK, result)
+}
+
+func (c *PostController) RevertPost(context *gin.Context) {
+	postID := context.Param("id")
+	token, _ := context.Get("tokenString")
+
+	var revertInfo models.RevertExpectedFormat
+	if err := context.ShouldBind(&revertInfo); err != nil {
+		_ = context.Error(postErrors.UnexpectedFormat())
+		return
+	}
+
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	post, err := c.sv.RevertPost(ctx, postID, revertInfo, sessionOf(context), token.(string))
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, post)
+}
+
 func (c *PostController) NewPostRetweet(context *gin.Context) {
 	postID := context.Param("id")
 	token, _ := context.Get("tokenString")
 	author_id, _ := context.Get("session_user_id")
 
-	newRetweet, err := c.sv.RetweetPost(postID, author_id.(string), token.(string))
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	newRetweet, err := c.sv.RetweetPost(ctx, postID, author_id.(string), token.(string))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 	
 	context.JSON(http.StatusCreated, newRetweet)
 }
 
-func (c *PostController) DeleteRetweet(context *gin.Context) {
+func (c *PostController) NewQuote(context *gin.Context) {
+	postID := context.Param("id")
+	token, _ := context.Get("tokenString")
+	author_id, _ := context.Get("session_user_id")
 
+	var newPost models.PostExpectedFormat
+	if err := context.ShouldBind(&newPost); err != nil {
+		_ = context.Error(postErrors.UnexpectedFormat())
+		return
+	}
+
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	quote, err := c.sv.QuotePost(ctx, postID, &newPost, author_id.(string), token.(string))
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
+	}
+
+	context.JSON(http.StatusCreated, quote)
+}
+
+func (c *PostController) NewReply(context *gin.Context) {
 	postID := context.Param("id")
+	token, _ := context.Get("tokenString")
 	author_id, _ := context.Get("session_user_id")
 
-	err := c.sv.RemoveRetweet(postID, author_id.(string))
+	var newPost models.PostExpectedFormat
+	if err := context.ShouldBind(&newPost); err != nil {
+		_ = context.Error(postErrors.UnexpectedFormat())
+		return
+	}
+
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	reply, err := c.sv.ReplyToPost(ctx, postID, &newPost, author_id.(string), token.(string))
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
+	}
+
+	context.JSON(http.StatusCreated, reply)
+}
+
+// GetConversation answers with the conversation as a tree, or with a page of
+// it flattened in reading order for the thread mode.
+func (c *PostController) GetConversation(context *gin.Context) {
+	postID := context.Param("id")
+	token, _ := context.Get("tokenString")
+	userID, _ := context.Get("session_user_id")
+
+	mode := context.Query(MODE)
+	limitParams := models.NewLimitConfig("", context.Query(SKIP), context.Query(LIMIT))
+
+	if limitParams.Limit <= 0 {
+		limitParams.Limit = service.CONVERSATION_PAGE_LIMIT
+	}
+
+	after, err := threadCursorFromQuery(context)
 
 	if err != nil {
 		_ = context.Error(err)
 		return
 	}
 
+	ctx, cancel := c.operationContext(context, READ_OPERATION)
+	defer cancel()
+
+	tree, thread, next, err := c.sv.FetchConversation(ctx, postID, mode, limitParams, after, userID.(string), token.(string))
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
+	}
+
+	if mode == models.THREAD_CONVERSATION {
+		context.JSON(http.StatusOK, models.ReturnPaginatedPosts{Data: thread, Pagination: newThreadPagination(next, after, limitParams)})
+		return
+	}
+
+	context.JSON(http.StatusOK, tree)
+}
+
+func (c *PostController) DeleteRetweet(context *gin.Context) {
+
+	postID := context.Param("id")
+	author_id, _ := context.Get("session_user_id")
+
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	err := c.sv.RemoveRetweet(ctx, postID, author_id.(string))
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
+	}
+
 	context.JSON(http.StatusNoContent, gin.H{})
 }
 
@@ -139,31 +305,32 @@ func (c *PostController) GetUserFeed(context *gin.Context) {
 	token, _ := context.Get("tokenString")
 	author_id, _ := context.Get("session_user_id")
 	
-	time := context.Query(TIME)
-	skip := context.Query(SKIP)
-	limit := context.Query(LIMIT)
 	feed_type := context.Query(FEED)
 	wanted_id := context.Query(WANTED_ID)
 
 	feedRequest := models.FeedRequesst{FeedType: feed_type, WantedUserID: wanted_id}
 
 
-	limitParams := models.NewLimitConfig(time, skip, limit)
-
-	posts, hasMore, err := c.sv.FetchUserFeed(&feedRequest, author_id.(string), limitParams, token.(string))
+	limitParams, err := limitConfigFromQuery(context)
 
 	if err != nil {
 		_ = context.Error(err)
 		return
 	}
 
-	result := models.ReturnPaginatedPosts{
-		Data: posts,
-		Pagination: models.Pagination{Limit: limitParams.Limit},
+	ctx, cancel := c.operationContext(context, FEED_OPERATION)
+	defer cancel()
+
+	posts, hasMore, err := c.sv.FetchUserFeed(ctx, &feedRequest, author_id.(string), limitParams, token.(string))
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
 	}
 
-	if hasMore {
-		result.Pagination.Next_Offset =limitParams.Skip + limitParams.Limit
+	result := models.ReturnPaginatedPosts{
+		Data: posts,
+		Pagination: newPagination(posts, hasMore, limitParams),
 	}
 
 	context.JSON(http.StatusOK, result)
@@ -171,33 +338,30 @@ func (c *PostController) GetUserFeed(context *gin.Context) {
 
 func (c *PostController) GetAllPosts(context *gin.Context) {
 	token, _ := context.Get("tokenString")
-	isUserAdmin, _ := context.Get("session_user_admin")
-
-	if !isUserAdmin.(bool) {
-		_ = context.Error(postErrors.AccssDenied())
+	if !authorizeAdminListing(context) {
 		return
 	}
 
-	time := context.Query(TIME)
-	skip := context.Query(SKIP)
-	limit := context.Query(LIMIT)
+	limitParams, err := limitConfigFromQuery(context)
+
+	if err != nil {
+		_ = context.Error(err)
+		return
+	}
 
-	limitParams := models.NewLimitConfig(time, skip, limit)
+	ctx, cancel := c.operationContext(context, FEED_OPERATION)
+	defer cancel()
 
-	posts, hasMore, err := c.sv.FetchAllPosts(limitParams, token.(string))
+	posts, hasMore, err := c.sv.FetchAllPosts(ctx, limitParams, token.(string))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
 	result := models.ReturnPaginatedPosts{
 		Data: posts,
-		Pagination: models.Pagination{Limit: limitParams.Limit},
-	}
-
-	if hasMore {
-		result.Pagination.Next_Offset =limitParams.Skip + limitParams.Limit
+		Pagination: newPagination(posts, hasMore, limitParams),
 	}
 
 	context.JSON(http.StatusOK, result)
@@ -209,26 +373,28 @@ func (c *PostController) HashtagsSearch(context *gin.Context) {
 
 
 	hashtags := context.QueryArray(HASTAGS)
-	time := context.Query(TIME)
-	skip := context.Query(SKIP)
-	limit := context.Query(LIMIT)
+	query := context.Query(QUERY)
 
-	limitParams := models.NewLimitConfig(time, skip, limit)
-
-	posts, hasMore, err := c.sv.FetchUserPostsByHashtags(hashtags, limitParams, userID.(string), token.(string))
+	limitParams, err := limitConfigFromQuery(context)
 
 	if err != nil {
 		_ = context.Error(err)
 		return
 	}
 
-	result := models.ReturnPaginatedPosts{
-		Data: posts,
-		Pagination: models.Pagination{Limit: limitParams.Limit},
+	ctx, cancel := c.operationContext(context, SEARCH_OPERATION)
+	defer cancel()
+
+	posts, hasMore, err := c.sv.FetchUserPostsByHashtags(ctx, query, hashtags, limitParams, userID.(string), token.(string))
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
 	}
 
-	if hasMore {
-		result.Pagination.Next_Offset =limitParams.Skip + limitParams.Limit
+	result := models.ReturnPaginatedPosts{
+		Data: posts,
+		Pagination: newPagination(posts, hasMore, limitParams),
 	}
 
 
@@ -240,27 +406,33 @@ func (c *PostController) WordsSearch(context *gin.Context) {
 	userID, _ := context.Get("session_user_id")
 
 	words := context.Query(WORDS)
+	sort := context.Query(SORT)
 
-	time := context.Query(TIME)
-	skip := context.Query(SKIP)
-	limit := context.Query(LIMIT)
+	limitParams, err := limitConfigFromQuery(context)
+
+	if err != nil {
+		_ = context.Error(err)
+		return
+	}
 
-	limitParams := models.NewLimitConfig(time, skip, limit)
+	ctx, cancel := c.operationContext(context, SEARCH_OPERATION)
+	defer cancel()
 
-	posts, hasMore, err := c.sv.WordsSearch(words, limitParams, userID.(string), token.(string))
+	posts, hasMore, err := c.sv.WordsSearch(ctx, words, sort, limitParams, userID.(string), token.(string))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
 	result := models.ReturnPaginatedPosts{
 		Data: posts,
-		Pagination: models.Pagination{Limit: limitParams.Limit},
+		Pagination: newPagination(posts, hasMore, limitParams),
 	}
 
-	if hasMore {
-		result.Pagination.Next_Offset =limitParams.Skip + limitParams.Limit
+	// Relevance has no cursor order, so its pages go by offset.
+	if sort == models.RELEVANCE_SORT {
+		result.Pagination = newOffsetPagination(hasMore, limitParams)
 	}
 
 
@@ -275,10 +447,73 @@ func (c *PostController) GetUserMetrics(context *gin.Context) {
 
 	limits := models.MetricLimits{FromTime: time, ToTime: end_time}
 
-	metrics, err := c.sv.GetUserMetrics(userID.(string), limits)
+	ctx, cancel := c.operationContext(context, METRICS_OPERATION)
+	defer cancel()
+
+	// Asking for an interval or a timezone switches to the time series.
+	if context.Query(INTERVAL) != "" || context.Query(TIMEZONE) != "" {
+		limits.Interval = context.Query(INTERVAL)
+		limits.Timezone = context.Query(TIMEZONE)
+
+		series, err := c.sv.GetUserMetricsSeries(ctx, userID.(string), limits)
+
+		if err != nil {
+			abortWithError(context, ctx, err)
+			return
+		}
+
+		context.JSON(http.StatusOK, series)
+		return
+	}
+
+	metrics, err := c.sv.GetUserMetrics(ctx, userID.(string), limits)
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, metrics)
+}
+
+func (c *PostController) GetPlatformMetrics(context *gin.Context) {
+	if !authorizeAdminListing(context) {
+		return
+	}
+
+	limits := models.MetricLimits{
+		FromTime:   context.Query(TIME),
+		ToTime:     context.Query(END_TIME),
+		Interval:   context.Query(INTERVAL),
+		Timezone:   context.Query(TIMEZONE),
+		Visibility: context.Query(VISIBILITY),
+	}
+
+	ctx, cancel := c.operationContext(context, METRICS_OPERATION)
+	defer cancel()
+
+	metrics, err := c.sv.GetPlatformMetrics(ctx, limits)
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, metrics)
+}
+
+func (c *PostController) GetTagMetrics(context *gin.Context) {
+	tag := context.Param("tag")
+
+	limits := models.MetricLimits{FromTime: context.Query(TIME), ToTime: context.Query(END_TIME), Interval: context.Query(INTERVAL)}
+
+	ctx, cancel := c.operationContext(context, METRICS_OPERATION)
+	defer cancel()
+
+	metrics, err := c.sv.GetTagMetrics(ctx, tag, limits)
+
+	if err != nil {
+		abortWithError(context, ctx, err)
 		return
 	}
 
@@ -286,25 +521,134 @@ func (c *PostController) GetUserMetrics(context *gin.Context) {
 }
 
 func (c *PostController) GetTrendingTopics(context *gin.Context) {
-	tokens, err := c.sv.GetTrendingTopics()
+	ctx, cancel := c.operationContext(context, METRICS_OPERATION)
+	defer cancel()
+
+	limitParams := models.NewLimitConfig("", context.Query(SKIP), context.Query(LIMIT))
+
+	tokens, err := c.sv.GetTrendingTopics(ctx, context.Query(WINDOW), limitParams)
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
 	context.JSON(http.StatusOK, tokens)
 }
 
+func (c *PostController) GetTrendingPosts(context *gin.Context) {
+	token, _ := context.Get("tokenString")
+	userID, _ := context.Get("session_user_id")
+
+	limitParams := models.NewLimitConfig("", context.Query(SKIP), context.Query(LIMIT))
+
+	if limitParams.Limit <= 0 {
+		limitParams.Limit = service.TRENDING_PAGE_LIMIT
+	}
+
+	cursor, err := trendingCursorFromQuery(context)
+
+	if err != nil {
+		_ = context.Error(err)
+		return
+	}
+
+	computedAt := time.Now().UTC()
+
+	if cursor != nil {
+		computedAt = cursor.Computed_At
+		limitParams.Skip = cursor.Skip
+	}
+
+	ctx, cancel := c.operationContext(context, FEED_OPERATION)
+	defer cancel()
+
+	posts, hasMore, err := c.sv.GetTrendingPosts(ctx, context.Query(WINDOW), userID.(string), limitParams, computedAt, token.(string))
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
+	}
+
+	result := models.ReturnPaginatedPosts{
+		Data:       posts,
+		Pagination: newTrendingPagination(hasMore, limitParams, computedAt),
+	}
+
+	context.JSON(http.StatusOK, result)
+}
+
+func (c *PostController) GetTagSuggestions(context *gin.Context) {
+	token, _ := context.Get("tokenString")
+	userID, _ := context.Get("session_user_id")
+
+	prefix := context.Query(PREFIX)
+	limit := models.NewLimitConfig("", "", context.Query(LIMIT)).Limit
+
+	ctx, cancel := c.operationContext(context, SEARCH_OPERATION)
+	defer cancel()
+
+	suggestions, err := c.sv.SuggestTags(ctx, prefix, limit, userID.(string), token.(string))
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, models.ReturnTagSuggestions{Prefix: prefix, Data: suggestions})
+}
+
+// InvalidateFollowing is called by the users service when the user follows or
+// unfollows someone.
+func (c *PostController) InvalidateFollowing(context *gin.Context) {
+	userID := context.Param("id")
+
+	if err := c.sv.InvalidateFollowing(userID, sessionOf(context)); err != nil {
+		_ = context.Error(err)
+		return
+	}
+
+	context.JSON(http.StatusNoContent, gin.H{})
+}
+
+func (c *PostController) GetCacheMetrics(context *gin.Context) {
+	if !authorizeAdminListing(context) {
+		return
+	}
+
+	context.JSON(http.StatusOK, c.sv.GetCacheMetrics())
+}
+
+func (c *PostController) GetOutbox(context *gin.Context) {
+	if !authorizeAdminListing(context) {
+		return
+	}
+
+	ctx, cancel := c.operationContext(context, READ_OPERATION)
+	defer cancel()
+
+	outbox, err := c.sv.GetOutbox(ctx, context.DefaultQuery(STATUS, models.OUTBOX_PENDING))
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, outbox)
+}
+
 
 func (c *PostController) LikePost(context *gin.Context) {
 	postID := context.Param("id")
 	userID, _ := context.Get("session_user_id")
 
-	err := c.sv.LikePost(postID, userID.(string))
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	err := c.sv.LikePost(ctx, postID, userID.(string))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
@@ -317,10 +661,13 @@ func (c *PostController) UnLikePost(context *gin.Context) {
 	postID := context.Param("id")
 	userID, _ := context.Get("session_user_id")
 
-	err := c.sv.UnLikePost(postID, userID.(string))
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	err := c.sv.UnLikePost(ctx, postID, userID.(string))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
@@ -330,10 +677,13 @@ func (c *PostController) UnLikePost(context *gin.Context) {
 func (c *PostController) BlockPost(context *gin.Context) {
 	postID := context.Param("id")
 
-	err := c.sv.BlockPost(postID)
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	err := c.sv.BlockPost(ctx, postID, sessionOf(context))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
@@ -343,10 +693,13 @@ func (c *PostController) BlockPost(context *gin.Context) {
 func (c *PostController) UnBlockPost(context *gin.Context) {
 	postID := context.Param("id")
 
-	err := c.sv.UnBlockPost(postID)
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	err := c.sv.UnBlockPost(ctx, postID, sessionOf(context))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
@@ -357,10 +710,13 @@ func (c *PostController) BookmarkPost(context *gin.Context) {
 	postID := context.Param("id")
 	userID, _ := context.Get("session_user_id")
 
-	err := c.sv.BookmarkPost(postID, userID.(string))
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	err := c.sv.BookmarkPost(ctx, postID, userID.(string))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
@@ -371,10 +727,13 @@ func (c *PostController) UnBookmarkPost(context *gin.Context) {
 	postID := context.Param("id")
 	userID, _ := context.Get("session_user_id")
 
-	err := c.sv.UnBookmarkPost(postID, userID.(string))
+	ctx, cancel := c.operationContext(context, WRITE_OPERATION)
+	defer cancel()
+
+	err := c.sv.UnBookmarkPost(ctx, postID, userID.(string))
 
 	if err != nil {
-		_ = context.Error(err)
+		abortWithError(context, ctx, err)
 		return
 	}
 
@@ -384,27 +743,28 @@ func (c *PostController) UnBookmarkPost(context *gin.Context) {
 func (c *PostController) GetBookmarks(context *gin.Context) {
 	token, _ := context.Get("tokenString")
 
-	time := context.Query(TIME)
-	skip := context.Query(SKIP)
-	limit := context.Query(LIMIT)
 	wanted_id := context.Query(WANTED_ID)
 
-	limitParams := models.NewLimitConfig(time, skip, limit)
-
-	bookmarks, hasMore, err := c.sv.GetUserFavorites(wanted_id, limitParams, token.(string))
+	limitParams, err := limitConfigFromQuery(context)
 
 	if err != nil {
 		_ = context.Error(err)
 		return
 	}
 
-	result := models.ReturnPaginatedPosts{
-		Data: bookmarks,
-		Pagination: models.Pagination{Limit: limitParams.Limit},
+	ctx, cancel := c.operationContext(context, FEED_OPERATION)
+	defer cancel()
+
+	bookmarks, hasMore, err := c.sv.GetUserFavorites(ctx, wanted_id, limitParams, sessionOf(context), token.(string))
+
+	if err != nil {
+		abortWithError(context, ctx, err)
+		return
 	}
 
-	if hasMore {
-		result.Pagination.Next_Offset =limitParams.Skip + limitParams.Limit
+	result := models.ReturnPaginatedPosts{
+		Data: bookmarks,
+		Pagination: newPagination(bookmarks, hasMore, limitParams),
 	}
 
 
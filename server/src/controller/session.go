@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"server/src/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sessionOf returns who made the request, as the auth middleware left it in
+// the context.
+func sessionOf(context *gin.Context) service.Session {
+	userID, _ := context.Get("session_user_id")
+	isUserAdmin, _ := context.Get("session_user_admin")
+
+	id, _ := userID.(string)
+	admin, _ := isUserAdmin.(bool)
+
+	return service.Session{UserID: id, Admin: admin}
+}
+
+// authorizeAdminListing reports a denial on the context unless the session
+// may see the admin listings.
+func authorizeAdminListing(context *gin.Context) bool {
+	if err := service.Authorize(sessionOf(context), service.ADMIN_LISTING_ACTION, ""); err != nil {
+		_ = context.Error(err)
+		return false
+	}
+	return true
+}
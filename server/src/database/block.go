@@ -3,37 +3,60 @@ package database
 import (
 	"context"
 	"log"
-
+	postErrors "server/src/all_errors"
+	"server/src/models"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
 
-func (d *AppDatabase) BlockPost(postId string) error {
-	postCollection := d.db.Collection(FEED_COLLECTION)
-
-	filter := bson.M{POST_ID_FIELD: postId}
-	update := bson.M{"$set": bson.M{BLOCKED_FIELD: true}}
-
-	_, err := postCollection.UpdateOne(context.Background(), filter, update)
-
-	if err != nil {
-		log.Println(err)
-	}
+func (d *AppDatabase) BlockPost(ctx context.Context, postId string) error {
+	return d.setBlocked(ctx, "BlockPost", postId, true, models.BLOCKED_CONTENT)
+}
 
-	return err
+func (d *AppDatabase) UnBlockPost(ctx context.Context, postId string) error {
+	return d.setBlocked(ctx, "UnBlockPost", postId, false, models.UNBLOCKED_CONTENT)
 }
 
-func (d *AppDatabase) UnBlockPost(postId string) error {
+// setBlocked blocks or unblocks the post and writes the event of type
+// messageType in the same write.
+func (d *AppDatabase) setBlocked(ctx context.Context, operation string, postId string, blocked bool, messageType string) error {
 	postCollection := d.db.Collection(FEED_COLLECTION)
 
 	filter := bson.M{POST_ID_FIELD: postId}
-	update := bson.M{"$set": bson.M{BLOCKED_FIELD: false}}
+	changed := false
+
+	steps := []writeStep{
+		{
+			name: STEP_POST,
+			apply: func(ctx context.Context) error {
+				result, err := postCollection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{BLOCKED_FIELD: blocked}})
+				if err != nil {
+					return err
+				}
+
+				if result.MatchedCount == 0 {
+					return postErrors.ErrTwitsnapNotFound
+				}
+
+				changed = result.ModifiedCount > 0
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				if !changed {
+					return nil
+				}
+				_, err := postCollection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{BLOCKED_FIELD: !blocked}})
+				return err
+			},
+		},
+		d.eventStep(models.NewContentChangeEvent(messageType, postId)),
+	}
 
-	_, err := postCollection.UpdateOne(context.Background(), filter, update)
+	err := d.runWrite(ctx, operation, steps)
 
 	if err != nil {
 		log.Println(err)
 	}
 
 	return err
-}
\ No newline at end of file
+}
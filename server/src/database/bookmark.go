@@ -5,19 +5,18 @@ import (
 	"log"
 	postErrors "server/src/all_errors"
 	"server/src/models"
-	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func (d *AppDatabase) AddFavorite(postID string, userID string) error {
+func (d *AppDatabase) AddFavorite(ctx context.Context, postID string, userID string) error {
 	favoritesCollection := d.db.Collection(BOOKMARK_COLLECTION)
 
 	filter := bson.M{AUTHOR_ID_FIELD: userID}
 	update := bson.M{"$addToSet": bson.M{POST_ID_FIELD: postID}}
 
-	_, err := favoritesCollection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	_, err := favoritesCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
 
 	if err != nil {
 		log.Println(err)
@@ -26,13 +25,13 @@ func (d *AppDatabase) AddFavorite(postID string, userID string) error {
 	return err
 }
 
-func (d *AppDatabase) RemoveFavorite(postID string, userID string) error {
+func (d *AppDatabase) RemoveFavorite(ctx context.Context, postID string, userID string) error {
 	favoritesCollection := d.db.Collection(BOOKMARK_COLLECTION)
 
 	filter := bson.M{AUTHOR_ID_FIELD: userID}
 	update := bson.M{"$pull": bson.M{POST_ID_FIELD: postID}}
 
-	_, err := favoritesCollection.UpdateOne(context.Background(), filter, update)
+	_, err := favoritesCollection.UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		log.Println(err)
@@ -41,20 +40,14 @@ func (d *AppDatabase) RemoveFavorite(postID string, userID string) error {
 	return err
 }
 
-func (d *AppDatabase) GetUserFavorites(userID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+func (d *AppDatabase) GetUserFavorites(ctx context.Context, userID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
 
 	favoritesCollection := d.db.Collection(BOOKMARK_COLLECTION)
 	postCollection := d.db.Collection(FEED_COLLECTION)
 
-	parsedTime, err := time.Parse(time.RFC3339, limitConfig.FromTime)
-
-	if err != nil {
-		log.Println(err)
-	}
-
 	filter := bson.M{AUTHOR_ID_FIELD: userID}
 
-	cursor, err := favoritesCollection.Find(context.Background(), filter, options.Find())
+	cursor, err := favoritesCollection.Find(ctx, filter, options.Find())
 
 	if err != nil {
 		log.Println(err)
@@ -63,7 +56,7 @@ func (d *AppDatabase) GetUserFavorites(userID string, limitConfig models.LimitCo
 
 	postIDs := []string{}
 
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var res bson.M
 		err = cursor.Decode(&res)
 		if err != nil {
@@ -84,17 +77,21 @@ func (d *AppDatabase) GetUserFavorites(userID string, limitConfig models.LimitCo
 		}
 	}
 
-	filter = bson.M{POST_ID_FIELD: bson.M{"$in": postIDs}, TIME_FIELD: bson.M{"$lt": parsedTime.UTC()}}
+	if err = cursor.Err(); err != nil {
+		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
+	}
+
+	filter = bson.M{POST_ID_FIELD: bson.M{"$in": postIDs}}
 
-	cursor, err = postCollection.Find(context.Background(), filter, options.Find().
-		SetSort(bson.M{TIME_FIELD: -1}).SetSkip(int64(limitConfig.Skip)).SetLimit(int64(limitConfig.Limit)+1))
+	cursor, err = postCollection.Find(ctx, pageFilter(filter, limitConfig), pageOptions(limitConfig))
 
 	if err != nil {
 		log.Println(err)
 		return nil, false, postErrors.DatabaseError(err.Error())
 	}
 
-	posts, err := d.createPostList(cursor, userID)
+	posts, err := d.createPostList(ctx, cursor, userID)
 
 	if err != nil {
 		log.Println(err)
@@ -103,5 +100,9 @@ func (d *AppDatabase) GetUserFavorites(userID string, limitConfig models.LimitCo
 
 	hasMore := len(posts) > limitConfig.Limit
 
+	if hasMore {
+		posts = posts[:len(posts)-1]
+	}
+
 	return posts, hasMore, nil
 }
\ No newline at end of file
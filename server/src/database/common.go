@@ -5,15 +5,17 @@ import (
 	"log"
 	postErrors "server/src/all_errors"
 	"server/src/models"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func (d *AppDatabase) findPost(postID string, postCollection *mongo.Collection) (models.DBPost, error) {
+func (d *AppDatabase) findPost(ctx context.Context, postID string, postCollection *mongo.Collection) (models.DBPost, error) {
 	var post models.DBPost
 	filter := bson.M{POST_ID_FIELD: postID, BLOCKED_FIELD: false}
-	err := postCollection.FindOne(context.Background(), filter).Decode(&post)
+	err := postCollection.FindOne(ctx, filter).Decode(&post)
 	if err != nil {
 		log.Println(err)
 		err = postErrors.ErrTwitsnapNotFound
@@ -21,63 +23,86 @@ func (d *AppDatabase) findPost(postID string, postCollection *mongo.Collection)
 	return post, err
 }
 
-func (d *AppDatabase) createPostList(cursor *mongo.Cursor, askerID string) ([]models.FrontPost, error) {
+func (d *AppDatabase) createPostList(ctx context.Context, cursor *mongo.Cursor, askerID string) ([]models.FrontPost, error) {
 	var posts []models.FrontPost
-	var err error
+	var dbPosts []models.DBPost
 
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var dbPost models.DBPost
-		err = cursor.Decode(&dbPost)
+		err := cursor.Decode(&dbPost)
 		if err != nil {
 			return nil, err
 		}
 
-		frontPost, err_2 := d.makeDBPostIntoFrontPost(dbPost, askerID)
-
-		if err_2 != nil {
-			return nil, err_2
-		}
+		dbPosts = append(dbPosts, dbPost)
+	}
 
-		posts = append(posts, frontPost)
+	if err := cursor.Err(); err != nil {
+		return nil, err
 	}
 
-	return posts, err
-}
+	state, err := d.loadViewerState(ctx, dbPosts, askerID)
 
-func (d *AppDatabase) makeDBPostIntoFrontPost(post models.DBPost, askerID string) (models.FrontPost, error) {
-	author := models.AuthorInfo{
-		Author_ID: post.Author_ID,
-		Username:  "username",
-		Alias:     "alias",
-		PthotoURL: "photourl",
+	if err != nil {
+		return nil, err
 	}
 
-	if len(post.Tags) == 0 {
-		post.Tags = []string{}
+	for _, dbPost := range dbPosts {
+		posts = append(posts, state.frontPost(dbPost))
 	}
 
-	if len(post.Mentions) == 0 {
-		post.Mentions = []string{}
-	}
+	return posts, nil
+}
+
+func (d *AppDatabase) makeDBPostIntoFrontPost(ctx context.Context, post models.DBPost, askerID string) (models.FrontPost, error) {
+	author := defaultAuthorInfo(post.Author_ID)
+
+	post = normalizePostLists(post)
 
-	liked, err_2 := d.hasLiked(post.Original_Post_ID, askerID)
+	liked, err_2 := d.hasLiked(ctx, post.Original_Post_ID, askerID)
 	if err_2 != nil {
 		return models.FrontPost{}, err_2
 	}
 
-	retweeted, err_3 := d.hasRetweeted(post.Original_Post_ID, askerID)
+	retweeted, err_3 := d.hasRetweeted(ctx, post.Original_Post_ID, askerID)
 	if err_3 != nil {
 		return models.FrontPost{}, err_3
 	}
 
-	bookmarked, err_4 := d.hasBookmark(post.Post_ID, askerID)
+	bookmarked, err_4 := d.hasBookmark(ctx, post.Post_ID, askerID)
 	if err_4 != nil {
 		return models.FrontPost{}, err_4
 	}
-	return models.NewFrontPost(post, author, liked, retweeted, bookmarked), nil
+
+	quoted, err_5 := d.findQuotedPosts(ctx, []models.DBPost{post})
+	if err_5 != nil {
+		return models.FrontPost{}, err_5
+	}
+	return withQuotedPost(models.NewFrontPost(post, author, liked, retweeted, bookmarked), quoted), nil
+}
+
+func defaultAuthorInfo(authorID string) models.AuthorInfo {
+	return models.AuthorInfo{
+		Author_ID: authorID,
+		Username:  "username",
+		Alias:     "alias",
+		PthotoURL: "photourl",
+	}
 }
 
-func (d *AppDatabase) hasLiked(postID string, likerID string) (bool, error) {
+func normalizePostLists(post models.DBPost) models.DBPost {
+	if len(post.Tags) == 0 {
+		post.Tags = []string{}
+	}
+
+	if len(post.Mentions) == 0 {
+		post.Mentions = []string{}
+	}
+
+	return post
+}
+
+func (d *AppDatabase) hasLiked(ctx context.Context, postID string, likerID string) (bool, error) {
 	if likerID == ADMIN {
 		return false, nil
 	}
@@ -88,7 +113,7 @@ func (d *AppDatabase) hasLiked(postID string, likerID string) (bool, error) {
 
 	var res bson.M
 
-	err := likesCollection.FindOne(context.Background(), filter).Decode(&res)
+	err := likesCollection.FindOne(ctx, filter).Decode(&res)
 
 	if err != nil && err != mongo.ErrNoDocuments {
 		return false, err
@@ -97,7 +122,7 @@ func (d *AppDatabase) hasLiked(postID string, likerID string) (bool, error) {
 	return err != mongo.ErrNoDocuments, nil
 }
 
-func (d *AppDatabase) hasRetweeted(postID string, retweeterID string) (bool, error) {
+func (d *AppDatabase) hasRetweeted(ctx context.Context, postID string, retweeterID string) (bool, error) {
 	if retweeterID == ADMIN {
 		return false, nil
 	}
@@ -108,7 +133,7 @@ func (d *AppDatabase) hasRetweeted(postID string, retweeterID string) (bool, err
 
 	var res bson.M
 
-	err := retweetCollection.FindOne(context.Background(), filter).Decode(&res)
+	err := retweetCollection.FindOne(ctx, filter).Decode(&res)
 
 	if err != nil && err != mongo.ErrNoDocuments {
 		return false, err
@@ -117,14 +142,14 @@ func (d *AppDatabase) hasRetweeted(postID string, retweeterID string) (bool, err
 	return err != mongo.ErrNoDocuments, nil
 }
 
-func (d *AppDatabase) hasBookmark(postID string, userID string) (bool, error) {
+func (d *AppDatabase) hasBookmark(ctx context.Context, postID string, userID string) (bool, error) {
 	favoritesCollection := d.db.Collection(BOOKMARK_COLLECTION)
 
 	filter := bson.M{AUTHOR_ID_FIELD: userID, POST_ID_FIELD: postID}
 
 	var res bson.M
 
-	err := favoritesCollection.FindOne(context.Background(), filter).Decode(&res)
+	err := favoritesCollection.FindOne(ctx, filter).Decode(&res)
 
 	if err != nil && err != mongo.ErrNoDocuments {
 		return false, err
@@ -144,4 +169,42 @@ func convertToInt(value interface{}) int {
 	default:
 		return 0
 	}
-}
\ No newline at end of file
+}
+
+// pageFilter narrows filter to the requested page: the posts after the cursor,
+// or the ones older than FromTime when paging by offset.
+func pageFilter(filter bson.M, limitConfig models.LimitConfig) bson.M {
+	var page bson.M
+
+	if cursor := limitConfig.Cursor; cursor != nil {
+		page = bson.M{"$or": []bson.M{
+			{TIME_FIELD: bson.M{"$lt": cursor.Time}},
+			{TIME_FIELD: cursor.Time, POST_ID_FIELD: bson.M{"$lt": cursor.Post_ID}},
+		}}
+	} else {
+		parsedTime, err := time.Parse(time.RFC3339, limitConfig.FromTime)
+
+		if err != nil {
+			log.Println(err)
+		}
+
+		page = bson.M{TIME_FIELD: bson.M{"$lt": parsedTime.UTC()}}
+	}
+
+	return bson.M{"$and": []bson.M{filter, page}}
+}
+
+// pageOptions sorts the posts newest first, breaking ties on the post ID so
+// that the order is stable and matches the cursor, and asks for one more post
+// than the limit to know whether there is a next page.
+func pageOptions(limitConfig models.LimitConfig) *options.FindOptions {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: TIME_FIELD, Value: -1}, {Key: POST_ID_FIELD, Value: -1}}).
+		SetLimit(int64(limitConfig.Limit) + 1)
+
+	if limitConfig.Cursor == nil {
+		findOptions.SetSkip(int64(limitConfig.Skip))
+	}
+
+	return findOptions
+}
@@ -8,6 +8,10 @@ const (
 	BOOKMARK_COLLECTION = "bookmarks"
 	TWTMETRICS_COLLECTION = "twtmetrics"
 	TAGMETRICS_COLLECTION = "tagmetrics"
+	OUTBOX_COLLECTION     = "outbox"
+	TRENDING_COLLECTION   = "trending"
+	TOMBSTONE_COLLECTION  = "tombstones"
+	REVISION_COLLECTION   = "revisions"
 )
 
 const (
@@ -16,6 +20,7 @@ const (
 	AUTHOR_ID_FIELD        = "author_id"
 	TIME_FIELD             = "time"
 	TAGS_FIELD             = "tags"
+	TAG_KEYS_FIELD         = "tag_keys"
 	LIKES_FIELD            = "likes"
 	PUBLIC_FIELD           = "public"
 	LIKERS_FIELD           = "likers"
@@ -29,6 +34,22 @@ const (
 	BOOKMARK_FIELD         = "bookmark"
 	MENTIONS_FIELD         = "mentions"
 	BLOCKED_FIELD		  = "blocked"
+	IN_REPLY_TO_FIELD      = "in_reply_to_post_id"
+	CONVERSATION_ID_FIELD  = "conversation_id"
+	REPLIES_FIELD          = "replies"
+	QUOTED_POST_ID_FIELD   = "quoted_post_id"
+	QUOTES_FIELD           = "quotes"
+	EDITS_FIELD            = "edits"
+	THREAD_PATH_FIELD      = "thread_path"
+	NUMBER_FIELD           = "number"
+)
+
+const (
+	MESSAGE_ID_FIELD   = "message_id"
+	STATUS_FIELD       = "status"
+	NEXT_ATTEMPT_FIELD = "next_attempt"
+	CREATED_AT_FIELD   = "created_at"
+	CLAIMED_BY_FIELD   = "claimed_by"
 )
 
 const (
@@ -37,8 +58,18 @@ const (
 	TREND = "trend"
 	LAST_UPDATED = "last_updated"
 	DAY = "day"
+	TAG_FIELD = "tag"
+	WINDOW_FIELD = "window"
 )
 
 const (
 	ADMIN = "admin"
+)
+
+const (
+	TRENDING_DECAY = 0.1
+	// Trending posts score their likes plus the retweets weighted by
+	// TRENDING_RETWEET_WEIGHT, over (age in hours + 2) ^ TRENDING_GRAVITY.
+	TRENDING_RETWEET_WEIGHT = 2
+	TRENDING_GRAVITY        = 1.5
 )
\ No newline at end of file
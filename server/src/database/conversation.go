@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"server/src/models"
+	"slices"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (d *AppDatabase) GetConversation(ctx context.Context, conversationID string, following []string, askerID string) ([]models.FrontPost, error) {
+	filter := bson.M{CONVERSATION_ID_FIELD: conversationID, IS_RETWEET_FIELD: false}
+
+	cursor, err := d.db.Collection(FEED_COLLECTION).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []models.DBPost
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+
+	cursor, err = d.db.Collection(TOMBSTONE_COLLECTION).Find(ctx, bson.M{CONVERSATION_ID_FIELD: conversationID})
+	if err != nil {
+		return nil, err
+	}
+
+	var tombstones []models.Tombstone
+	if err := cursor.All(ctx, &tombstones); err != nil {
+		return nil, err
+	}
+
+	entries := conversationEntries(posts, tombstones, following)
+
+	shown := []models.DBPost{}
+	for _, entry := range entries {
+		if entry.shown {
+			shown = append(shown, entry.post)
+		}
+	}
+
+	state, err := d.loadViewerState(ctx, shown, askerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return conversationPosts(entries, state.frontPost), nil
+}
+
+func (d *AppDatabase) GetConversationThread(ctx context.Context, conversationID string, following []string, askerID string, limitConfig models.LimitConfig, after *models.ThreadCursor) ([]models.FrontPost, *models.ThreadCursor, error) {
+	filter := bson.M{CONVERSATION_ID_FIELD: conversationID, IS_RETWEET_FIELD: false}
+	tombstoneFilter := bson.M{CONVERSATION_ID_FIELD: conversationID}
+
+	if after != nil {
+		filter[THREAD_PATH_FIELD] = bson.M{"$gt": after.Thread_Path}
+		tombstoneFilter[THREAD_PATH_FIELD] = bson.M{"$gt": after.Thread_Path}
+	}
+
+	cursor, err := d.db.Collection(FEED_COLLECTION).Find(ctx, filter, threadPageOptions(limitConfig, after))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var posts []models.DBPost
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, nil, err
+	}
+
+	cursor, err = d.db.Collection(TOMBSTONE_COLLECTION).Find(ctx, tombstoneFilter, threadPageOptions(limitConfig, after))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tombstones []models.Tombstone
+	if err := cursor.All(ctx, &tombstones); err != nil {
+		return nil, nil, err
+	}
+
+	entries, next := threadPage(threadEntries(posts, tombstones, following), limitConfig, after)
+
+	shown := []models.DBPost{}
+	for _, entry := range entries {
+		if entry.shown {
+			shown = append(shown, entry.post)
+		}
+	}
+
+	state, err := d.loadViewerState(ctx, shown, askerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conversationPosts(entries, state.frontPost), next, nil
+}
+
+// threadPageOptions reads a thread in path order. Posts and tombstones are
+// read apart, so the offset is only taken once both are merged and each
+// read asks for every entry up to the end of the page, plus one.
+func threadPageOptions(limitConfig models.LimitConfig, after *models.ThreadCursor) *options.FindOptions {
+	limit := limitConfig.Limit + 1
+
+	if after == nil {
+		limit += max(limitConfig.Skip, 0)
+	}
+
+	return options.Find().
+		SetSort(bson.D{{Key: THREAD_PATH_FIELD, Value: 1}}).
+		SetLimit(int64(limit))
+}
+
+// threadPath returns the thread path of newPost. A reply whose parent left no
+// trace starts a branch of its own, after the posts that came before it.
+func (d *AppDatabase) threadPath(ctx context.Context, newPost models.DBPost) (string, error) {
+	if newPost.In_Reply_To_Post_ID == "" {
+		return models.ThreadPath("", newPost), nil
+	}
+
+	var parent models.DBPost
+
+	findOptions := options.FindOne().SetProjection(bson.M{THREAD_PATH_FIELD: 1})
+	err := d.db.Collection(FEED_COLLECTION).FindOne(ctx, bson.M{POST_ID_FIELD: newPost.In_Reply_To_Post_ID}, findOptions).Decode(&parent)
+
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return "", err
+	}
+
+	return models.ThreadPath(parent.Thread_Path, newPost), nil
+}
+
+// conversationEntry is a post of a conversation and whether the asker may see
+// it. The ones that cannot be seen are shown as tombstones.
+type conversationEntry struct {
+	post  models.DBPost
+	shown bool
+}
+
+// conversationEntries merges the posts and tombstones of a conversation,
+// oldest first.
+func conversationEntries(posts []models.DBPost, tombstones []models.Tombstone, following []string) []conversationEntry {
+	entries := []conversationEntry{}
+
+	for _, post := range posts {
+		visible := post.Public || slices.Contains(following, post.Author_ID)
+		entries = append(entries, conversationEntry{post: post, shown: visible && !post.Blocked})
+	}
+
+	for _, tombstone := range tombstones {
+		entries = append(entries, conversationEntry{post: tombstone.Post()})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return sortsBefore(entries[j].post.Time, entries[j].post.Post_ID, entries[i].post.Time, entries[i].post.Post_ID)
+	})
+
+	return entries
+}
+
+// threadEntries merges the posts and tombstones of a conversation in reading
+// order.
+func threadEntries(posts []models.DBPost, tombstones []models.Tombstone, following []string) []conversationEntry {
+	entries := conversationEntries(posts, tombstones, following)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].post.Thread_Path < entries[j].post.Thread_Path
+	})
+
+	return entries
+}
+
+// threadPage keeps the page of entries that limitConfig asks for, skipping
+// the offset unless the page starts after a cursor, and returns the cursor
+// of the next page when there is one.
+func threadPage(entries []conversationEntry, limitConfig models.LimitConfig, after *models.ThreadCursor) ([]conversationEntry, *models.ThreadCursor) {
+	if after == nil {
+		entries = entries[min(max(limitConfig.Skip, 0), len(entries)):]
+	}
+
+	if len(entries) <= limitConfig.Limit {
+		return entries, nil
+	}
+
+	entries = entries[:limitConfig.Limit]
+
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	return entries, &models.ThreadCursor{Thread_Path: entries[len(entries)-1].post.Thread_Path}
+}
+
+func conversationPosts(entries []conversationEntry, frontPost func(post models.DBPost) models.FrontPost) []models.FrontPost {
+	posts := []models.FrontPost{}
+
+	for _, entry := range entries {
+		if entry.shown {
+			posts = append(posts, frontPost(entry.post))
+		} else {
+			posts = append(posts, models.NewTombstoneFrontPost(entry.post))
+		}
+	}
+
+	return posts
+}
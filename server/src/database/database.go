@@ -5,28 +5,64 @@ import (
 
 	postErrors "server/src/all_errors"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type AppDatabase struct {
-	db *mongo.Database
+	db           *mongo.Database
+	hooks        writeHooks
+	transactions transactionSupport
 }
 
 func NewAppDatabase(client *mongo.Client) Database {
 	return &AppDatabase{db: client.Database(DATABASE_NAME)}
 }
 
-func (d *AppDatabase) ClearDB() error {
-	err := d.db.Collection(FEED_COLLECTION).Drop(context.Background())
+func (d *AppDatabase) ClearDB(ctx context.Context) error {
+	// The posts keep their indexes, since word searches cannot run without
+	// the text index.
+	_, err := d.db.Collection(FEED_COLLECTION).DeleteMany(ctx, bson.M{})
 	if err != nil {
 		return postErrors.DatabaseError(err.Error())
 	}
-	err = d.db.Collection(LIKES_COLLECTION).Drop(context.Background())
+	err = d.db.Collection(LIKES_COLLECTION).Drop(ctx)
 	if err != nil {
 		return postErrors.DatabaseError(err.Error())
 	}
 
-	err = d.db.Collection(RETWEET_COLLECTION).Drop(context.Background())
+	err = d.db.Collection(RETWEET_COLLECTION).Drop(ctx)
+	if err != nil {
+		return postErrors.DatabaseError(err.Error())
+	}
+
+	err = d.db.Collection(BOOKMARK_COLLECTION).Drop(ctx)
+	if err != nil {
+		return postErrors.DatabaseError(err.Error())
+	}
+
+	err = d.db.Collection(TOMBSTONE_COLLECTION).Drop(ctx)
+	if err != nil {
+		return postErrors.DatabaseError(err.Error())
+	}
+
+	err = d.db.Collection(REVISION_COLLECTION).Drop(ctx)
+	if err != nil {
+		return postErrors.DatabaseError(err.Error())
+	}
+
+	err = d.db.Collection(OUTBOX_COLLECTION).Drop(ctx)
+	if err != nil {
+		return postErrors.DatabaseError(err.Error())
+	}
+
+	for _, collection := range []string{TWTMETRICS_COLLECTION, TAGMETRICS_COLLECTION, TRENDING_COLLECTION} {
+		if err := d.db.Collection(collection).Drop(ctx); err != nil {
+			return postErrors.DatabaseError(err.Error())
+		}
+	}
+
+	err = d.db.Collection(MIGRATIONS_COLLECTION).Drop(ctx)
 	if err != nil {
 		return postErrors.DatabaseError(err.Error())
 	}
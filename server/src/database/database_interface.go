@@ -1,53 +1,135 @@
 package database
 
 import (
+	"context"
 	"server/src/models"
+	"time"
 )
 
 type Database interface {
-	AddNewPost(newPost models.DBPost) (models.FrontPost, error)
+	AddNewPost(ctx context.Context, newPost models.DBPost) (models.FrontPost, error)
 
-	GetPost(postID string, askerID string) (models.FrontPost, error)
+	// AddNewPostWithOutbox adds the post and the outbox messages it caused in
+	// a single write: either both are stored or neither is.
+	AddNewPostWithOutbox(ctx context.Context, newPost models.DBPost, messages []models.OutboxMessage) (models.FrontPost, error)
 
-	DeletePost(postID string) error
+	GetPost(ctx context.Context, postID string, askerID string) (models.FrontPost, error)
 
-	AddNewRetweet(newRetweet models.DBPost) (models.FrontPost, error)
+	// DeletePost, the retweet, like, block and edit writes store the event
+	// of the change in the outbox in the same write, so an event is written
+	// if and only if its change is.
+	DeletePost(ctx context.Context, postID string) error
 
-	DeleteRetweet(postID string, userID string) error
+	AddNewRetweet(ctx context.Context, newRetweet models.DBPost) (models.FrontPost, error)
 
-	EditPost(postID string, editInfo models.EditPostExpectedFormat, askerID string) (models.FrontPost, error)
+	DeleteRetweet(ctx context.Context, postID string, userID string) error
 
-	GetAllPosts(limitConfig models.LimitConfig, askerID string) ([]models.FrontPost, bool, error)
+	// EditPost stores the changes it makes to the post as a new revision. An
+	// edit that changes nothing is not stored.
+	EditPost(ctx context.Context, postID string, editInfo models.EditPostExpectedFormat, askerID string) (models.FrontPost, error)
 
-	GetUserFeedFollowing(following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)
+	// GetRevisions returns the revisions of the post, the newest first.
+	GetRevisions(ctx context.Context, postID string, limitConfig models.LimitConfig) ([]models.Revision, bool, error)
 
-	GetUserFeedInterests(interests []string, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)
+	// RevertPost brings the post back to how it was after the given revision,
+	// 0 being the post as first written. The revert is a revision of its own.
+	RevertPost(ctx context.Context, postID string, revision int, askerID string) (models.FrontPost, error)
 
-	GetUserFeedSingle(userID string, limitConfig models.LimitConfig, askerID string, following []string) ([]models.FrontPost, bool, error)
+	GetAllPosts(ctx context.Context, limitConfig models.LimitConfig, askerID string) ([]models.FrontPost, bool, error)
 
-	GetUserFeedRetweet(userID string, limitConfig models.LimitConfig, askerID string, following []string) ([]models.FrontPost, bool, error)
+	GetUserFeedFollowing(ctx context.Context, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)
 
-	WordSearchPosts(words string, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)
+	GetUserFeedInterests(ctx context.Context, interests []string, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)
 
-	GetUserHashtags(hashtags []string, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)
+	GetUserFeedSingle(ctx context.Context, userID string, limitConfig models.LimitConfig, askerID string, following []string) ([]models.FrontPost, bool, error)
 
-	GetUserMetrics(userID string, limits models.MetricLimits) (models.UserMetrics, error)
+	GetUserFeedRetweet(ctx context.Context, userID string, limitConfig models.LimitConfig, askerID string, following []string) ([]models.FrontPost, bool, error)
 
-	LikeAPost(postID string, likerID string) error
+	GetUserFeedMentions(ctx context.Context, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)
 
-	UnLikeAPost(postID string, likerID string) error
+	// GetConversation returns the posts of the conversation oldest first,
+	// retweets left out. Deleted posts that had replies, blocked posts and
+	// those following does not let the asker see come back as tombstones.
+	GetConversation(ctx context.Context, conversationID string, following []string, askerID string) ([]models.FrontPost, error)
 
-	AddFavorite(postID string, userID string) error
+	// GetConversationThread returns one page of the conversation in reading
+	// order, every post followed by its replies, with the same tombstones as
+	// GetConversation. The page starts after the cursor when there is one,
+	// and the cursor of the next page is nil on the last one.
+	GetConversationThread(ctx context.Context, conversationID string, following []string, askerID string, limitConfig models.LimitConfig, after *models.ThreadCursor) ([]models.FrontPost, *models.ThreadCursor, error)
 
-	RemoveFavorite(postID string, userID string) error
+	// SearchPosts returns the posts visible through following that match
+	// query, by relevance when it is sorted so and has words to search.
+	SearchPosts(ctx context.Context, query models.SearchQuery, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)
 
-	GetUserFavorites(userID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)
+	// GetTagSuggestions returns up to limit tags of the visible posts whose
+	// TagKey starts with prefix, the most used lately first.
+	GetTagSuggestions(ctx context.Context, prefix string, following []string, now time.Time, limit int) ([]models.TagSuggestion, error)
 
-	BlockPost(postID string) error
+	GetUserMetrics(ctx context.Context, userID string, limits models.MetricLimits) (models.UserMetrics, error)
 
-	UnBlockPost(postID string) error
+	// GetUserMetricsSeries returns the metrics of the posts of the user by
+	// the interval and timezone of limits. Buckets without posts are left out.
+	GetUserMetricsSeries(ctx context.Context, userID string, limits models.MetricLimits) ([]models.UserMetricsBucket, error)
 
-	GetTrendingTopics() ([]string, error)
+	// GetPlatformMetricsSeries returns the activity of every user by the
+	// interval, timezone and visibility of limits. Buckets without posts are
+	// left out and shares are not computed.
+	GetPlatformMetricsSeries(ctx context.Context, limits models.MetricLimits) ([]models.PlatformMetricsBucket, error)
 
-	ClearDB() error
+	LikeAPost(ctx context.Context, postID string, likerID string) error
+
+	UnLikeAPost(ctx context.Context, postID string, likerID string) error
+
+	AddFavorite(ctx context.Context, postID string, userID string) error
+
+	RemoveFavorite(ctx context.Context, postID string, userID string) error
+
+	GetUserFavorites(ctx context.Context, userID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)
+
+	BlockPost(ctx context.Context, postID string) error
+
+	UnBlockPost(ctx context.Context, postID string) error
+
+	// ComputeTrendingTopics ranks the tags of the public, unblocked original
+	// posts made from since to now, best first, keeping the first limit.
+	ComputeTrendingTopics(ctx context.Context, since time.Time, now time.Time, decay float64, limit int) ([]models.TrendingTopic, error)
+
+	// GetTrendingPosts ranks the unblocked original posts made from since to
+	// now that the asker can see by how fast they got likes and retweets.
+	// Retweets are counted in their original and never listed on their own.
+	GetTrendingPosts(ctx context.Context, following []string, askerID string, since time.Time, now time.Time, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)
+
+	// SaveTrendingTopics replaces the snapshot of its window.
+	SaveTrendingTopics(ctx context.Context, snapshot models.TrendingSnapshot) error
+
+	// GetTrendingTopics returns the last snapshot saved for window, or one
+	// with a zero Computed_At if there is none.
+	GetTrendingTopics(ctx context.Context, window string) (models.TrendingSnapshot, error)
+
+	// GetTagSummary returns the totals of tag, with its trend as of now.
+	GetTagSummary(ctx context.Context, tag string) (models.TagSummary, error)
+
+	// GetTagUsage returns the daily usage of tag for the UTC days from the day
+	// of from to the day of to, oldest first. Days without posts are missing.
+	GetTagUsage(ctx context.Context, tag string, from time.Time, to time.Time) ([]models.TagUsage, error)
+
+	AddOutboxMessages(ctx context.Context, messages []models.OutboxMessage) error
+
+	// ClaimOutboxMessages returns up to limit pending messages that are due by
+	// dueBy and leases them to owner by pushing their next attempt into the
+	// future, so no other dispatcher claims them meanwhile.
+	ClaimOutboxMessages(ctx context.Context, owner string, dueBy time.Time, limit int, lease time.Duration) ([]models.OutboxMessage, error)
+
+	// UpdateOutboxMessage stores message and releases its lease. It fails with
+	// ErrOutboxLeaseLost unless the lease of message.Claimed_By still holds.
+	UpdateOutboxMessage(ctx context.Context, message models.OutboxMessage) error
+
+	DeleteOutboxMessage(ctx context.Context, messageID string) error
+
+	GetOutboxMessages(ctx context.Context, status string, limit int) ([]models.OutboxMessage, error)
+
+	CountOutboxMessages(ctx context.Context, status string) (int, error)
+
+	ClearDB(ctx context.Context) error
 }
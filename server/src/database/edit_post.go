@@ -2,163 +2,98 @@ package database
 
 import (
 	"context"
-	"log"
+	postErrors "server/src/all_errors"
 	"server/src/models"
-	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+func (d *AppDatabase) EditPost(ctx context.Context, postID string, editInfo models.EditPostExpectedFormat, askerID string) (models.FrontPost, error) {
+	oldPost, err := d.findPost(ctx, postID, d.db.Collection(FEED_COLLECTION))
 
-func (d *AppDatabase) EditPost(postID string, editInfo models.EditPostExpectedFormat, askerID string) (models.FrontPost, error) {
-	postCollection := d.db.Collection(FEED_COLLECTION)
-	var post models.FrontPost
-	var dbPost models.DBPost
-
-	err := d.updatePostContent(postID, editInfo.Content)
-
-	if err != nil {
-		return post, err
-	}
-
-	err_3 := d.updatePostPublic(postID, editInfo.Public)
-
-	if err_3 != nil {
-		return post, err_3
-	}
-
-	err_4 := d.updatePostMediaURL(postID, editInfo.MediaInfo)
-
-	if err_4 != nil {
-		return post, err_4
-	}
-
-	err_5 := d.updatePostMentions(postID, &editInfo.Mentions)
-
-	if err_5 != nil {
-		return post, err_5
-	}
-
-	dbPost, err_6 := d.findPost(postID, postCollection)
-
-	if err_6 != nil {
-		return post, err_6
-	}
-
-	frontPost, err_7 := d.makeDBPostIntoFrontPost(dbPost, askerID)
-
-	return frontPost, err_7
-}
-
-func (d *AppDatabase) updatePostContent(postID string, newContent *string) error {
-
-	if newContent == nil {
-		return nil
-	}
-
-	postCollection := d.db.Collection(FEED_COLLECTION)
-
-	filter := bson.M{POST_ID_FIELD: postID}
-	update := bson.M{"$set": bson.M{CONTENT_FIELD: newContent}}
-
-	_, err := postCollection.UpdateOne(context.Background(), filter, update)
 	if err != nil {
-		log.Println(err)
-	}
-
-	var tags []string
-
-	content := strings.Split(*newContent, " ")
-
-	for _, word := range content {
-		if strings.HasPrefix(word, "#") {
-			tags = append(tags, word)
-		}
+		return models.FrontPost{}, err
 	}
 
-	err = d.updatePostTags(postID, &tags)
-
-	return err
+	return d.saveRevision(ctx, oldPost, editedPost(oldPost, editInfo), askerID, nil)
 }
 
-func (d *AppDatabase) updatePostTags(postID string, newTags *[]string) error {
-
-	if newTags == nil {
-		return nil
-	}
-
-	fixedTags := []string{}
+// saveRevision stores edited in place of post along with the revision that
+// tells them apart. Nothing is written when they do not differ.
+func (d *AppDatabase) saveRevision(ctx context.Context, post models.DBPost, edited models.DBPost, editorID string, revertedTo *int) (models.FrontPost, error) {
+	revision, changed := models.NewRevision(post, edited, editorID)
 
-	for _, word := range *newTags {
-		fixedTags = append(fixedTags, word[1:])
+	if !changed {
+		return d.makeDBPostIntoFrontPost(ctx, post, editorID)
 	}
 
-	postCollection := d.db.Collection(FEED_COLLECTION)
-
-	filter := bson.M{POST_ID_FIELD: postID}
-	update := bson.M{"$set": bson.M{TAGS_FIELD: fixedTags}}
-
-	_, err := postCollection.UpdateOne(context.Background(), filter, update)
-	if err != nil {
-		log.Println(err)
-	}
-
-	return err
-}
-
-func (d *AppDatabase) updatePostMentions(postID string, newMentions *[]string) error {
-
-	if newMentions == nil {
-		return nil
-	}
+	revision.Reverted_To = revertedTo
+	edited.Edits = revision.Number
 
 	postCollection := d.db.Collection(FEED_COLLECTION)
-
-	filter := bson.M{POST_ID_FIELD: postID}
-	update := bson.M{"$set": bson.M{MENTIONS_FIELD: newMentions}}
-
-	_, err := postCollection.UpdateOne(context.Background(), filter, update)
-	if err != nil {
-		log.Println(err)
-	}
-
-	return err
+	revisionCollection := d.db.Collection(REVISION_COLLECTION)
+
+	steps := []writeStep{
+		{
+			name: STEP_POST,
+			apply: func(ctx context.Context) error {
+				// Matching on the edit count keeps two edits made at once from
+				// both taking the same revision number.
+				filter := bson.M{POST_ID_FIELD: post.Post_ID, EDITS_FIELD: post.Edits}
+				result, err := postCollection.UpdateOne(ctx, filter, bson.M{"$set": editableFields(edited)})
+				if err == nil && result.MatchedCount == 0 {
+					err = postErrors.ErrEditConflict
+				}
+				return err
+			},
+			undo: func(ctx context.Context) error {
+				filter := bson.M{POST_ID_FIELD: post.Post_ID, EDITS_FIELD: edited.Edits}
+				_, err := postCollection.UpdateOne(ctx, filter, bson.M{"$set": editableFields(post)})
+				return err
+			},
+		},
+	}
+
+	if changes := editTagChanges(post.Tags, edited.Tags); !post.Is_Retweet && len(changes) > 0 {
+		steps = append(steps, writeStep{
+			name: STEP_TAG_METRICS,
+			apply: func(ctx context.Context) error {
+				return d.updateTagMetrics(ctx, post.Time, changes)
+			},
+			undo: func(ctx context.Context) error {
+				return d.updateTagMetrics(ctx, post.Time, changes.inverse())
+			},
+		})
+	}
+
+	steps = append(steps, writeStep{
+		name: STEP_REVISION,
+		apply: func(ctx context.Context) error {
+			_, err := revisionCollection.InsertOne(ctx, revision)
+			return err
+		},
+		undo: func(ctx context.Context) error {
+			_, err := revisionCollection.DeleteOne(ctx, bson.M{POST_ID_FIELD: revision.Post_ID, NUMBER_FIELD: revision.Number})
+			return err
+		},
+	}, d.eventStep(models.NewContentEvent(models.EDITED_CONTENT, edited.Post_ID, edited.Author_ID, edited.Tags)))
+
+	if err := d.runWrite(ctx, "EditPost", steps); err != nil {
+		return models.FrontPost{}, err
+	}
+
+	return d.makeDBPostIntoFrontPost(ctx, edited, editorID)
 }
 
-func (d *AppDatabase) updatePostPublic(postID string, newPublic *bool) error {
-
-	postCollection := d.db.Collection(FEED_COLLECTION)
-
-	if newPublic == nil {
-		return nil
+// editableFields are the fields of post an edit or a revert sets.
+func editableFields(post models.DBPost) bson.M {
+	return bson.M{
+		CONTENT_FIELD:    post.Content,
+		TAGS_FIELD:       post.Tags,
+		TAG_KEYS_FIELD:   post.Tag_Keys,
+		PUBLIC_FIELD:     post.Public,
+		MEDIA_INFO_FIELD: post.Media_Info,
+		MENTIONS_FIELD:   post.Mentions,
+		EDITS_FIELD:      post.Edits,
 	}
-
-	filter := bson.M{POST_ID_FIELD: postID}
-	update := bson.M{"$set": bson.M{PUBLIC_FIELD: newPublic}}
-
-	_, err := postCollection.UpdateOne(context.Background(), filter, update)
-	if err != nil {
-		log.Println(err)
-	}
-
-	return err
 }
-
-func (d *AppDatabase) updatePostMediaURL(postID string, newMediaInfo *models.MediaInfo) error {
-
-	postCollection := d.db.Collection(FEED_COLLECTION)
-
-	if newMediaInfo == nil {
-		return nil
-	}
-
-	filter := bson.M{POST_ID_FIELD: postID}
-	update := bson.M{"$set": bson.M{MEDIA_INFO_FIELD: newMediaInfo}}
-
-	_, err := postCollection.UpdateOne(context.Background(), filter, update)
-	if err != nil {
-		log.Println(err)
-	}
-
-	return err
-}
\ No newline at end of file
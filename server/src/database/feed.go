@@ -5,35 +5,27 @@ import (
 	"log"
 	postErrors "server/src/all_errors"
 	"server/src/models"
-	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 
-func (d *AppDatabase) GetUserFeedFollowing(following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+func (d *AppDatabase) GetUserFeedFollowing(ctx context.Context, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
 	postCollection := d.db.Collection(FEED_COLLECTION)
 
-	parsedTime, err := time.Parse(time.RFC3339, limitConfig.FromTime)
-
-	if err != nil {
-		log.Println(err)
-	}
-
-	filter := bson.M{TIME_FIELD: bson.M{"$lt": parsedTime.UTC()}, BLOCKED_FIELD: false, "$or": []bson.M{
+	filter := bson.M{BLOCKED_FIELD: false, "$or": []bson.M{
 		{AUTHOR_ID_FIELD: bson.M{"$in": following}},
 		{RETWEET_AUTHOR_FIELD: bson.M{"$in": following}},
 	}}
 
-	cursor, err := postCollection.Find(context.Background(), filter, options.Find().
-		SetSort(bson.M{TIME_FIELD: -1}).SetSkip(int64(limitConfig.Skip)).SetLimit(int64(limitConfig.Limit)+1))
+	cursor, err := postCollection.Find(ctx, pageFilter(filter, limitConfig), pageOptions(limitConfig))
 	if err != nil {
 		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
-	posts, err := d.createPostList(cursor, askerID)
+	posts, err := d.createPostList(ctx, cursor, askerID)
 
 	if err != nil {
 		return nil, false, postErrors.DatabaseError(err.Error())
@@ -48,32 +40,26 @@ func (d *AppDatabase) GetUserFeedFollowing(following []string, askerID string, l
 	return posts, hasMore, err
 }
 
-func (d *AppDatabase) GetUserFeedInterests(interests []string, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+func (d *AppDatabase) GetUserFeedInterests(ctx context.Context, interests []string, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
 	if len(interests) == 0 {
 		return []models.FrontPost{}, false, postErrors.NoTagsFound()
 	}
 
 	postCollection := d.db.Collection(FEED_COLLECTION)
-	parsedTime, err := time.Parse(time.RFC3339, limitConfig.FromTime)
-
-	if err != nil {
-		log.Println(err)
-	}
-
-	filter := bson.M{TAGS_FIELD: bson.M{"$in": interests}, TIME_FIELD: bson.M{"$lt": parsedTime.UTC()}, BLOCKED_FIELD: false, "$or": []bson.M{
+	filter := bson.M{TAGS_FIELD: bson.M{"$in": interests}, BLOCKED_FIELD: false, "$or": []bson.M{
 		{PUBLIC_FIELD: true},
 		{PUBLIC_FIELD: false, AUTHOR_ID_FIELD: bson.M{"$in": following}},
 		{PUBLIC_FIELD: false, RETWEET_AUTHOR_FIELD: bson.M{"$in": following}},
 	}}
 
-	cursor, err := postCollection.Find(context.Background(), filter, options.Find().
-		SetSort(bson.M{TIME_FIELD: -1}).SetSkip(int64(limitConfig.Skip)).SetLimit(int64(limitConfig.Limit)+1))
+	cursor, err := postCollection.Find(ctx, pageFilter(filter, limitConfig), pageOptions(limitConfig))
 	if err != nil {
 		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
-	posts, err := d.createPostList(cursor, askerID)
+	posts, err := d.createPostList(ctx, cursor, askerID)
 
 	if err != nil {
 		log.Println(err)
@@ -89,17 +75,9 @@ func (d *AppDatabase) GetUserFeedInterests(interests []string, following []strin
 	return posts, hasMore, err
 }
 
-func (d *AppDatabase) GetUserFeedSingle(userId string, limitConfig models.LimitConfig, askerID string, following []string) ([]models.FrontPost, bool, error) {
+func (d *AppDatabase) GetUserFeedSingle(ctx context.Context, userId string, limitConfig models.LimitConfig, askerID string, following []string) ([]models.FrontPost, bool, error) {
 	postCollection := d.db.Collection(FEED_COLLECTION)
-	parsedTime, err := time.Parse(time.RFC3339, limitConfig.FromTime)
-
-	if err != nil {
-		log.Println(err)
-		log.Println("User does not follow")
-	}
-
 	filter := bson.M{
-		TIME_FIELD:    bson.M{"$lt": parsedTime.UTC()},
 		BLOCKED_FIELD: false,
 		"$and": []bson.M{
 			{"$or": []bson.M{
@@ -114,14 +92,47 @@ func (d *AppDatabase) GetUserFeedSingle(userId string, limitConfig models.LimitC
 		},
 	}
 
-	cursor, err := postCollection.Find(context.Background(), filter, options.Find().
-		SetSort(bson.M{TIME_FIELD: -1}).SetSkip(int64(limitConfig.Skip)).SetLimit(int64(limitConfig.Limit)+1))
+	cursor, err := postCollection.Find(ctx, pageFilter(filter, limitConfig), pageOptions(limitConfig))
+	if err != nil {
+		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	posts, err := d.createPostList(ctx, cursor, askerID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
+	}
+
+	hasMore := len(posts) > limitConfig.Limit
+
+	if hasMore {
+		posts = posts[:len(posts)-1]
+	}
+
+	return posts, hasMore, err
+}
+
+// GetUserFeedMentions returns the original posts that mention askerID. Private
+// ones are only shown when the author is in following.
+func (d *AppDatabase) GetUserFeedMentions(ctx context.Context, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+	postCollection := d.db.Collection(FEED_COLLECTION)
+
+	filter := bson.M{MENTIONS_FIELD: askerID, IS_RETWEET_FIELD: false, BLOCKED_FIELD: false, "$or": []bson.M{
+		{PUBLIC_FIELD: true},
+		{PUBLIC_FIELD: false, AUTHOR_ID_FIELD: bson.M{"$in": following}},
+	}}
+
+	cursor, err := postCollection.Find(ctx, pageFilter(filter, limitConfig), pageOptions(limitConfig))
 	if err != nil {
 		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
-	posts, err := d.createPostList(cursor, askerID)
+	posts, err := d.createPostList(ctx, cursor, askerID)
 
 	if err != nil {
 		log.Println(err)
@@ -4,62 +4,114 @@ import (
 	"context"
 	"log"
 	postErrors "server/src/all_errors"
+	"server/src/models"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func (d *AppDatabase) LikeAPost(postID string, likerID string) error {
+func (d *AppDatabase) LikeAPost(ctx context.Context, postID string, likerID string) error {
 	postCollection := d.db.Collection(FEED_COLLECTION)
 	likesCollection := d.db.Collection(LIKES_COLLECTION)
 
-	liked, _ := d.hasLiked(postID, likerID)
-
-	if liked {
-		return postErrors.AlreadyLiked(postID)
-	}
-
 	filter := bson.M{ORIGINAL_POST_ID_FIELD: postID}
-	update := bson.M{"$inc": bson.M{LIKES_FIELD: 1}}
-
-	liker := bson.M{"$addToSet": bson.M{LIKERS_FIELD: likerID}}
-
-	_, err := postCollection.UpdateMany(context.Background(), filter, update)
-	
-	if err != nil {
-		log.Println(err)
-		return postErrors.TwitsnapNotFound(postID)
-	}
 
-	_, err = likesCollection.UpdateOne(context.Background(), filter, liker, options.Update().SetUpsert(true))
-	if err != nil {
-		log.Println(err)
-		return postErrors.TwitsnapNotFound(postID)
+	steps := []writeStep{
+		{
+			name: STEP_LIKERS,
+			apply: func(ctx context.Context) error {
+				liker := bson.M{"$addToSet": bson.M{LIKERS_FIELD: likerID}}
+
+				result, err := likesCollection.UpdateOne(ctx, filter, liker, options.Update().SetUpsert(true))
+				if err != nil {
+					log.Println(err)
+					return postErrors.TwitsnapNotFound(postID)
+				}
+
+				// The likers set decides whether the like counts, so two
+				// concurrent likes can not both increment the counter.
+				if result.ModifiedCount == 0 && result.UpsertedCount == 0 {
+					return postErrors.AlreadyLiked(postID)
+				}
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				_, err := likesCollection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{LIKERS_FIELD: likerID}})
+				return err
+			},
+		},
+		{
+			name: STEP_LIKES_COUNTER,
+			apply: func(ctx context.Context) error {
+				_, err := postCollection.UpdateMany(ctx, filter, bson.M{"$inc": bson.M{LIKES_FIELD: 1}})
+				if err != nil {
+					log.Println(err)
+					return postErrors.TwitsnapNotFound(postID)
+				}
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				_, err := postCollection.UpdateMany(ctx, filter, bson.M{"$inc": bson.M{LIKES_FIELD: -1}})
+				return err
+			},
+		},
+		d.eventStep(models.NewEngagementEvent(models.NEW_LIKE, postID, likerID)),
 	}
 
-	return nil
+	return d.runWrite(ctx, "LikeAPost", steps)
 }
 
-func (d *AppDatabase) UnLikeAPost(postID string, likerID string) error {
+func (d *AppDatabase) UnLikeAPost(ctx context.Context, postID string, likerID string) error {
 	postCollection := d.db.Collection(FEED_COLLECTION)
 	likesCollection := d.db.Collection(LIKES_COLLECTION)
 
 	filter := bson.M{ORIGINAL_POST_ID_FIELD: postID}
-	update := bson.M{"$inc": bson.M{LIKES_FIELD: -1}}
-
-	liker := bson.M{"$pull": bson.M{LIKERS_FIELD: likerID}}
-
-	_, err := postCollection.UpdateMany(context.Background(), filter, update)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-
-	_, err = likesCollection.UpdateOne(context.Background(), filter, liker)
-
-	if err != nil {
-		log.Println(err)
+	liked := true
+
+	steps := []writeStep{
+		{
+			name: STEP_LIKERS,
+			apply: func(ctx context.Context) error {
+				result, err := likesCollection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{LIKERS_FIELD: likerID}})
+				if err != nil {
+					log.Println(err)
+					return err
+				}
+
+				liked = result.ModifiedCount > 0
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				if !liked {
+					return nil
+				}
+				_, err := likesCollection.UpdateOne(ctx, filter, bson.M{"$addToSet": bson.M{LIKERS_FIELD: likerID}})
+				return err
+			},
+		},
+		{
+			name: STEP_LIKES_COUNTER,
+			apply: func(ctx context.Context) error {
+				// Only likes that were in the set are taken off the counter.
+				if !liked {
+					return nil
+				}
+				_, err := postCollection.UpdateMany(ctx, filter, bson.M{"$inc": bson.M{LIKES_FIELD: -1}})
+				if err != nil {
+					log.Println(err)
+				}
+				return err
+			},
+			undo: func(ctx context.Context) error {
+				if !liked {
+					return nil
+				}
+				_, err := postCollection.UpdateMany(ctx, filter, bson.M{"$inc": bson.M{LIKES_FIELD: 1}})
+				return err
+			},
+		},
+		when(&liked, d.eventStep(models.NewEngagementEvent(models.REMOVED_LIKE, postID, likerID))),
 	}
 
-	return err
+	return d.runWrite(ctx, "UnLikeAPost", steps)
 }
@@ -0,0 +1,239 @@
+package database
+
+import (
+	"context"
+	"log"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryDatabase is an in-memory Database that mirrors the filtering, privacy
+// and pagination rules of AppDatabase. It is meant for tests and local runs
+// where no MongoDB instance is available.
+type MemoryDatabase struct {
+	mu         sync.RWMutex
+	posts      []models.DBPost
+	likes      map[string][]string
+	retweets   map[string][]string
+	bookmarks  map[string][]string
+	outbox     []models.OutboxMessage
+	tombstones []models.Tombstone
+	revisions  []models.Revision
+	tagUsage   map[string]map[string]models.TagUsage
+	tags       map[string]models.TagSummary
+	trending   map[string]models.TrendingSnapshot
+	hooks      writeHooks
+}
+
+func NewMemoryDatabase() Database {
+	m := &MemoryDatabase{}
+	m.reset()
+	return m
+}
+
+func (m *MemoryDatabase) ClearDB(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reset()
+
+	return nil
+}
+
+func (m *MemoryDatabase) reset() {
+	m.posts = []models.DBPost{}
+	m.likes = map[string][]string{}
+	m.retweets = map[string][]string{}
+	m.bookmarks = map[string][]string{}
+	m.outbox = []models.OutboxMessage{}
+	m.tombstones = []models.Tombstone{}
+	m.revisions = []models.Revision{}
+	m.tagUsage = map[string]map[string]models.TagUsage{}
+	m.tags = map[string]models.TagSummary{}
+	m.trending = map[string]models.TrendingSnapshot{}
+}
+
+func (m *MemoryDatabase) findPost(postID string) (models.DBPost, error) {
+	for _, post := range m.posts {
+		if post.Post_ID == postID && !post.Blocked {
+			return post, nil
+		}
+	}
+	return models.DBPost{}, postErrors.ErrTwitsnapNotFound
+}
+
+func (m *MemoryDatabase) findPostIndex(postID string) int {
+	return slices.IndexFunc(m.posts, func(post models.DBPost) bool {
+		return post.Post_ID == postID
+	})
+}
+
+func (m *MemoryDatabase) updateByOriginal(originalPostID string, update func(post *models.DBPost)) {
+	for i := range m.posts {
+		if m.posts[i].Original_Post_ID == originalPostID {
+			update(&m.posts[i])
+		}
+	}
+}
+
+// findPosts returns one page of the posts matching filter, newest first,
+// following the same skip/limit+1 convention as the Mongo queries.
+func (m *MemoryDatabase) findPosts(filter func(post models.DBPost) bool, limitConfig models.LimitConfig, askerID string) ([]models.FrontPost, bool) {
+	matched := m.matchPosts(filter, limitConfig)
+
+	posts := m.createPostList(matched, askerID)
+
+	hasMore := len(posts) > limitConfig.Limit
+
+	if hasMore {
+		posts = posts[:len(posts)-1]
+	}
+
+	return posts, hasMore
+}
+
+// matchPosts returns the posts of the requested page that match filter plus
+// one more, sorted the same way as pageOptions sorts the Mongo queries.
+func (m *MemoryDatabase) matchPosts(filter func(post models.DBPost) bool, limitConfig models.LimitConfig) []models.DBPost {
+	matched := []models.DBPost{}
+	inPage := pageMatcher(limitConfig)
+
+	for _, post := range m.posts {
+		if filter(post) && inPage(post) {
+			matched = append(matched, post)
+		}
+	}
+
+	skip := limitConfig.Skip
+
+	if limitConfig.Cursor != nil {
+		skip = 0
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return sortsBefore(matched[i].Time, matched[i].Post_ID, matched[j].Time, matched[j].Post_ID)
+	})
+
+	if skip > len(matched) {
+		skip = len(matched)
+	}
+	matched = matched[skip:]
+
+	if limit := limitConfig.Limit + 1; limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+
+	return matched
+}
+
+// pageMatcher keeps the posts after the cursor, or the ones older than
+// FromTime when paging by offset.
+func pageMatcher(limitConfig models.LimitConfig) func(post models.DBPost) bool {
+	if cursor := limitConfig.Cursor; cursor != nil {
+		return func(post models.DBPost) bool {
+			return sortsBefore(cursor.Time, cursor.Post_ID, post.Time, post.Post_ID)
+		}
+	}
+
+	parsedTime := parseLimitTime(limitConfig.FromTime)
+
+	return func(post models.DBPost) bool {
+		return post.Time.Before(parsedTime)
+	}
+}
+
+// sortsBefore reports whether a post comes before another one in cursor
+// order: newest first and, for the same time, the higher post ID first.
+func sortsBefore(postTime time.Time, postID string, otherTime time.Time, otherID string) bool {
+	if !postTime.Equal(otherTime) {
+		return postTime.After(otherTime)
+	}
+	return postID > otherID
+}
+
+func (m *MemoryDatabase) createPostList(dbPosts []models.DBPost, askerID string) []models.FrontPost {
+	var posts []models.FrontPost
+
+	for _, dbPost := range dbPosts {
+		posts = append(posts, m.makeDBPostIntoFrontPost(dbPost, askerID))
+	}
+
+	return posts
+}
+
+func (m *MemoryDatabase) makeDBPostIntoFrontPost(post models.DBPost, askerID string) models.FrontPost {
+	author := defaultAuthorInfo(post.Author_ID)
+
+	post = normalizePostLists(post)
+
+	liked := m.hasLiked(post.Original_Post_ID, askerID)
+	retweeted := m.hasRetweeted(post.Original_Post_ID, askerID)
+	bookmarked := m.hasBookmark(post.Post_ID, askerID)
+	quoted := m.findQuotedPosts([]models.DBPost{post})
+
+	return withQuotedPost(models.NewFrontPost(post, author, liked, retweeted, bookmarked), quoted)
+}
+
+func (m *MemoryDatabase) hasLiked(postID string, likerID string) bool {
+	if likerID == ADMIN {
+		return false
+	}
+	return slices.Contains(m.likes[postID], likerID)
+}
+
+func (m *MemoryDatabase) hasRetweeted(postID string, retweeterID string) bool {
+	if retweeterID == ADMIN {
+		return false
+	}
+	return slices.Contains(m.retweets[postID], retweeterID)
+}
+
+func (m *MemoryDatabase) hasBookmark(postID string, userID string) bool {
+	return slices.Contains(m.bookmarks[userID], postID)
+}
+
+// storedPost copies the slices of a post and truncates its time to
+// milliseconds, which is the precision MongoDB keeps for dates.
+func storedPost(post models.DBPost) models.DBPost {
+	post.Time = post.Time.UTC().Truncate(time.Millisecond)
+	post.Tags = slices.Clone(post.Tags)
+	post.Mentions = slices.Clone(post.Mentions)
+	return post
+}
+
+func parseLimitTime(fromTime string) time.Time {
+	parsedTime, err := time.Parse(time.RFC3339, fromTime)
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	return parsedTime.UTC()
+}
+
+func isVisibleTo(post models.DBPost, following []string) bool {
+	return post.Public ||
+		slices.Contains(following, post.Author_ID) ||
+		slices.Contains(following, post.Retweet_Author_ID)
+}
+
+func addToSet(set []string, value string) []string {
+	if slices.Contains(set, value) {
+		return set
+	}
+	return append(set, value)
+}
+
+func pull(set []string, value string) []string {
+	return slices.DeleteFunc(set, func(v string) bool {
+		return v == value
+	})
+}
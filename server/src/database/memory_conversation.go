@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"server/src/models"
+	"slices"
+)
+
+func (m *MemoryDatabase) GetConversation(ctx context.Context, conversationID string, following []string, askerID string) ([]models.FrontPost, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	posts := []models.DBPost{}
+	for _, post := range m.posts {
+		if post.Conversation_ID == conversationID && !post.Is_Retweet {
+			posts = append(posts, post)
+		}
+	}
+
+	tombstones := slices.DeleteFunc(slices.Clone(m.tombstones), func(tombstone models.Tombstone) bool {
+		return tombstone.Conversation_ID != conversationID
+	})
+
+	entries := conversationEntries(posts, tombstones, following)
+
+	return conversationPosts(entries, func(post models.DBPost) models.FrontPost {
+		return m.makeDBPostIntoFrontPost(post, askerID)
+	}), nil
+}
+
+func (m *MemoryDatabase) GetConversationThread(ctx context.Context, conversationID string, following []string, askerID string, limitConfig models.LimitConfig, after *models.ThreadCursor) ([]models.FrontPost, *models.ThreadCursor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	inPage := func(path string) bool {
+		return after == nil || path > after.Thread_Path
+	}
+
+	posts := []models.DBPost{}
+	for _, post := range m.posts {
+		if post.Conversation_ID == conversationID && !post.Is_Retweet && inPage(post.Thread_Path) {
+			posts = append(posts, post)
+		}
+	}
+
+	tombstones := slices.DeleteFunc(slices.Clone(m.tombstones), func(tombstone models.Tombstone) bool {
+		return tombstone.Conversation_ID != conversationID || !inPage(tombstone.Thread_Path)
+	})
+
+	entries, next := threadPage(threadEntries(posts, tombstones, following), limitConfig, after)
+
+	return conversationPosts(entries, func(post models.DBPost) models.FrontPost {
+		return m.makeDBPostIntoFrontPost(post, askerID)
+	}), next, nil
+}
+
+// threadPath returns the thread path of newPost, as the Mongo database does.
+func (m *MemoryDatabase) threadPath(newPost models.DBPost) string {
+	parentPath := ""
+
+	if index := m.findPostIndex(newPost.In_Reply_To_Post_ID); newPost.In_Reply_To_Post_ID != "" && index != -1 {
+		parentPath = m.posts[index].Thread_Path
+	}
+
+	return models.ThreadPath(parentPath, newPost)
+}
+
+// incrementReplies counts delta more replies on the post and its retweets.
+func (m *MemoryDatabase) incrementReplies(originalPostID string, delta int) {
+	m.updateByOriginal(originalPostID, func(post *models.DBPost) {
+		post.Replies += delta
+	})
+}
@@ -0,0 +1,235 @@
+package database
+
+import (
+	"context"
+	"math"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"slices"
+	"sort"
+	"strings"
+)
+
+func (m *MemoryDatabase) GetUserFeedFollowing(ctx context.Context, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	posts, hasMore := m.findPosts(func(post models.DBPost) bool {
+		return !post.Blocked &&
+			(slices.Contains(following, post.Author_ID) || slices.Contains(following, post.Retweet_Author_ID))
+	}, limitConfig, askerID)
+
+	return posts, hasMore, nil
+}
+
+func (m *MemoryDatabase) GetUserFeedInterests(ctx context.Context, interests []string, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if len(interests) == 0 {
+		return []models.FrontPost{}, false, postErrors.NoTagsFound()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	posts, hasMore := m.findPosts(func(post models.DBPost) bool {
+		return hasAnyTag(post, interests) && !post.Blocked && isVisibleTo(post, following)
+	}, limitConfig, askerID)
+
+	return posts, hasMore, nil
+}
+
+func (m *MemoryDatabase) GetUserFeedSingle(ctx context.Context, userId string, limitConfig models.LimitConfig, askerID string, following []string) ([]models.FrontPost, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	posts, hasMore := m.findPosts(func(post models.DBPost) bool {
+		ownedByUser := (post.Author_ID == userId && !post.Is_Retweet) || post.Retweet_Author_ID == userId
+		return !post.Blocked && ownedByUser && isVisibleTo(post, following)
+	}, limitConfig, askerID)
+
+	return posts, hasMore, nil
+}
+
+func (m *MemoryDatabase) GetUserFeedRetweet(ctx context.Context, userId string, limitConfig models.LimitConfig, askerID string, following []string) ([]models.FrontPost, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	posts, hasMore := m.findPosts(func(post models.DBPost) bool {
+		ownedByUser := post.Author_ID == userId || post.Retweet_Author_ID == userId
+		return post.Is_Retweet && ownedByUser && isVisibleTo(post, following)
+	}, limitConfig, askerID)
+
+	return posts, hasMore, nil
+}
+
+func (m *MemoryDatabase) GetUserFeedMentions(ctx context.Context, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	posts, hasMore := m.findPosts(func(post models.DBPost) bool {
+		visible := post.Public || slices.Contains(following, post.Author_ID)
+		return !post.Is_Retweet && !post.Blocked && visible && slices.Contains(post.Mentions, askerID)
+	}, limitConfig, askerID)
+
+	return posts, hasMore, nil
+}
+
+func (m *MemoryDatabase) SearchPosts(ctx context.Context, query models.SearchQuery, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matches := func(post models.DBPost) bool {
+		return matchesSearch(post, query) && isVisibleTo(post, following)
+	}
+
+	if query.Sort != models.RELEVANCE_SORT || !query.HasText() {
+		posts, hasMore := m.findPosts(matches, limitConfig, askerID)
+		return posts, hasMore, nil
+	}
+
+	terms := query.ScoredTerms()
+	all := limitConfig
+	all.Skip, all.Limit = 0, len(m.posts)
+	matched := m.matchPosts(matches, all)
+	scores := map[string]float64{}
+
+	for _, post := range matched {
+		scores[post.Post_ID] = textScore(post.Content, terms)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return scores[matched[i].Post_ID] > scores[matched[j].Post_ID]
+	})
+
+	skip := min(limitConfig.Skip, len(matched))
+	matched = matched[skip:]
+	matched = matched[:min(limitConfig.Limit+1, len(matched))]
+
+	posts := m.createPostList(matched, askerID)
+	hasMore := len(posts) > limitConfig.Limit
+
+	if hasMore {
+		posts = posts[:len(posts)-1]
+	}
+
+	return posts, hasMore, nil
+}
+
+// matchesSearch tells whether post matches query the way searchFilter and
+// the text index do.
+func matchesSearch(post models.DBPost, query models.SearchQuery) bool {
+	tokens := models.SearchTokens(post.Content)
+
+	if query.HasText() && !matchesText(post.Content, tokens, query) {
+		return false
+	}
+
+	if slices.ContainsFunc(query.Excluded, func(word string) bool { return slices.Contains(tokens, word) }) {
+		return false
+	}
+
+	if len(query.Authors) > 0 && !slices.Contains(query.Authors, post.Retweet_Author_ID) {
+		return false
+	}
+
+	if !hasAllTags(post, query.Tags) {
+		return false
+	}
+
+	if slices.ContainsFunc(query.Mentions, func(mention string) bool { return !slices.Contains(post.Mentions, mention) }) {
+		return false
+	}
+
+	if query.Since != nil && post.Time.Before(*query.Since) {
+		return false
+	}
+
+	if query.Until != nil && !post.Time.Before(*query.Until) {
+		return false
+	}
+
+	if query.HasMedia && post.Media_Info.Media_URL == "" {
+		return false
+	}
+
+	return !query.IsRetweet || post.Is_Retweet
+}
+
+// matchesText tells whether content has every phrase of query or, without
+// phrases, any of its terms.
+func matchesText(content string, tokens []string, query models.SearchQuery) bool {
+	if len(query.Phrases) > 0 {
+		lower := strings.ToLower(content)
+		return !slices.ContainsFunc(query.Phrases, func(phrase string) bool {
+			return !strings.Contains(lower, strings.ToLower(phrase))
+		})
+	}
+
+	return slices.ContainsFunc(query.Terms, func(term string) bool {
+		return slices.Contains(tokens, term)
+	})
+}
+
+// textScore is the relevance the text index gives content for terms. Every
+// repetition of a term weighs half the one before it and shorter contents
+// score higher.
+func textScore(content string, terms []string) float64 {
+	tokens := models.SearchTokens(content)
+	score := 0.0
+
+	for _, term := range terms {
+		count := 0
+		freq := 0.0
+
+		for _, token := range tokens {
+			if token == term {
+				freq += 1 / math.Pow(2, float64(count))
+				count++
+			}
+		}
+
+		if count > 0 {
+			score += freq * (0.5*float64(count)/float64(len(tokens)) + 0.5)
+		}
+	}
+
+	return score
+}
+
+func hasAnyTag(post models.DBPost, tags []string) bool {
+	return slices.ContainsFunc(post.Tags, func(tag string) bool {
+		return slices.Contains(tags, tag)
+	})
+}
+
+func hasAllTags(post models.DBPost, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(post.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,267 @@
+package database
+
+import (
+	"context"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"slices"
+)
+
+func (m *MemoryDatabase) LikeAPost(ctx context.Context, postID string, likerID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	steps := []writeStep{
+		{
+			name: STEP_LIKERS,
+			apply: func(ctx context.Context) error {
+				if slices.Contains(m.likes[postID], likerID) {
+					return postErrors.AlreadyLiked(postID)
+				}
+				m.likes[postID] = append(m.likes[postID], likerID)
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.likes[postID] = pull(m.likes[postID], likerID)
+				return nil
+			},
+		},
+		{
+			name: STEP_LIKES_COUNTER,
+			apply: func(ctx context.Context) error {
+				m.updateByOriginal(postID, func(post *models.DBPost) { post.Likes++ })
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.updateByOriginal(postID, func(post *models.DBPost) { post.Likes-- })
+				return nil
+			},
+		},
+		m.eventStep(models.NewEngagementEvent(models.NEW_LIKE, postID, likerID)),
+	}
+
+	return m.hooks.runCompensated(ctx, "LikeAPost", steps)
+}
+
+func (m *MemoryDatabase) UnLikeAPost(ctx context.Context, postID string, likerID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	liked := slices.Contains(m.likes[postID], likerID)
+
+	steps := []writeStep{
+		{
+			name: STEP_LIKERS,
+			apply: func(ctx context.Context) error {
+				if likers, ok := m.likes[postID]; ok {
+					m.likes[postID] = pull(likers, likerID)
+				}
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				if liked {
+					m.likes[postID] = addToSet(m.likes[postID], likerID)
+				}
+				return nil
+			},
+		},
+		{
+			name: STEP_LIKES_COUNTER,
+			apply: func(ctx context.Context) error {
+				if liked {
+					m.updateByOriginal(postID, func(post *models.DBPost) { post.Likes-- })
+				}
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				if liked {
+					m.updateByOriginal(postID, func(post *models.DBPost) { post.Likes++ })
+				}
+				return nil
+			},
+		},
+		when(&liked, m.eventStep(models.NewEngagementEvent(models.REMOVED_LIKE, postID, likerID))),
+	}
+
+	return m.hooks.runCompensated(ctx, "UnLikeAPost", steps)
+}
+
+func (m *MemoryDatabase) AddNewRetweet(ctx context.Context, newRetweet models.DBPost) (models.FrontPost, error) {
+	if err := ctx.Err(); err != nil {
+		return models.FrontPost{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	originalPostID := newRetweet.Original_Post_ID
+
+	steps := []writeStep{
+		{
+			name: STEP_RETWEETERS,
+			apply: func(ctx context.Context) error {
+				if slices.Contains(m.retweets[originalPostID], newRetweet.Retweet_Author_ID) {
+					return postErrors.ErrAlreadyRetweeted
+				}
+				m.retweets[originalPostID] = append(m.retweets[originalPostID], newRetweet.Retweet_Author_ID)
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.retweets[originalPostID] = pull(m.retweets[originalPostID], newRetweet.Retweet_Author_ID)
+				return nil
+			},
+		},
+		{
+			name: STEP_POST,
+			apply: func(ctx context.Context) error {
+				m.posts = append(m.posts, storedPost(newRetweet))
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.posts = slices.DeleteFunc(m.posts, func(post models.DBPost) bool {
+					return post.Post_ID == newRetweet.Post_ID
+				})
+				return nil
+			},
+		},
+		{
+			name: STEP_RETWEETS_COUNTER,
+			apply: func(ctx context.Context) error {
+				m.updateByOriginal(originalPostID, func(post *models.DBPost) { post.Retweets++ })
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.updateByOriginal(originalPostID, func(post *models.DBPost) { post.Retweets-- })
+				return nil
+			},
+		},
+		m.eventStep(models.NewEngagementEvent(models.NEW_RETWEET, originalPostID, newRetweet.Retweet_Author_ID)),
+	}
+
+	if err := m.hooks.runCompensated(ctx, "AddNewRetweet", steps); err != nil {
+		return models.FrontPost{}, err
+	}
+
+	newPostRetweet, err := m.findPost(newRetweet.Post_ID)
+
+	if err != nil {
+		return models.FrontPost{}, err
+	}
+
+	return m.makeDBPostIntoFrontPost(newPostRetweet, newPostRetweet.Retweet_Author_ID), nil
+}
+
+func (m *MemoryDatabase) DeleteRetweet(ctx context.Context, postID string, userID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	retweeted := slices.Contains(m.retweets[postID], userID)
+	posts := m.posts
+
+	steps := []writeStep{
+		{
+			name: STEP_RETWEETERS,
+			apply: func(ctx context.Context) error {
+				if retweeters, ok := m.retweets[postID]; ok {
+					m.retweets[postID] = pull(retweeters, userID)
+				}
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				if retweeted {
+					m.retweets[postID] = addToSet(m.retweets[postID], userID)
+				}
+				return nil
+			},
+		},
+		{
+			name: STEP_RETWEETS_COUNTER,
+			apply: func(ctx context.Context) error {
+				if retweeted {
+					m.updateByOriginal(postID, func(post *models.DBPost) { post.Retweets-- })
+				}
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				if retweeted {
+					m.updateByOriginal(postID, func(post *models.DBPost) { post.Retweets++ })
+				}
+				return nil
+			},
+		},
+		{
+			// The retweet itself leaves the feeds along with the retweeter.
+			name: STEP_RETWEET_POSTS,
+			apply: func(ctx context.Context) error {
+				m.posts = slices.DeleteFunc(slices.Clone(m.posts), func(post models.DBPost) bool {
+					return post.Is_Retweet && post.Original_Post_ID == postID && post.Retweet_Author_ID == userID
+				})
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.posts = posts
+				return nil
+			},
+		},
+		when(&retweeted, m.eventStep(models.NewEngagementEvent(models.REMOVED_RETWEET, postID, userID))),
+	}
+
+	return m.hooks.runCompensated(ctx, "DeleteRetweet", steps)
+}
+
+func (m *MemoryDatabase) AddFavorite(ctx context.Context, postID string, userID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bookmarks[userID] = addToSet(m.bookmarks[userID], postID)
+
+	return nil
+}
+
+func (m *MemoryDatabase) RemoveFavorite(ctx context.Context, postID string, userID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if bookmarks, ok := m.bookmarks[userID]; ok {
+		m.bookmarks[userID] = pull(bookmarks, postID)
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) GetUserFavorites(ctx context.Context, userID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	postIDs := m.bookmarks[userID]
+
+	posts, hasMore := m.findPosts(func(post models.DBPost) bool {
+		return slices.Contains(postIDs, post.Post_ID)
+	}, limitConfig, userID)
+
+	return posts, hasMore, nil
+}
@@ -0,0 +1,248 @@
+package database
+
+import (
+	"context"
+	"log"
+	"maps"
+	"math"
+	"server/src/models"
+	"sort"
+	"time"
+)
+
+func (m *MemoryDatabase) GetUserMetrics(ctx context.Context, userID string, limits models.MetricLimits) (models.UserMetrics, error) {
+	if err := ctx.Err(); err != nil {
+		return models.UserMetrics{}, err
+	}
+
+	parsedFromTime, err := time.Parse(time.RFC3339, limits.FromTime)
+	if err != nil {
+		log.Println(err)
+		return models.UserMetrics{}, err
+	}
+
+	parsedToTime, err := time.Parse(time.RFC3339, limits.ToTime)
+	if err != nil {
+		log.Println(err)
+		return models.UserMetrics{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	metrics := models.UserMetrics{Likes: 0, Retweets: 0, Posts: 0}
+
+	for _, post := range m.posts {
+		if post.Author_ID != userID || post.Is_Retweet {
+			continue
+		}
+		if post.Time.Before(parsedFromTime) || !post.Time.Before(parsedToTime) {
+			continue
+		}
+		metrics.Likes += post.Likes
+		metrics.Retweets += post.Retweets
+		metrics.Posts++
+	}
+
+	return metrics, nil
+}
+
+func (m *MemoryDatabase) updateTagMetrics(postTime time.Time, changes tagChanges) {
+	now := time.Now().UTC()
+	day := tagDay(postTime)
+	hour := tagHour(postTime)
+
+	for tag, delta := range changes {
+		if delta == 0 {
+			continue
+		}
+
+		if m.tagUsage[tag] == nil {
+			m.tagUsage[tag] = map[string]models.TagUsage{}
+		}
+
+		usage, ok := m.tagUsage[tag][day]
+		if !ok {
+			usage = models.TagUsage{Tag: tag, Day: day, Hourly_Frecuency: map[string]int{}}
+		}
+		usage.Hourly_Frecuency = maps.Clone(usage.Hourly_Frecuency)
+		usage.Hourly_Frecuency[hour] += delta
+		usage.Total_Tweets += delta
+		m.tagUsage[tag][day] = usage
+
+		summary, ok := m.tags[tag]
+		if !ok {
+			summary = models.TagSummary{Tag: tag, Last_Updated: now}
+		}
+		summary.Total_Tweets += delta
+		summary.Trend = math.Max(0, decayTrend(summary.Trend, summary.Last_Updated, now)+float64(delta)*trendWeight(postTime, now))
+		summary.Last_Updated = now
+		m.tags[tag] = summary
+	}
+}
+
+func (m *MemoryDatabase) GetTagSummary(ctx context.Context, tag string) (models.TagSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return models.TagSummary{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	summary, ok := m.tags[tag]
+	if !ok {
+		return models.TagSummary{Tag: tag}, nil
+	}
+
+	now := time.Now().UTC()
+	summary.Trend = decayTrend(summary.Trend, summary.Last_Updated, now)
+	summary.Last_Updated = now
+
+	return summary, nil
+}
+
+func (m *MemoryDatabase) GetTagUsage(ctx context.Context, tag string, from time.Time, to time.Time) ([]models.TagUsage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	fromDay := tagDay(from)
+	toDay := tagDay(to)
+
+	usage := []models.TagUsage{}
+	for day, dayUsage := range m.tagUsage[tag] {
+		if day >= fromDay && day <= toDay {
+			dayUsage.Hourly_Frecuency = maps.Clone(dayUsage.Hourly_Frecuency)
+			usage = append(usage, dayUsage)
+		}
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Day < usage[j].Day
+	})
+
+	return usage, nil
+}
+
+func (m *MemoryDatabase) GetUserMetricsSeries(ctx context.Context, userID string, limits models.MetricLimits) ([]models.UserMetricsBucket, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	from, to, location, err := parseMetricLimits(limits)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byStart := map[int64]models.UserMetricsBucket{}
+
+	for _, post := range m.posts {
+		if post.Author_ID != userID || post.Is_Retweet {
+			continue
+		}
+		if post.Time.Before(from) || !post.Time.Before(to) {
+			continue
+		}
+
+		start := models.TruncateToInterval(post.Time, limits.Interval, location)
+		bucket := byStart[start.Unix()]
+		bucket.Time = start
+		bucket.Likes += post.Likes
+		bucket.Retweets += post.Retweets
+		bucket.Posts++
+		byStart[start.Unix()] = bucket
+	}
+
+	buckets := []models.UserMetricsBucket{}
+	for _, bucket := range byStart {
+		buckets = append(buckets, bucket)
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Time.Before(buckets[j].Time)
+	})
+
+	return buckets, nil
+}
+
+func (m *MemoryDatabase) GetPlatformMetricsSeries(ctx context.Context, limits models.MetricLimits) ([]models.PlatformMetricsBucket, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	from, to, location, err := parseMetricLimits(limits)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	bookmarks := map[string]int{}
+	for _, postIDs := range m.bookmarks {
+		for _, postID := range postIDs {
+			bookmarks[postID]++
+		}
+	}
+
+	byStart := map[int64]models.PlatformMetricsBucket{}
+	authors := map[int64]map[string]bool{}
+
+	for _, post := range m.posts {
+		if post.Time.Before(from) || !post.Time.Before(to) {
+			continue
+		}
+		if (limits.Visibility == models.PUBLIC_VISIBILITY && !post.Public) || (limits.Visibility == models.PRIVATE_VISIBILITY && post.Public) {
+			continue
+		}
+
+		start := models.TruncateToInterval(post.Time, limits.Interval, location)
+		key := start.Unix()
+		bucket := byStart[key]
+		bucket.Time = start
+
+		if authors[key] == nil {
+			authors[key] = map[string]bool{}
+		}
+		authors[key][post.Retweet_Author_ID] = true
+
+		if post.Is_Retweet {
+			bucket.Retweets++
+		} else {
+			bucket.Posts++
+			bucket.Likes += post.Likes
+			bucket.Bookmarks += bookmarks[post.Post_ID]
+			if post.Blocked {
+				bucket.Blocked++
+			}
+			if post.Media_Info.Media_URL != "" {
+				bucket.Media_Posts++
+			}
+			if len(post.Mentions) > 0 {
+				bucket.Mention_Posts++
+			}
+		}
+
+		byStart[key] = bucket
+	}
+
+	buckets := []models.PlatformMetricsBucket{}
+	for key, bucket := range byStart {
+		bucket.Active_Authors = len(authors[key])
+		buckets = append(buckets, bucket)
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Time.Before(buckets[j].Time)
+	})
+
+	return buckets, nil
+}
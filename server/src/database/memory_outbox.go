@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"slices"
+	"time"
+)
+
+func (m *MemoryDatabase) AddOutboxMessages(ctx context.Context, messages []models.OutboxMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.outbox = append(m.outbox, messages...)
+
+	return nil
+}
+
+// outboxStep writes messages to the outbox as the last step of a write, as
+// the Mongo database does.
+func (m *MemoryDatabase) outboxStep(messages []models.OutboxMessage) writeStep {
+	return writeStep{
+		name: STEP_OUTBOX,
+		apply: func(ctx context.Context) error {
+			m.outbox = append(m.outbox, messages...)
+			return nil
+		},
+	}
+}
+
+// eventStep writes the event of a change to the outbox within its write.
+func (m *MemoryDatabase) eventStep(event models.QueueMessage) writeStep {
+	return m.outboxStep([]models.OutboxMessage{models.NewEventOutboxMessage(event)})
+}
+
+func (m *MemoryDatabase) ClaimOutboxMessages(ctx context.Context, owner string, dueBy time.Time, limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	due := []int{}
+	for i, message := range m.outbox {
+		if message.Status == models.OUTBOX_PENDING && !message.Next_Attempt.After(dueBy) {
+			due = append(due, i)
+		}
+	}
+
+	slices.SortStableFunc(due, func(a, b int) int {
+		return m.outbox[a].Next_Attempt.Compare(m.outbox[b].Next_Attempt)
+	})
+
+	messages := []models.OutboxMessage{}
+
+	for _, i := range due[:min(limit, len(due))] {
+		m.outbox[i].Next_Attempt = now.Add(lease)
+		m.outbox[i].Claimed_By = owner
+		messages = append(messages, m.outbox[i])
+	}
+
+	return messages, nil
+}
+
+func (m *MemoryDatabase) UpdateOutboxMessage(ctx context.Context, message models.OutboxMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.findOutboxIndex(message.Message_ID)
+
+	if i == -1 || m.outbox[i].Claimed_By != message.Claimed_By || !m.outbox[i].Next_Attempt.After(time.Now().UTC()) {
+		return postErrors.ErrOutboxLeaseLost
+	}
+
+	message.Claimed_By = ""
+	m.outbox[i] = message
+
+	return nil
+}
+
+func (m *MemoryDatabase) DeleteOutboxMessage(ctx context.Context, messageID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.outbox = slices.DeleteFunc(m.outbox, func(message models.OutboxMessage) bool {
+		return message.Message_ID == messageID
+	})
+
+	return nil
+}
+
+func (m *MemoryDatabase) GetOutboxMessages(ctx context.Context, status string, limit int) ([]models.OutboxMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	messages := []models.OutboxMessage{}
+	for _, message := range m.outbox {
+		if message.Status == status {
+			messages = append(messages, message)
+		}
+	}
+
+	slices.SortStableFunc(messages, func(a, b models.OutboxMessage) int {
+		return a.Created_At.Compare(b.Created_At)
+	})
+
+	return messages[:min(limit, len(messages))], nil
+}
+
+func (m *MemoryDatabase) CountOutboxMessages(ctx context.Context, status string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, message := range m.outbox {
+		if message.Status == status {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (m *MemoryDatabase) findOutboxIndex(messageID string) int {
+	return slices.IndexFunc(m.outbox, func(message models.OutboxMessage) bool {
+		return message.Message_ID == messageID
+	})
+}
@@ -0,0 +1,320 @@
+package database
+
+import (
+	"context"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"slices"
+	"strings"
+)
+
+func (m *MemoryDatabase) AddNewPost(ctx context.Context, newPost models.DBPost) (models.FrontPost, error) {
+	return m.AddNewPostWithOutbox(ctx, newPost, nil)
+}
+
+func (m *MemoryDatabase) AddNewPostWithOutbox(ctx context.Context, newPost models.DBPost, messages []models.OutboxMessage) (models.FrontPost, error) {
+	if err := ctx.Err(); err != nil {
+		return models.FrontPost{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	newPost.Thread_Path = m.threadPath(newPost)
+
+	posts := m.posts
+
+	steps := []writeStep{
+		{
+			name: STEP_POST,
+			apply: func(ctx context.Context) error {
+				m.posts = append(slices.Clone(m.posts), storedPost(newPost))
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.posts = posts
+				return nil
+			},
+		},
+	}
+
+	if changes := postTagChanges(newPost, 1); len(changes) > 0 {
+		steps = append(steps, writeStep{
+			name: STEP_TAG_METRICS,
+			apply: func(ctx context.Context) error {
+				m.updateTagMetrics(newPost.Time, changes)
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.updateTagMetrics(newPost.Time, changes.inverse())
+				return nil
+			},
+		})
+	}
+
+	if parentID := newPost.In_Reply_To_Post_ID; parentID != "" {
+		steps = append(steps, writeStep{
+			name: STEP_REPLIES_COUNTER,
+			apply: func(ctx context.Context) error {
+				m.incrementReplies(parentID, 1)
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.incrementReplies(parentID, -1)
+				return nil
+			},
+		})
+	}
+
+	if quotedID := newPost.Quoted_Post_ID; quotedID != "" {
+		steps = append(steps, writeStep{
+			name: STEP_QUOTES_COUNTER,
+			apply: func(ctx context.Context) error {
+				m.incrementQuotes(quotedID, 1)
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.incrementQuotes(quotedID, -1)
+				return nil
+			},
+		})
+	}
+
+	if len(messages) > 0 {
+		steps = append(steps, m.outboxStep(messages))
+	}
+
+	if err := m.hooks.runCompensated(ctx, "AddNewPostWithOutbox", steps); err != nil {
+		return models.FrontPost{}, err
+	}
+
+	return m.makeDBPostIntoFrontPost(newPost, newPost.Author_ID), nil
+}
+
+func (m *MemoryDatabase) GetPost(ctx context.Context, postID string, askerID string) (models.FrontPost, error) {
+	if err := ctx.Err(); err != nil {
+		return models.FrontPost{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	post, err := m.findPost(postID)
+
+	if err != nil {
+		return models.FrontPost{}, err
+	}
+
+	return m.makeDBPostIntoFrontPost(post, askerID), nil
+}
+
+func (m *MemoryDatabase) DeletePost(ctx context.Context, postID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Every step keeps what it removed so the write can be undone.
+	posts := m.posts
+	tombstones := m.tombstones
+	likers, liked := m.likes[postID]
+	retweeters, retweeted := m.retweets[postID]
+	var deleted models.DBPost
+
+	steps := []writeStep{
+		{
+			name: STEP_POST,
+			apply: func(ctx context.Context) error {
+				index := m.findPostIndex(postID)
+
+				if index == -1 {
+					return postErrors.ErrTwitsnapNotFound
+				}
+
+				deleted = m.posts[index]
+				m.posts = slices.Delete(slices.Clone(m.posts), index, index+1)
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.posts = posts
+				return nil
+			},
+		},
+		{
+			name: STEP_TAG_METRICS,
+			apply: func(ctx context.Context) error {
+				m.updateTagMetrics(deleted.Time, postTagChanges(deleted, -1))
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.updateTagMetrics(deleted.Time, postTagChanges(deleted, 1))
+				return nil
+			},
+		},
+		{
+			name: STEP_RETWEET_POSTS,
+			apply: func(ctx context.Context) error {
+				m.posts = slices.DeleteFunc(m.posts, func(post models.DBPost) bool {
+					return post.Original_Post_ID == postID
+				})
+				return nil
+			},
+		},
+		{
+			name: STEP_LIKERS,
+			apply: func(ctx context.Context) error {
+				delete(m.likes, postID)
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				if liked {
+					m.likes[postID] = likers
+				}
+				return nil
+			},
+		},
+		{
+			name: STEP_RETWEETERS,
+			apply: func(ctx context.Context) error {
+				delete(m.retweets, postID)
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				if retweeted {
+					m.retweets[postID] = retweeters
+				}
+				return nil
+			},
+		},
+		{
+			// A post with replies leaves a tombstone in its conversation. One
+			// without them is no longer a reply of its parent.
+			name: STEP_TOMBSTONE,
+			apply: func(ctx context.Context) error {
+				if deleted.Is_Retweet {
+					return nil
+				}
+
+				if deleted.Replies > 0 {
+					m.tombstones = append(slices.Clone(m.tombstones), models.NewTombstone(deleted))
+				} else if deleted.In_Reply_To_Post_ID != "" {
+					m.incrementReplies(deleted.In_Reply_To_Post_ID, -1)
+				}
+				return nil
+			},
+			// The reply count of the parent comes back with the posts.
+			undo: func(ctx context.Context) error {
+				m.tombstones = tombstones
+				return nil
+			},
+		},
+		{
+			name: STEP_QUOTES_COUNTER,
+			apply: func(ctx context.Context) error {
+				if !deleted.Is_Retweet && deleted.Quoted_Post_ID != "" {
+					m.incrementQuotes(deleted.Quoted_Post_ID, -1)
+				}
+				return nil
+			},
+			// The quote count comes back with the posts.
+		},
+		m.eventStep(models.NewContentChangeEvent(models.DELETED_CONTENT, postID)),
+	}
+
+	return m.hooks.runCompensated(ctx, "DeletePost", steps)
+}
+
+func (m *MemoryDatabase) EditPost(ctx context.Context, postID string, editInfo models.EditPostExpectedFormat, askerID string) (models.FrontPost, error) {
+	if err := ctx.Err(); err != nil {
+		return models.FrontPost{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	post, err := m.findPost(postID)
+
+	if err != nil {
+		return models.FrontPost{}, err
+	}
+
+	return m.saveRevision(ctx, post, editedPost(post, editInfo), askerID, nil)
+}
+
+func (m *MemoryDatabase) GetAllPosts(ctx context.Context, limitConfig models.LimitConfig, askerID string) ([]models.FrontPost, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	posts, hasMore := m.findPosts(func(post models.DBPost) bool {
+		return true
+	}, limitConfig, askerID)
+
+	return posts, hasMore, nil
+}
+
+func (m *MemoryDatabase) BlockPost(ctx context.Context, postID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return m.setBlocked(ctx, "BlockPost", postID, true, models.BLOCKED_CONTENT)
+}
+
+func (m *MemoryDatabase) UnBlockPost(ctx context.Context, postID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return m.setBlocked(ctx, "UnBlockPost", postID, false, models.UNBLOCKED_CONTENT)
+}
+
+func (m *MemoryDatabase) setBlocked(ctx context.Context, operation string, postID string, blocked bool, messageType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var was bool
+
+	steps := []writeStep{
+		{
+			name: STEP_POST,
+			apply: func(ctx context.Context) error {
+				index := m.findPostIndex(postID)
+
+				if index == -1 {
+					return postErrors.ErrTwitsnapNotFound
+				}
+
+				was = m.posts[index].Blocked
+				m.posts[index].Blocked = blocked
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				if index := m.findPostIndex(postID); index != -1 {
+					m.posts[index].Blocked = was
+				}
+				return nil
+			},
+		},
+		m.eventStep(models.NewContentChangeEvent(messageType, postID)),
+	}
+
+	return m.hooks.runCompensated(ctx, operation, steps)
+}
+
+func contentTags(content string) []string {
+	tags := []string{}
+
+	for _, word := range strings.Split(content, " ") {
+		if strings.HasPrefix(word, "#") {
+			tags = append(tags, word[1:])
+		}
+	}
+
+	return tags
+}
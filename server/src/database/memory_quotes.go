@@ -0,0 +1,26 @@
+package database
+
+import "server/src/models"
+
+// incrementQuotes counts delta more quotes on the post and its retweets.
+func (m *MemoryDatabase) incrementQuotes(originalPostID string, delta int) {
+	m.updateByOriginal(originalPostID, func(post *models.DBPost) {
+		post.Quotes += delta
+	})
+}
+
+// findQuotedPosts returns the posts quoted by posts by ID, blocked or not.
+func (m *MemoryDatabase) findQuotedPosts(posts []models.DBPost) map[string]models.DBPost {
+	quoted := map[string]models.DBPost{}
+
+	for _, post := range posts {
+		if post.Quoted_Post_ID == "" {
+			continue
+		}
+		if index := m.findPostIndex(post.Quoted_Post_ID); index != -1 {
+			quoted[post.Quoted_Post_ID] = m.posts[index]
+		}
+	}
+
+	return quoted
+}
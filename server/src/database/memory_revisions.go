@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"slices"
+	"sort"
+)
+
+func (m *MemoryDatabase) GetRevisions(ctx context.Context, postID string, limitConfig models.LimitConfig) ([]models.Revision, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, err := m.findPost(postID); err != nil {
+		return nil, false, err
+	}
+
+	revisions := m.postRevisions(postID, 0)
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Number > revisions[j].Number
+	})
+
+	skip := min(max(limitConfig.Skip, 0), len(revisions))
+	revisions = revisions[skip:]
+
+	hasMore := len(revisions) > limitConfig.Limit
+
+	if hasMore {
+		revisions = revisions[:limitConfig.Limit]
+	}
+
+	return revisions, hasMore, nil
+}
+
+func (m *MemoryDatabase) RevertPost(ctx context.Context, postID string, revision int, askerID string) (models.FrontPost, error) {
+	if err := ctx.Err(); err != nil {
+		return models.FrontPost{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	post, err := m.findPost(postID)
+
+	if err != nil {
+		return models.FrontPost{}, err
+	}
+
+	if revision < 0 || revision > post.Edits {
+		return models.FrontPost{}, postErrors.ErrRevisionNotFound
+	}
+
+	return m.saveRevision(ctx, post, revertedPost(post, m.postRevisions(postID, revision)), askerID, &revision)
+}
+
+// postRevisions returns the revisions of the post made after the one numbered
+// after.
+func (m *MemoryDatabase) postRevisions(postID string, after int) []models.Revision {
+	revisions := []models.Revision{}
+
+	for _, revision := range m.revisions {
+		if revision.Post_ID == postID && revision.Number > after {
+			revisions = append(revisions, revision)
+		}
+	}
+
+	return revisions
+}
+
+func (m *MemoryDatabase) saveRevision(ctx context.Context, post models.DBPost, edited models.DBPost, editorID string, revertedTo *int) (models.FrontPost, error) {
+	revision, changed := models.NewRevision(post, edited, editorID)
+
+	if !changed {
+		return m.makeDBPostIntoFrontPost(post, editorID), nil
+	}
+
+	revision.Reverted_To = revertedTo
+	edited.Edits = revision.Number
+
+	steps := []writeStep{
+		{
+			name: STEP_POST,
+			apply: func(ctx context.Context) error {
+				m.posts[m.findPostIndex(post.Post_ID)] = edited
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.posts[m.findPostIndex(post.Post_ID)] = post
+				return nil
+			},
+		},
+	}
+
+	if changes := editTagChanges(post.Tags, edited.Tags); !post.Is_Retweet && len(changes) > 0 {
+		steps = append(steps, writeStep{
+			name: STEP_TAG_METRICS,
+			apply: func(ctx context.Context) error {
+				m.updateTagMetrics(post.Time, changes)
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				m.updateTagMetrics(post.Time, changes.inverse())
+				return nil
+			},
+		})
+	}
+
+	revisions := m.revisions
+
+	steps = append(steps, writeStep{
+		name: STEP_REVISION,
+		apply: func(ctx context.Context) error {
+			m.revisions = append(slices.Clone(m.revisions), revision)
+			return nil
+		},
+		undo: func(ctx context.Context) error {
+			m.revisions = revisions
+			return nil
+		},
+	}, m.eventStep(models.NewContentEvent(models.EDITED_CONTENT, edited.Post_ID, edited.Author_ID, edited.Tags)))
+
+	if err := m.hooks.runCompensated(ctx, "EditPost", steps); err != nil {
+		return models.FrontPost{}, err
+	}
+
+	return m.makeDBPostIntoFrontPost(edited, editorID), nil
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"math"
+	"server/src/models"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (m *MemoryDatabase) GetTagSuggestions(ctx context.Context, prefix string, following []string, now time.Time, limit int) ([]models.TagSuggestion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	type spelling struct{ key, tag string }
+
+	spellings := map[spelling]*models.TagSuggestion{}
+
+	for _, post := range m.posts {
+		if post.Is_Retweet || post.Blocked || !(post.Public || slices.Contains(following, post.Author_ID)) {
+			continue
+		}
+
+		hours := math.Max(0, float64(now.Sub(post.Time).Milliseconds())/(1000*60*60))
+		seen := map[spelling]bool{}
+
+		for i, tag := range post.Tags {
+			current := spelling{key: models.TagKey(tag), tag: tag}
+			if i < len(post.Tag_Keys) {
+				current.key = post.Tag_Keys[i]
+			}
+
+			if seen[current] || !strings.HasPrefix(current.key, prefix) {
+				continue
+			}
+			seen[current] = true
+
+			counted, ok := spellings[current]
+			if !ok {
+				counted = &models.TagSuggestion{Tag: tag}
+				spellings[current] = counted
+			}
+
+			counted.Posts++
+			counted.Score += math.Exp(-TRENDING_DECAY * hours)
+			if post.Time.After(counted.Last_Used) {
+				counted.Last_Used = post.Time
+			}
+		}
+	}
+
+	byKey := map[string]*models.TagSuggestion{}
+	mostUsed := map[string]int{}
+
+	for current, counted := range spellings {
+		suggestion, ok := byKey[current.key]
+		if !ok {
+			suggestion = &models.TagSuggestion{}
+			byKey[current.key] = suggestion
+		}
+
+		used := mostUsed[current.key]
+		if counted.Posts > used || (counted.Posts == used && counted.Tag < suggestion.Tag) {
+			suggestion.Tag = counted.Tag
+			mostUsed[current.key] = counted.Posts
+		}
+
+		suggestion.Posts += counted.Posts
+		suggestion.Score += counted.Score
+		if counted.Last_Used.After(suggestion.Last_Used) {
+			suggestion.Last_Used = counted.Last_Used
+		}
+	}
+
+	suggestions := []models.TagSuggestion{}
+
+	for _, suggestion := range byKey {
+		suggestion.Score = tagSuggestionScore(suggestion.Score, suggestion.Posts)
+		suggestions = append(suggestions, *suggestion)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Tag < suggestions[j].Tag
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"math"
+	"server/src/models"
+	"slices"
+	"sort"
+	"time"
+)
+
+func (m *MemoryDatabase) ComputeTrendingTopics(ctx context.Context, since time.Time, now time.Time, decay float64, limit int) ([]models.TrendingTopic, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	posts := map[string]int{}
+	totalHours := map[string]float64{}
+
+	for _, post := range m.posts {
+		if post.Is_Retweet || !post.Public || post.Blocked {
+			continue
+		}
+		if post.Time.Before(since) || post.Time.After(now) {
+			continue
+		}
+
+		hours := float64(now.Sub(post.Time).Milliseconds()) / (1000 * 60 * 60)
+		for _, tag := range post.Tags {
+			posts[tag]++
+			totalHours[tag] += hours
+		}
+	}
+
+	topics := []models.TrendingTopic{}
+
+	for tag, count := range posts {
+		averageHours := totalHours[tag] / float64(count)
+		topics = append(topics, models.TrendingTopic{Tag: tag, Posts: count, Score: float64(count) * math.Exp(-decay*averageHours)})
+	}
+
+	sort.Slice(topics, func(i, j int) bool {
+		if topics[i].Score != topics[j].Score {
+			return topics[i].Score > topics[j].Score
+		}
+		return topics[i].Tag < topics[j].Tag
+	})
+
+	if len(topics) > limit {
+		topics = topics[:limit]
+	}
+
+	return topics, nil
+}
+
+func (m *MemoryDatabase) SaveTrendingTopics(ctx context.Context, snapshot models.TrendingSnapshot) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot.Topics = slices.Clone(snapshot.Topics)
+	m.trending[snapshot.Window] = snapshot
+
+	return nil
+}
+
+func (m *MemoryDatabase) GetTrendingTopics(ctx context.Context, window string) (models.TrendingSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return models.TrendingSnapshot{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshot, ok := m.trending[window]
+	if !ok {
+		return models.TrendingSnapshot{Window: window, Topics: []models.TrendingTopic{}}, nil
+	}
+
+	snapshot.Topics = slices.Clone(snapshot.Topics)
+
+	return snapshot, nil
+}
+
+func (m *MemoryDatabase) GetTrendingPosts(ctx context.Context, following []string, askerID string, since time.Time, now time.Time, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matched := []models.DBPost{}
+	scores := map[string]float64{}
+
+	for _, post := range m.posts {
+		if post.Is_Retweet || post.Blocked || post.Time.Before(since) || post.Time.After(now) {
+			continue
+		}
+		if !post.Public && !slices.Contains(following, post.Author_ID) {
+			continue
+		}
+
+		matched = append(matched, post)
+		scores[post.Post_ID] = trendingPostScore(post, now)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if scores[matched[i].Post_ID] != scores[matched[j].Post_ID] {
+			return scores[matched[i].Post_ID] > scores[matched[j].Post_ID]
+		}
+		return sortsBefore(matched[i].Time, matched[i].Post_ID, matched[j].Time, matched[j].Post_ID)
+	})
+
+	skip := min(max(limitConfig.Skip, 0), len(matched))
+	matched = matched[skip:]
+
+	if limit := limitConfig.Limit + 1; limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+
+	posts := m.createPostList(matched, askerID)
+
+	hasMore := len(posts) > limitConfig.Limit
+
+	if hasMore {
+		posts = posts[:len(posts)-1]
+	}
+
+	return posts, hasMore, nil
+}
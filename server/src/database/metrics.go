@@ -7,10 +7,11 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func (d *AppDatabase) GetUserMetrics(userID string, limits models.MetricLimits) (models.UserMetrics, error) {
+func (d *AppDatabase) GetUserMetrics(ctx context.Context, userID string, limits models.MetricLimits) (models.UserMetrics, error) {
 	postCollection := d.db.Collection(FEED_COLLECTION)
 
 	parsedFromTime, err := time.Parse(time.RFC3339, limits.FromTime)
@@ -41,7 +42,7 @@ func (d *AppDatabase) GetUserMetrics(userID string, limits models.MetricLimits)
 		}}},
 	}
 
-	cursor, err := postCollection.Aggregate(context.Background(), pipeline)
+	cursor, err := postCollection.Aggregate(ctx, pipeline)
 
 	if err != nil {
 		log.Println(err)
@@ -50,8 +51,9 @@ func (d *AppDatabase) GetUserMetrics(userID string, limits models.MetricLimits)
 
 	var result []bson.M
 
-	if err := cursor.All(context.Background(), &result); err != nil {
-		log.Fatal(err)
+	if err := cursor.All(ctx, &result); err != nil {
+		log.Println(err)
+		return models.UserMetrics{}, err
 	}
 
 	var metrics models.UserMetrics
@@ -64,4 +66,193 @@ func (d *AppDatabase) GetUserMetrics(userID string, limits models.MetricLimits)
 	}
 
 	return metrics, nil
-}
\ No newline at end of file
+}
+
+// parseMetricLimits reads the range and the timezone of limits.
+func parseMetricLimits(limits models.MetricLimits) (time.Time, time.Time, *time.Location, error) {
+	from, err := time.Parse(time.RFC3339, limits.FromTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, err
+	}
+
+	to, err := time.Parse(time.RFC3339, limits.ToTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, err
+	}
+
+	location, err := time.LoadLocation(limits.Timezone)
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, err
+	}
+
+	return from, to, location, nil
+}
+
+// bucketStart is the start of the bucket of the time of a post, as models'
+// TruncateToInterval computes it.
+func bucketStart(interval string, location *time.Location) bson.D {
+	truncate := bson.D{
+		{Key: "date", Value: "$" + TIME_FIELD},
+		{Key: "unit", Value: interval},
+		{Key: "timezone", Value: location.String()},
+	}
+	if interval == models.WEEK_INTERVAL {
+		truncate = append(truncate, bson.E{Key: "startOfWeek", Value: "monday"})
+	}
+
+	return bson.D{{Key: "$dateTrunc", Value: truncate}}
+}
+
+func (d *AppDatabase) GetUserMetricsSeries(ctx context.Context, userID string, limits models.MetricLimits) ([]models.UserMetricsBucket, error) {
+	postCollection := d.db.Collection(FEED_COLLECTION)
+
+	from, to, location, err := parseMetricLimits(limits)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	pipeline := mongo.Pipeline{
+
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: TIME_FIELD, Value: bson.D{{Key: "$gte", Value: from.UTC()}, {Key: "$lt", Value: to.UTC()}}},
+			{Key: AUTHOR_ID_FIELD, Value: userID},
+			{Key: IS_RETWEET_FIELD, Value: false},
+		}}},
+
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bucketStart(limits.Interval, location)},
+			{Key: "likes", Value: bson.D{{Key: "$sum", Value: "$" + LIKES_FIELD}}},
+			{Key: "retweets", Value: bson.D{{Key: "$sum", Value: "$" + RETWEET_FIELD}}},
+			{Key: "posts", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := postCollection.Aggregate(ctx, pipeline)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	var result []bson.M
+
+	if err := cursor.All(ctx, &result); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	buckets := []models.UserMetricsBucket{}
+
+	for _, bucket := range result {
+		start, _ := bucket["_id"].(primitive.DateTime)
+
+		buckets = append(buckets, models.UserMetricsBucket{
+			Time:     start.Time().In(location),
+			Likes:    convertToInt(bucket["likes"]),
+			Retweets: convertToInt(bucket["retweets"]),
+			Posts:    convertToInt(bucket["posts"]),
+		})
+	}
+
+	return buckets, nil
+}
+
+func (d *AppDatabase) GetPlatformMetricsSeries(ctx context.Context, limits models.MetricLimits) ([]models.PlatformMetricsBucket, error) {
+	postCollection := d.db.Collection(FEED_COLLECTION)
+
+	from, to, location, err := parseMetricLimits(limits)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	filter := bson.D{{Key: TIME_FIELD, Value: bson.D{{Key: "$gte", Value: from.UTC()}, {Key: "$lt", Value: to.UTC()}}}}
+
+	switch limits.Visibility {
+	case models.PUBLIC_VISIBILITY:
+		filter = append(filter, bson.E{Key: PUBLIC_FIELD, Value: true})
+	case models.PRIVATE_VISIBILITY:
+		filter = append(filter, bson.E{Key: PUBLIC_FIELD, Value: false})
+	}
+
+	original := bson.D{{Key: "$not", Value: bson.A{"$" + IS_RETWEET_FIELD}}}
+	countOriginals := func(condition interface{}) bson.D {
+		return bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$and", Value: bson.A{original, condition}}}, 1, 0}}}}}
+	}
+	sumOriginals := func(value interface{}) bson.D {
+		return bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{original, value, 0}}}}}
+	}
+
+	hasMedia := bson.D{{Key: "$gt", Value: bson.A{
+		bson.D{{Key: "$ifNull", Value: bson.A{"$" + MEDIA_INFO_FIELD + ".media_url", ""}}},
+		"",
+	}}}
+	hasMentions := bson.D{{Key: "$gt", Value: bson.A{
+		bson.D{{Key: "$size", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$" + MENTIONS_FIELD, bson.A{}}}}}},
+		0,
+	}}}
+
+	pipeline := mongo.Pipeline{
+
+		bson.D{{Key: "$match", Value: filter}},
+
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: BOOKMARK_COLLECTION},
+			{Key: "localField", Value: POST_ID_FIELD},
+			{Key: "foreignField", Value: POST_ID_FIELD},
+			{Key: "as", Value: BOOKMARK_FIELD},
+		}}},
+
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bucketStart(limits.Interval, location)},
+			{Key: "posts", Value: countOriginals(true)},
+			{Key: "retweets", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{"$" + IS_RETWEET_FIELD, 1, 0}}}}}},
+			{Key: "likes", Value: sumOriginals("$" + LIKES_FIELD)},
+			{Key: "bookmarks", Value: sumOriginals(bson.D{{Key: "$size", Value: "$" + BOOKMARK_FIELD}})},
+			{Key: "blocked", Value: countOriginals(bson.D{{Key: "$eq", Value: bson.A{"$" + BLOCKED_FIELD, true}}})},
+			{Key: "authors", Value: bson.D{{Key: "$addToSet", Value: "$" + RETWEET_AUTHOR_FIELD}}},
+			{Key: "media", Value: countOriginals(hasMedia)},
+			{Key: "mentions", Value: countOriginals(hasMentions)},
+		}}},
+
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := postCollection.Aggregate(ctx, pipeline)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	var result []bson.M
+
+	if err := cursor.All(ctx, &result); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	buckets := []models.PlatformMetricsBucket{}
+
+	for _, bucket := range result {
+		start, _ := bucket["_id"].(primitive.DateTime)
+		authors, _ := bucket["authors"].(primitive.A)
+
+		buckets = append(buckets, models.PlatformMetricsBucket{
+			Time:           start.Time().In(location),
+			Posts:          convertToInt(bucket["posts"]),
+			Retweets:       convertToInt(bucket["retweets"]),
+			Likes:          convertToInt(bucket["likes"]),
+			Bookmarks:      convertToInt(bucket["bookmarks"]),
+			Blocked:        convertToInt(bucket["blocked"]),
+			Active_Authors: len(authors),
+			Media_Posts:    convertToInt(bucket["media"]),
+			Mention_Posts:  convertToInt(bucket["mentions"]),
+		})
+	}
+
+	return buckets, nil
+}
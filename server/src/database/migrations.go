@@ -0,0 +1,680 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"server/src/models"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	MIGRATIONS_COLLECTION = "migrations"
+	MIGRATION_LOCK_ID     = "lock"
+	MIGRATION_LOCK_LEASE  = time.Minute
+	MIGRATION_LOCK_RETRY  = 500 * time.Millisecond
+	MIGRATION_LOCK_RENEW  = MIGRATION_LOCK_LEASE / 3
+	NAMESPACE_EXISTS_CODE = 48
+	TAG_KEYS_BATCH_SIZE   = 500
+)
+
+// Migration is one versioned change to the schema or the data. Up has to be
+// idempotent: if a replica dies halfway the next one runs it again.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// migrations are applied in order. New ones go at the end with the next
+// version; applied ones must never be edited.
+var migrations = []Migration{
+	{1, "create feed indexes", createFeedIndexes},
+	{2, "add schema validators", addSchemaValidators},
+	{3, "backfill blocked field on posts", backfillBlocked},
+	{4, "create cursor pagination index", createCursorIndex},
+	{5, "create outbox indexes", createOutboxIndexes},
+	{6, "create and backfill hashtag metrics", backfillTagMetrics},
+	{7, "index bookmarks by post", createBookmarkPostIndex},
+	{8, "create trending topics index", createTrendingIndex},
+	{9, "create posts text index", createTextIndex},
+	{10, "backfill and index tag keys", backfillTagKeys},
+	{11, "backfill conversations and index replies", backfillConversations},
+	{12, "backfill edit counts and index revisions", backfillEdits},
+	{13, "backfill and index thread paths", backfillThreadPaths},
+}
+
+var errMigrationLockLost = errors.New("migration lock lost")
+
+// Migrations returns the known migrations in the order they are applied.
+func Migrations() []Migration {
+	return slices.Clone(migrations)
+}
+
+type migrationRecord struct {
+	Version     int       `bson:"version"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// RunMigrations applies the migrations that are missing from the migrations
+// collection. Replicas starting at the same time take turns through a lock
+// document, so every migration runs once. The lease of the lock is renewed in
+// the background while the migrations run, however long they take, and they
+// are stopped if it is lost.
+func RunMigrations(ctx context.Context, client *mongo.Client) error {
+	db := client.Database(DATABASE_NAME)
+
+	if err := checkMigrationOrder(migrations); err != nil {
+		return err
+	}
+
+	owner := uuid.NewString()
+
+	if err := acquireMigrationLock(ctx, db, owner); err != nil {
+		return err
+	}
+	defer releaseMigrationLock(context.WithoutCancel(ctx), db, owner)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	go keepMigrationLock(ctx, cancel, db, owner)
+
+	applied, err := appliedMigrations(ctx, db)
+
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if applied[migration.Version] {
+			continue
+		}
+
+		log.Println("Applying migration", migration.Version, ":", migration.Description)
+
+		if err := migration.Up(ctx, db); err != nil {
+			if errors.Is(err, context.Canceled) {
+				err = context.Cause(ctx)
+			}
+			return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+
+		if err := recordMigration(ctx, db, migration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkMigrationOrder(migrations []Migration) error {
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version <= migrations[i-1].Version {
+			return fmt.Errorf("migration %d is out of order", migrations[i].Version)
+		}
+	}
+	return nil
+}
+
+// acquireMigrationLock takes or renews the lock document. A lock whose lease
+// expired is taken over, so a replica that crashed does not block the others.
+func acquireMigrationLock(ctx context.Context, db *mongo.Database, owner string) error {
+	collection := db.Collection(MIGRATIONS_COLLECTION)
+
+	for {
+		now := time.Now().UTC()
+
+		filter := bson.M{
+			"_id": MIGRATION_LOCK_ID,
+			"$or": []bson.M{
+				{"owner": owner},
+				{"expires_at": bson.M{"$lt": now}},
+			},
+		}
+		update := bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(MIGRATION_LOCK_LEASE)}}
+
+		_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+
+		if err == nil {
+			return nil
+		}
+
+		// The upsert collides with the lock document of another replica.
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(MIGRATION_LOCK_RETRY):
+		}
+	}
+}
+
+// keepMigrationLock renews the lease of the lock held by owner until ctx is
+// done. If another replica took the lock over, the migrations are cancelled
+// with errMigrationLockLost; other errors are retried on the next renewal,
+// well before the lease expires.
+func keepMigrationLock(ctx context.Context, cancel context.CancelCauseFunc, db *mongo.Database, owner string) {
+	ticker := time.NewTicker(MIGRATION_LOCK_RENEW)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		filter := bson.M{"_id": MIGRATION_LOCK_ID, "owner": owner}
+		update := bson.M{"$set": bson.M{"expires_at": time.Now().UTC().Add(MIGRATION_LOCK_LEASE)}}
+
+		result, err := db.Collection(MIGRATIONS_COLLECTION).UpdateOne(ctx, filter, update)
+
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Println("Could not renew migration lock:", err)
+			}
+			continue
+		}
+
+		if result.MatchedCount == 0 {
+			cancel(errMigrationLockLost)
+			return
+		}
+	}
+}
+
+func releaseMigrationLock(ctx context.Context, db *mongo.Database, owner string) {
+	_, err := db.Collection(MIGRATIONS_COLLECTION).DeleteOne(ctx, bson.M{"_id": MIGRATION_LOCK_ID, "owner": owner})
+
+	if err != nil {
+		log.Println("Could not release migration lock:", err)
+	}
+}
+
+func appliedMigrations(ctx context.Context, db *mongo.Database) (map[int]bool, error) {
+	cursor, err := db.Collection(MIGRATIONS_COLLECTION).Find(ctx, bson.M{"version": bson.M{"$exists": true}})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var records []migrationRecord
+
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := map[int]bool{}
+
+	for _, record := range records {
+		applied[record.Version] = true
+	}
+
+	return applied, nil
+}
+
+func recordMigration(ctx context.Context, db *mongo.Database, migration Migration) error {
+	record := migrationRecord{Version: migration.Version, Description: migration.Description, AppliedAt: time.Now().UTC()}
+
+	_, err := db.Collection(MIGRATIONS_COLLECTION).UpdateOne(ctx,
+		bson.M{"version": migration.Version},
+		bson.M{"$setOnInsert": record},
+		options.Update().SetUpsert(true))
+
+	return err
+}
+
+func createFeedIndexes(ctx context.Context, db *mongo.Database) error {
+	indexes := map[string][]mongo.IndexModel{
+		FEED_COLLECTION: {
+			{Keys: bson.D{{Key: POST_ID_FIELD, Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: ORIGINAL_POST_ID_FIELD, Value: 1}}},
+			{Keys: bson.D{{Key: TIME_FIELD, Value: -1}}},
+			{Keys: bson.D{{Key: AUTHOR_ID_FIELD, Value: 1}, {Key: TIME_FIELD, Value: -1}}},
+			{Keys: bson.D{{Key: RETWEET_AUTHOR_FIELD, Value: 1}, {Key: TIME_FIELD, Value: -1}}},
+			{Keys: bson.D{{Key: TAGS_FIELD, Value: 1}, {Key: TIME_FIELD, Value: -1}}},
+			{Keys: bson.D{{Key: BLOCKED_FIELD, Value: 1}, {Key: PUBLIC_FIELD, Value: 1}, {Key: TIME_FIELD, Value: -1}}},
+		},
+		LIKES_COLLECTION: {
+			{Keys: bson.D{{Key: ORIGINAL_POST_ID_FIELD, Value: 1}, {Key: LIKERS_FIELD, Value: 1}}},
+		},
+		RETWEET_COLLECTION: {
+			{Keys: bson.D{{Key: ORIGINAL_POST_ID_FIELD, Value: 1}, {Key: RETWEETERS_FIELD, Value: 1}}},
+		},
+		BOOKMARK_COLLECTION: {
+			{Keys: bson.D{{Key: AUTHOR_ID_FIELD, Value: 1}, {Key: POST_ID_FIELD, Value: 1}}},
+		},
+	}
+
+	for collection, indexModels := range indexes {
+		// Creating an index that already exists with the same keys is a no-op.
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, indexModels); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createCursorIndex backs the (time, post_id) sort of every listing.
+func createCursorIndex(ctx context.Context, db *mongo.Database) error {
+	index := mongo.IndexModel{Keys: bson.D{{Key: TIME_FIELD, Value: -1}, {Key: POST_ID_FIELD, Value: -1}}}
+
+	_, err := db.Collection(FEED_COLLECTION).Indexes().CreateOne(ctx, index)
+
+	return err
+}
+
+func createOutboxIndexes(ctx context.Context, db *mongo.Database) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: MESSAGE_ID_FIELD, Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: STATUS_FIELD, Value: 1}, {Key: NEXT_ATTEMPT_FIELD, Value: 1}}},
+	}
+
+	_, err := db.Collection(OUTBOX_COLLECTION).Indexes().CreateMany(ctx, indexes)
+
+	return err
+}
+
+// createBookmarkPostIndex lets the platform metrics count the bookmarks of a
+// post without scanning every user's bookmarks.
+func createBookmarkPostIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(BOOKMARK_COLLECTION).Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: POST_ID_FIELD, Value: 1}}})
+
+	return err
+}
+
+func createTrendingIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(TRENDING_COLLECTION).Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: WINDOW_FIELD, Value: 1}}, Options: options.Index().SetUnique(true)})
+
+	return err
+}
+
+// createTextIndex indexes the words of the posts for word searches. Without a
+// language words are neither stemmed nor dropped as stop words.
+func createTextIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(FEED_COLLECTION).Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: CONTENT_FIELD, Value: "text"}}, Options: options.Index().SetDefaultLanguage("none")})
+
+	return err
+}
+
+// backfillTagKeys gives the posts from before tag suggestions the keys of
+// their tags, which are computed in Go, and indexes them for prefix lookups.
+func backfillTagKeys(ctx context.Context, db *mongo.Database) error {
+	postCollection := db.Collection(FEED_COLLECTION)
+
+	filter := bson.M{TAG_KEYS_FIELD: bson.M{"$exists": false}}
+	cursor, err := postCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{POST_ID_FIELD: 1, TAGS_FIELD: 1}))
+
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	updates := []mongo.WriteModel{}
+	backfilled := 0
+
+	flush := func() error {
+		if len(updates) == 0 {
+			return nil
+		}
+		_, err := postCollection.BulkWrite(ctx, updates)
+		backfilled += len(updates)
+		updates = updates[:0]
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var post models.DBPost
+		if err := cursor.Decode(&post); err != nil {
+			return err
+		}
+
+		updates = append(updates, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{POST_ID_FIELD: post.Post_ID}).
+			SetUpdate(bson.M{"$set": bson.M{TAG_KEYS_FIELD: models.TagKeys(post.Tags)}}))
+
+		if len(updates) == TAG_KEYS_BATCH_SIZE {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	log.Println("Backfilled tag keys on", backfilled, "posts")
+
+	_, err = postCollection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: TAG_KEYS_FIELD, Value: 1}}})
+
+	return err
+}
+
+// backfillConversations makes every post from before replies the start of
+// its own conversation, or of the one of its original for retweets.
+func backfillConversations(ctx context.Context, db *mongo.Database) error {
+	postCollection := db.Collection(FEED_COLLECTION)
+
+	filter := bson.M{CONVERSATION_ID_FIELD: bson.M{"$exists": false}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		CONVERSATION_ID_FIELD: "$" + ORIGINAL_POST_ID_FIELD,
+		IN_REPLY_TO_FIELD:     "",
+		REPLIES_FIELD:         0,
+	}}}}
+
+	result, err := postCollection.UpdateMany(ctx, filter, update)
+
+	if err != nil {
+		return err
+	}
+
+	log.Println("Backfilled conversations on", result.ModifiedCount, "posts")
+
+	_, err = postCollection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: CONVERSATION_ID_FIELD, Value: 1}, {Key: TIME_FIELD, Value: 1}}})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection(TOMBSTONE_COLLECTION).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: POST_ID_FIELD, Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: CONVERSATION_ID_FIELD, Value: 1}}},
+	})
+
+	return err
+}
+
+// backfillEdits starts the posts from before revisions with no edits, since
+// edits are matched on their count.
+func backfillEdits(ctx context.Context, db *mongo.Database) error {
+	filter := bson.M{EDITS_FIELD: bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{EDITS_FIELD: 0}}
+
+	result, err := db.Collection(FEED_COLLECTION).UpdateMany(ctx, filter, update)
+
+	if err != nil {
+		return err
+	}
+
+	log.Println("Backfilled edit counts on", result.ModifiedCount, "posts")
+
+	_, err = db.Collection(REVISION_COLLECTION).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: POST_ID_FIELD, Value: 1}, {Key: NUMBER_FIELD, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return err
+}
+
+// backfillThreadPaths gives a thread path to the posts and tombstones from
+// before threads were paged by it. They are read oldest first, so a parent
+// has its path before its replies need it; the ones that have a path keep it.
+func backfillThreadPaths(ctx context.Context, db *mongo.Database) error {
+	index := mongo.IndexModel{Keys: bson.D{{Key: CONVERSATION_ID_FIELD, Value: 1}, {Key: THREAD_PATH_FIELD, Value: 1}}}
+
+	for _, collection := range []string{FEED_COLLECTION, TOMBSTONE_COLLECTION} {
+		if _, err := db.Collection(collection).Indexes().CreateOne(ctx, index); err != nil {
+			return err
+		}
+	}
+
+	fields := bson.M{POST_ID_FIELD: 1, IN_REPLY_TO_FIELD: 1, TIME_FIELD: 1, THREAD_PATH_FIELD: 1}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{IS_RETWEET_FIELD: false}}},
+		{{Key: "$project", Value: fields}},
+		{{Key: "$set", Value: bson.M{"collection": FEED_COLLECTION}}},
+		{{Key: "$unionWith", Value: bson.M{"coll": TOMBSTONE_COLLECTION, "pipeline": mongo.Pipeline{
+			{{Key: "$project", Value: fields}},
+			{{Key: "$set", Value: bson.M{"collection": TOMBSTONE_COLLECTION}}},
+		}}}},
+		{{Key: "$sort", Value: bson.D{{Key: TIME_FIELD, Value: 1}, {Key: POST_ID_FIELD, Value: 1}}}},
+	}
+
+	cursor, err := db.Collection(FEED_COLLECTION).Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	paths := map[string]string{}
+	updates := map[string][]mongo.WriteModel{}
+	backfilled := 0
+
+	flush := func(collection string) error {
+		if len(updates[collection]) == 0 {
+			return nil
+		}
+		_, err := db.Collection(collection).BulkWrite(ctx, updates[collection])
+		backfilled += len(updates[collection])
+		updates[collection] = nil
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var entry struct {
+			models.DBPost `bson:",inline"`
+			Collection    string `bson:"collection"`
+		}
+		if err := cursor.Decode(&entry); err != nil {
+			return err
+		}
+
+		post := entry.DBPost
+
+		if post.Thread_Path == "" {
+			post.Thread_Path = models.ThreadPath(paths[post.In_Reply_To_Post_ID], post)
+
+			updates[entry.Collection] = append(updates[entry.Collection], mongo.NewUpdateOneModel().
+				SetFilter(bson.M{POST_ID_FIELD: post.Post_ID}).
+				SetUpdate(bson.M{"$set": bson.M{THREAD_PATH_FIELD: post.Thread_Path}}))
+
+			if len(updates[entry.Collection]) == TAG_KEYS_BATCH_SIZE {
+				if err := flush(entry.Collection); err != nil {
+					return err
+				}
+			}
+		}
+
+		paths[post.Post_ID] = post.Thread_Path
+	}
+
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	for _, collection := range []string{FEED_COLLECTION, TOMBSTONE_COLLECTION} {
+		if err := flush(collection); err != nil {
+			return err
+		}
+	}
+
+	log.Println("Backfilled thread paths on", backfilled, "posts and tombstones")
+
+	return nil
+}
+
+// backfillTagMetrics rebuilds the hashtag metrics from the posts. It replaces
+// the documents it computes, so running it again gives the same result.
+func backfillTagMetrics(ctx context.Context, db *mongo.Database) error {
+	indexes := map[string]mongo.IndexModel{
+		TWTMETRICS_COLLECTION: {Keys: bson.D{{Key: TAG_FIELD, Value: 1}, {Key: DAY, Value: 1}}, Options: options.Index().SetUnique(true)},
+		TAGMETRICS_COLLECTION: {Keys: bson.D{{Key: TAG_FIELD, Value: 1}}, Options: options.Index().SetUnique(true)},
+	}
+
+	for collection, index := range indexes {
+		if _, err := db.Collection(collection).Indexes().CreateOne(ctx, index); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now().UTC()
+
+	// Every original post counts each of its tags once.
+	postTags := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{IS_RETWEET_FIELD: false, TAGS_FIELD: bson.M{"$type": "array"}}}},
+		{{Key: "$project", Value: bson.M{TIME_FIELD: 1, TAGS_FIELD: bson.M{"$setUnion": bson.A{"$" + TAGS_FIELD, bson.A{}}}}}},
+		{{Key: "$unwind", Value: "$" + TAGS_FIELD}},
+	}
+
+	usage := append(slices.Clone(postTags),
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				TAG_FIELD: "$" + TAGS_FIELD,
+				DAY:       bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$" + TIME_FIELD}},
+				"hour":    bson.M{"$dateToString": bson.M{"format": "%H", "date": "$" + TIME_FIELD}},
+			},
+			"count": bson.M{"$sum": 1},
+		}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{TAG_FIELD: "$_id." + TAG_FIELD, DAY: "$_id." + DAY},
+			"hours": bson.M{"$push": bson.M{"k": "$_id.hour", "v": "$count"}},
+			"total": bson.M{"$sum": "$count"},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":            0,
+			TAG_FIELD:        "$_id." + TAG_FIELD,
+			DAY:              "$_id." + DAY,
+			HOURLY_FRECUENCY: bson.M{"$arrayToObject": "$hours"},
+			TOTAL_TWEETS:     "$total",
+		}}},
+		bson.D{{Key: "$merge", Value: bson.M{"into": TWTMETRICS_COLLECTION, "on": bson.A{TAG_FIELD, DAY}, "whenMatched": "replace", "whenNotMatched": "insert"}}},
+	)
+
+	ageHours := bson.M{"$max": bson.A{0, bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{now, "$" + TIME_FIELD}}, float64(time.Hour.Milliseconds())}}}}
+
+	summaries := append(slices.Clone(postTags),
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":   "$" + TAGS_FIELD,
+			"total": bson.M{"$sum": 1},
+			"trend": bson.M{"$sum": bson.M{"$exp": bson.M{"$multiply": bson.A{-TRENDING_DECAY, ageHours}}}},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":        0,
+			TAG_FIELD:    "$_id",
+			TOTAL_TWEETS: "$total",
+			TREND:        "$trend",
+			LAST_UPDATED: now,
+		}}},
+		bson.D{{Key: "$merge", Value: bson.M{"into": TAGMETRICS_COLLECTION, "on": TAG_FIELD, "whenMatched": "replace", "whenNotMatched": "insert"}}},
+	)
+
+	for _, pipeline := range []mongo.Pipeline{usage, summaries} {
+		cursor, err := db.Collection(FEED_COLLECTION).Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+		if err := cursor.Close(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addSchemaValidators(ctx context.Context, db *mongo.Database) error {
+	stringType := bson.M{"bsonType": "string"}
+	counterType := bson.M{"bsonType": bson.A{"int", "long"}}
+	listType := bson.M{"bsonType": bson.A{"array", "null"}, "items": stringType}
+
+	schemas := map[string]bson.M{
+		FEED_COLLECTION: {
+			"bsonType": "object",
+			"required": bson.A{POST_ID_FIELD, AUTHOR_ID_FIELD, CONTENT_FIELD, TIME_FIELD, ORIGINAL_POST_ID_FIELD},
+			"properties": bson.M{
+				POST_ID_FIELD:          stringType,
+				AUTHOR_ID_FIELD:        stringType,
+				CONTENT_FIELD:          stringType,
+				TIME_FIELD:             bson.M{"bsonType": "date"},
+				PUBLIC_FIELD:           bson.M{"bsonType": "bool"},
+				TAGS_FIELD:             listType,
+				MENTIONS_FIELD:         listType,
+				LIKES_FIELD:            counterType,
+				RETWEET_FIELD:          counterType,
+				IS_RETWEET_FIELD:       bson.M{"bsonType": "bool"},
+				ORIGINAL_POST_ID_FIELD: stringType,
+				RETWEET_AUTHOR_FIELD:   stringType,
+				MEDIA_INFO_FIELD:       bson.M{"bsonType": "object"},
+				BLOCKED_FIELD:          bson.M{"bsonType": "bool"},
+			},
+		},
+		LIKES_COLLECTION: {
+			"bsonType":   "object",
+			"required":   bson.A{ORIGINAL_POST_ID_FIELD},
+			"properties": bson.M{ORIGINAL_POST_ID_FIELD: stringType, LIKERS_FIELD: listType},
+		},
+		RETWEET_COLLECTION: {
+			"bsonType":   "object",
+			"required":   bson.A{ORIGINAL_POST_ID_FIELD},
+			"properties": bson.M{ORIGINAL_POST_ID_FIELD: stringType, RETWEETERS_FIELD: listType},
+		},
+		BOOKMARK_COLLECTION: {
+			"bsonType":   "object",
+			"required":   bson.A{AUTHOR_ID_FIELD},
+			"properties": bson.M{AUTHOR_ID_FIELD: stringType, POST_ID_FIELD: listType},
+		},
+	}
+
+	for collection, schema := range schemas {
+		if err := setValidator(ctx, db, collection, bson.M{"$jsonSchema": schema}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setValidator creates the collection with the validator, or replaces the
+// validator of a collection that already exists. The moderate level leaves
+// old documents that do not match alone until they are fixed.
+func setValidator(ctx context.Context, db *mongo.Database, collection string, validator bson.M) error {
+	err := db.CreateCollection(ctx, collection, options.CreateCollection().
+		SetValidator(validator).
+		SetValidationLevel("moderate"))
+
+	var serverError mongo.ServerError
+
+	if errors.As(err, &serverError) && serverError.HasErrorCode(NAMESPACE_EXISTS_CODE) {
+		return db.RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: collection},
+			{Key: "validator", Value: validator},
+			{Key: "validationLevel", Value: "moderate"},
+		}).Err()
+	}
+
+	return err
+}
+
+func backfillBlocked(ctx context.Context, db *mongo.Database) error {
+	filter := bson.M{BLOCKED_FIELD: bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{BLOCKED_FIELD: false}}
+
+	result, err := db.Collection(FEED_COLLECTION).UpdateMany(ctx, filter, update)
+
+	if err != nil {
+		return err
+	}
+
+	log.Println("Backfilled blocked field on", result.ModifiedCount, "posts")
+
+	return nil
+}
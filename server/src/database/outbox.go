@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (d *AppDatabase) AddOutboxMessages(ctx context.Context, messages []models.OutboxMessage) error {
+	outboxCollection := d.db.Collection(OUTBOX_COLLECTION)
+
+	documents := make([]interface{}, len(messages))
+	for i, message := range messages {
+		documents[i] = message
+	}
+
+	_, err := outboxCollection.InsertMany(ctx, documents)
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	return err
+}
+
+// outboxStep writes messages to the outbox as the last step of a write, so
+// they are stored if and only if the change they come from is.
+func (d *AppDatabase) outboxStep(messages []models.OutboxMessage) writeStep {
+	outboxCollection := d.db.Collection(OUTBOX_COLLECTION)
+
+	return writeStep{
+		name: STEP_OUTBOX,
+		apply: func(ctx context.Context) error {
+			documents := make([]interface{}, len(messages))
+			for i, message := range messages {
+				documents[i] = message
+			}
+			_, err := outboxCollection.InsertMany(ctx, documents)
+			return err
+		},
+	}
+}
+
+// eventStep writes the event of a change to the outbox within its write.
+func (d *AppDatabase) eventStep(event models.QueueMessage) writeStep {
+	return d.outboxStep([]models.OutboxMessage{models.NewEventOutboxMessage(event)})
+}
+
+func (d *AppDatabase) ClaimOutboxMessages(ctx context.Context, owner string, dueBy time.Time, limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	outboxCollection := d.db.Collection(OUTBOX_COLLECTION)
+
+	now := time.Now().UTC()
+	filter := bson.M{STATUS_FIELD: models.OUTBOX_PENDING, NEXT_ATTEMPT_FIELD: bson.M{"$lte": dueBy}}
+	update := bson.M{"$set": bson.M{NEXT_ATTEMPT_FIELD: now.Add(lease), CLAIMED_BY_FIELD: owner}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: NEXT_ATTEMPT_FIELD, Value: 1}}).
+		SetReturnDocument(options.After)
+
+	messages := []models.OutboxMessage{}
+
+	// Each message is claimed on its own, so two dispatchers never get the
+	// same one.
+	for len(messages) < limit {
+		var message models.OutboxMessage
+
+		err := outboxCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&message)
+
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+func (d *AppDatabase) UpdateOutboxMessage(ctx context.Context, message models.OutboxMessage) error {
+	outboxCollection := d.db.Collection(OUTBOX_COLLECTION)
+
+	// The lease holds while the message is still claimed by the same
+	// dispatcher and its next attempt, the end of the lease, is ahead.
+	filter := bson.M{
+		MESSAGE_ID_FIELD:   message.Message_ID,
+		CLAIMED_BY_FIELD:   message.Claimed_By,
+		NEXT_ATTEMPT_FIELD: bson.M{"$gt": time.Now().UTC()},
+	}
+
+	message.Claimed_By = ""
+
+	result, err := outboxCollection.ReplaceOne(ctx, filter, message)
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return postErrors.ErrOutboxLeaseLost
+	}
+
+	return nil
+}
+
+func (d *AppDatabase) DeleteOutboxMessage(ctx context.Context, messageID string) error {
+	outboxCollection := d.db.Collection(OUTBOX_COLLECTION)
+
+	_, err := outboxCollection.DeleteOne(ctx, bson.M{MESSAGE_ID_FIELD: messageID})
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	return err
+}
+
+func (d *AppDatabase) GetOutboxMessages(ctx context.Context, status string, limit int) ([]models.OutboxMessage, error) {
+	outboxCollection := d.db.Collection(OUTBOX_COLLECTION)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: CREATED_AT_FIELD, Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := outboxCollection.Find(ctx, bson.M{STATUS_FIELD: status}, opts)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	messages := []models.OutboxMessage{}
+
+	if err := cursor.All(ctx, &messages); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (d *AppDatabase) CountOutboxMessages(ctx context.Context, status string) (int, error) {
+	outboxCollection := d.db.Collection(OUTBOX_COLLECTION)
+
+	count, err := outboxCollection.CountDocuments(ctx, bson.M{STATUS_FIELD: status})
+
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+
+	return int(count), nil
+}
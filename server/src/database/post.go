@@ -2,98 +2,330 @@ package database
 
 import (
 	"context"
+	"errors"
 	"log"
 	postErrors "server/src/all_errors"
 	"server/src/models"
-	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func (d *AppDatabase) AddNewPost(newPost models.DBPost) (models.FrontPost, error) {
+func (d *AppDatabase) AddNewPost(ctx context.Context, newPost models.DBPost) (models.FrontPost, error) {
+	return d.AddNewPostWithOutbox(ctx, newPost, nil)
+}
+
+func (d *AppDatabase) AddNewPostWithOutbox(ctx context.Context, newPost models.DBPost, messages []models.OutboxMessage) (models.FrontPost, error) {
 	postCollection := d.db.Collection(FEED_COLLECTION)
-	_, err := postCollection.InsertOne(context.Background(), newPost)
+
+	threadPath, err := d.threadPath(ctx, newPost)
+
+	if err != nil {
+		log.Println(err)
+		return models.FrontPost{}, err
+	}
+
+	newPost.Thread_Path = threadPath
+
+	steps := []writeStep{
+		{
+			name: STEP_POST,
+			apply: func(ctx context.Context) error {
+				_, err := postCollection.InsertOne(ctx, newPost)
+				return err
+			},
+			undo: func(ctx context.Context) error {
+				_, err := postCollection.DeleteOne(ctx, bson.M{POST_ID_FIELD: newPost.Post_ID})
+				return err
+			},
+		},
+	}
+
+	if changes := postTagChanges(newPost, 1); len(changes) > 0 {
+		steps = append(steps, writeStep{
+			name: STEP_TAG_METRICS,
+			apply: func(ctx context.Context) error {
+				return d.updateTagMetrics(ctx, newPost.Time, changes)
+			},
+			undo: func(ctx context.Context) error {
+				return d.updateTagMetrics(ctx, newPost.Time, changes.inverse())
+			},
+		})
+	}
+
+	if parentID := newPost.In_Reply_To_Post_ID; parentID != "" {
+		steps = append(steps, writeStep{
+			name: STEP_REPLIES_COUNTER,
+			apply: func(ctx context.Context) error {
+				return d.incrementCounter(ctx, parentID, REPLIES_FIELD, 1)
+			},
+			undo: func(ctx context.Context) error {
+				return d.incrementCounter(ctx, parentID, REPLIES_FIELD, -1)
+			},
+		})
+	}
+
+	if quotedID := newPost.Quoted_Post_ID; quotedID != "" {
+		steps = append(steps, writeStep{
+			name: STEP_QUOTES_COUNTER,
+			apply: func(ctx context.Context) error {
+				return d.incrementCounter(ctx, quotedID, QUOTES_FIELD, 1)
+			},
+			undo: func(ctx context.Context) error {
+				return d.incrementCounter(ctx, quotedID, QUOTES_FIELD, -1)
+			},
+		})
+	}
+
+	if len(messages) > 0 {
+		steps = append(steps, d.outboxStep(messages))
+	}
+
+	// A post without tags, parent, quote or messages is a single insert,
+	// which needs no transaction.
+	if len(steps) == 1 {
+		err = d.hooks.runCompensated(ctx, "AddNewPostWithOutbox", steps)
+	} else {
+		err = d.runWrite(ctx, "AddNewPostWithOutbox", steps)
+	}
 
 	if err != nil {
 		log.Println(err)
 		return models.FrontPost{}, err
 	}
 
-	frontPost, err_2 := d.makeDBPostIntoFrontPost(newPost, newPost.Author_ID)
+	frontPost, err_2 := d.makeDBPostIntoFrontPost(ctx, newPost, newPost.Author_ID)
 
 	return frontPost, err_2
 }
 
-func (d *AppDatabase) GetPost(postID string, askerID string) (models.FrontPost, error) {
+func (d *AppDatabase) GetPost(ctx context.Context, postID string, askerID string) (models.FrontPost, error) {
 	postCollection := d.db.Collection(FEED_COLLECTION)
-	post, err := d.findPost(postID, postCollection)
+	post, err := d.findPost(ctx, postID, postCollection)
 
 	if err != nil {
 		return models.FrontPost{}, err
 	}
 
-	frontPost, err := d.makeDBPostIntoFrontPost(post, askerID)
+	frontPost, err := d.makeDBPostIntoFrontPost(ctx, post, askerID)
 
 	return frontPost, err
 }
 
-func (d *AppDatabase) DeletePost(postID string) error {
+func (d *AppDatabase) DeletePost(ctx context.Context, postID string) error {
 	postCollection := d.db.Collection(FEED_COLLECTION)
 	likesCollection := d.db.Collection(LIKES_COLLECTION)
 	retweetCollection := d.db.Collection(RETWEET_COLLECTION)
+	tombstoneCollection := d.db.Collection(TOMBSTONE_COLLECTION)
 
 	filter := bson.M{POST_ID_FIELD: postID}
-
-	result, err := postCollection.DeleteOne(context.Background(), filter)
-
-	if err != nil {
-		return err
-	}
-
-	if result.DeletedCount == 0 {
-		return postErrors.ErrTwitsnapNotFound
-	}
-
 	filter_retweet := bson.M{ORIGINAL_POST_ID_FIELD: postID}
-	_, err = postCollection.DeleteMany(context.Background(), filter_retweet)
 
-	if err != nil {
-		return err
+	// The deleted documents are kept so they can be put back when the write
+	// has to be undone.
+	var deletedPost bson.M
+	var deletedRetweets []interface{}
+	var deletedLikes bson.M
+	var deletedRetweeters bson.M
+
+	steps := []writeStep{
+		{
+			name: STEP_POST,
+			apply: func(ctx context.Context) error {
+				deletedPost = nil
+				err := postCollection.FindOneAndDelete(ctx, filter).Decode(&deletedPost)
+
+				if errors.Is(err, mongo.ErrNoDocuments) {
+					return postErrors.ErrTwitsnapNotFound
+				}
+				return err
+			},
+			undo: func(ctx context.Context) error {
+				_, err := postCollection.InsertOne(ctx, deletedPost)
+				return err
+			},
+		},
+		{
+			name: STEP_TAG_METRICS,
+			apply: func(ctx context.Context) error {
+				post, err := decodePost(deletedPost)
+				if err != nil {
+					return err
+				}
+				return d.updateTagMetrics(ctx, post.Time, postTagChanges(post, -1))
+			},
+			undo: func(ctx context.Context) error {
+				post, err := decodePost(deletedPost)
+				if err != nil {
+					return err
+				}
+				return d.updateTagMetrics(ctx, post.Time, postTagChanges(post, 1))
+			},
+		},
+		{
+			name: STEP_RETWEET_POSTS,
+			apply: func(ctx context.Context) error {
+				cursor, err := postCollection.Find(ctx, filter_retweet)
+				if err != nil {
+					return err
+				}
+
+				var retweets []bson.M
+				if err := cursor.All(ctx, &retweets); err != nil {
+					return err
+				}
+
+				deletedRetweets = nil
+				for _, retweet := range retweets {
+					deletedRetweets = append(deletedRetweets, retweet)
+				}
+
+				_, err = postCollection.DeleteMany(ctx, filter_retweet)
+				return err
+			},
+			undo: func(ctx context.Context) error {
+				if len(deletedRetweets) == 0 {
+					return nil
+				}
+				_, err := postCollection.InsertMany(ctx, deletedRetweets)
+				return err
+			},
+		},
+		{
+			name: STEP_LIKERS,
+			apply: func(ctx context.Context) error {
+				deletedLikes = nil
+				err := likesCollection.FindOneAndDelete(ctx, filter_retweet).Decode(&deletedLikes)
+
+				if errors.Is(err, mongo.ErrNoDocuments) {
+					return nil
+				}
+				return err
+			},
+			undo: func(ctx context.Context) error {
+				if deletedLikes == nil {
+					return nil
+				}
+				_, err := likesCollection.InsertOne(ctx, deletedLikes)
+				return err
+			},
+		},
+		{
+			name: STEP_RETWEETERS,
+			apply: func(ctx context.Context) error {
+				deletedRetweeters = nil
+				err := retweetCollection.FindOneAndDelete(ctx, filter_retweet).Decode(&deletedRetweeters)
+
+				if errors.Is(err, mongo.ErrNoDocuments) {
+					return nil
+				}
+				return err
+			},
+			undo: func(ctx context.Context) error {
+				if deletedRetweeters == nil {
+					return nil
+				}
+				_, err := retweetCollection.InsertOne(ctx, deletedRetweeters)
+				return err
+			},
+		},
+		{
+			// A post with replies leaves a tombstone in its conversation. One
+			// without them is no longer a reply of its parent.
+			name: STEP_TOMBSTONE,
+			apply: func(ctx context.Context) error {
+				post, err := decodePost(deletedPost)
+				if err != nil || post.Is_Retweet {
+					return err
+				}
+
+				if post.Replies > 0 {
+					_, err = tombstoneCollection.InsertOne(ctx, models.NewTombstone(post))
+					return err
+				}
+
+				if post.In_Reply_To_Post_ID != "" {
+					return d.incrementCounter(ctx, post.In_Reply_To_Post_ID, REPLIES_FIELD, -1)
+				}
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				post, err := decodePost(deletedPost)
+				if err != nil || post.Is_Retweet {
+					return err
+				}
+
+				if post.Replies > 0 {
+					_, err = tombstoneCollection.DeleteOne(ctx, filter)
+					return err
+				}
+
+				if post.In_Reply_To_Post_ID != "" {
+					return d.incrementCounter(ctx, post.In_Reply_To_Post_ID, REPLIES_FIELD, 1)
+				}
+				return nil
+			},
+		},
+		{
+			name: STEP_QUOTES_COUNTER,
+			apply: func(ctx context.Context) error {
+				post, err := decodePost(deletedPost)
+				if err != nil || post.Is_Retweet || post.Quoted_Post_ID == "" {
+					return err
+				}
+				return d.incrementCounter(ctx, post.Quoted_Post_ID, QUOTES_FIELD, -1)
+			},
+			undo: func(ctx context.Context) error {
+				post, err := decodePost(deletedPost)
+				if err != nil || post.Is_Retweet || post.Quoted_Post_ID == "" {
+					return err
+				}
+				return d.incrementCounter(ctx, post.Quoted_Post_ID, QUOTES_FIELD, 1)
+			},
+		},
+		d.eventStep(models.NewContentChangeEvent(models.DELETED_CONTENT, postID)),
 	}
 
-	_, err = likesCollection.DeleteOne(context.Background(), filter)
+	return d.runWrite(ctx, "DeletePost", steps)
+}
 
-	if err != nil {
-		return err
-	}
+// incrementCounter adds delta to the counter field of the post and its
+// retweets.
+func (d *AppDatabase) incrementCounter(ctx context.Context, originalPostID string, field string, delta int) error {
+	filter := bson.M{ORIGINAL_POST_ID_FIELD: originalPostID}
 
-	_, err = retweetCollection.DeleteOne(context.Background(), filter)
+	_, err := d.db.Collection(FEED_COLLECTION).UpdateMany(ctx, filter, bson.M{"$inc": bson.M{field: delta}})
 
 	return err
 }
 
-func (d *AppDatabase) GetAllPosts(limitConfig models.LimitConfig, askerID string) ([]models.FrontPost, bool, error) {
-	postCollection := d.db.Collection(FEED_COLLECTION)
+// decodePost reads a post kept as a raw document.
+func decodePost(document bson.M) (models.DBPost, error) {
+	var post models.DBPost
 
-	parsedTime, err := time.Parse(time.RFC3339, limitConfig.FromTime)
+	data, err := bson.Marshal(document)
 
-	if err != nil {
-		log.Println(err)
+	if err == nil {
+		err = bson.Unmarshal(data, &post)
 	}
 
-	filter := bson.M{TIME_FIELD: bson.M{"$lt": parsedTime.UTC()}}
+	return post, err
+}
+
+func (d *AppDatabase) GetAllPosts(ctx context.Context, limitConfig models.LimitConfig, askerID string) ([]models.FrontPost, bool, error) {
+	postCollection := d.db.Collection(FEED_COLLECTION)
+
+	filter := bson.M{}
 
-	cursor, err := postCollection.Find(context.Background(), filter, options.Find().
-		SetSort(bson.M{TIME_FIELD: -1}).SetSkip(int64(limitConfig.Skip)).SetLimit(int64(limitConfig.Limit)+1))
+	cursor, err := postCollection.Find(ctx, pageFilter(filter, limitConfig), pageOptions(limitConfig))
 
 	if err != nil {
 		log.Println(err)
 		return []models.FrontPost{}, false, postErrors.DatabaseError(err.Error())
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
-	posts, err := d.createPostList(cursor, askerID)
+	posts, err := d.createPostList(ctx, cursor, askerID)
 
 	if err != nil {
 		log.Println(err)
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"server/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// findQuotedPosts returns the posts quoted by posts by ID, blocked or not.
+func (d *AppDatabase) findQuotedPosts(ctx context.Context, posts []models.DBPost) (map[string]models.DBPost, error) {
+	quoted := map[string]models.DBPost{}
+	quotedIDs := []string{}
+
+	for _, post := range posts {
+		if post.Quoted_Post_ID != "" {
+			quotedIDs = append(quotedIDs, post.Quoted_Post_ID)
+		}
+	}
+
+	if len(quotedIDs) == 0 {
+		return quoted, nil
+	}
+
+	cursor, err := d.db.Collection(FEED_COLLECTION).Find(ctx, bson.M{POST_ID_FIELD: bson.M{"$in": quotedIDs}})
+	if err != nil {
+		return nil, err
+	}
+
+	var found []models.DBPost
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	for _, post := range found {
+		quoted[post.Post_ID] = post
+	}
+
+	return quoted, nil
+}
+
+// withQuotedPost embeds in post the one it quotes, taken from quoted. A
+// quoted post that was deleted or blocked shows as a tombstone.
+func withQuotedPost(post models.FrontPost, quoted map[string]models.DBPost) models.FrontPost {
+	if post.Quoted_Post_ID == "" {
+		return post
+	}
+
+	original, found := quoted[post.Quoted_Post_ID]
+
+	var embedded models.FrontPost
+
+	switch {
+	case !found:
+		embedded = models.TombstoneOf(models.FrontPost{Post_ID: post.Quoted_Post_ID})
+	case original.Blocked:
+		embedded = models.NewTombstoneFrontPost(original)
+	default:
+		original = normalizePostLists(original)
+		embedded = models.NewFrontPost(original, defaultAuthorInfo(original.Author_ID), false, false, false)
+	}
+
+	post.Quoted_Post = &embedded
+
+	return post
+}
@@ -5,69 +5,165 @@ import (
 	"log"
 	postErrors "server/src/all_errors"
 	"server/src/models"
-	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func (d *AppDatabase) AddNewRetweet(newRetweet models.DBPost) (models.FrontPost, error) {
+func (d *AppDatabase) AddNewRetweet(ctx context.Context, newRetweet models.DBPost) (models.FrontPost, error) {
 	postCollection := d.db.Collection(FEED_COLLECTION)
-	_, err := postCollection.InsertOne(context.Background(), newRetweet)
-
-	if err != nil {
-		log.Println(err)
-		return models.FrontPost{}, err
-	}
-
 	retweetCollection := d.db.Collection(RETWEET_COLLECTION)
 
 	filter_original := bson.M{ORIGINAL_POST_ID_FIELD: newRetweet.Original_Post_ID}
-	update := bson.M{"$inc": bson.M{RETWEET_FIELD: 1}}
-
-	retweeter := bson.M{"$addToSet": bson.M{RETWEETERS_FIELD: newRetweet.Retweet_Author_ID}}
 
-	_, err = postCollection.UpdateMany(context.Background(), filter_original, update)
-	if err != nil {
-		log.Println(err)
-		return models.FrontPost{}, err
+	steps := []writeStep{
+		{
+			name: STEP_RETWEETERS,
+			apply: func(ctx context.Context) error {
+				retweeter := bson.M{"$addToSet": bson.M{RETWEETERS_FIELD: newRetweet.Retweet_Author_ID}}
+				result, err := retweetCollection.UpdateOne(ctx, filter_original, retweeter, options.Update().SetUpsert(true))
+				if err != nil {
+					return err
+				}
+
+				// The retweeters set decides whether the retweet counts, so
+				// retweeting twice can not increment the counter twice.
+				if result.ModifiedCount == 0 && result.UpsertedCount == 0 {
+					return postErrors.ErrAlreadyRetweeted
+				}
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				_, err := retweetCollection.UpdateOne(ctx, filter_original, bson.M{"$pull": bson.M{RETWEETERS_FIELD: newRetweet.Retweet_Author_ID}})
+				return err
+			},
+		},
+		{
+			name: STEP_POST,
+			apply: func(ctx context.Context) error {
+				_, err := postCollection.InsertOne(ctx, newRetweet)
+				return err
+			},
+			undo: func(ctx context.Context) error {
+				_, err := postCollection.DeleteOne(ctx, bson.M{POST_ID_FIELD: newRetweet.Post_ID})
+				return err
+			},
+		},
+		{
+			name: STEP_RETWEETS_COUNTER,
+			apply: func(ctx context.Context) error {
+				_, err := postCollection.UpdateMany(ctx, filter_original, bson.M{"$inc": bson.M{RETWEET_FIELD: 1}})
+				return err
+			},
+			undo: func(ctx context.Context) error {
+				_, err := postCollection.UpdateMany(ctx, filter_original, bson.M{"$inc": bson.M{RETWEET_FIELD: -1}})
+				return err
+			},
+		},
+		d.eventStep(models.NewEngagementEvent(models.NEW_RETWEET, newRetweet.Original_Post_ID, newRetweet.Retweet_Author_ID)),
 	}
 
-	_, err = retweetCollection.UpdateOne(context.Background(), filter_original, retweeter, options.Update().SetUpsert(true))
+	err := d.runWrite(ctx, "AddNewRetweet", steps)
+
 	if err != nil {
 		log.Println(err)
 		return models.FrontPost{}, err
 	}
 
-	newPostRetweet, err := d.findPost(newRetweet.Post_ID, postCollection)
+	newPostRetweet, err := d.findPost(ctx, newRetweet.Post_ID, postCollection)
 
 	if err != nil {
 		log.Println(err)
 		return models.FrontPost{}, err
 	}
 
-	post, err := d.makeDBPostIntoFrontPost(newPostRetweet, newPostRetweet.Retweet_Author_ID)
+	post, err := d.makeDBPostIntoFrontPost(ctx, newPostRetweet, newPostRetweet.Retweet_Author_ID)
 
 	return post, err
 }
 
-func (d *AppDatabase) DeleteRetweet(postID string, userID string) error {
+func (d *AppDatabase) DeleteRetweet(ctx context.Context, postID string, userID string) error {
 
 	postCollection := d.db.Collection(FEED_COLLECTION)
 	retweetCollection := d.db.Collection(RETWEET_COLLECTION)
 
 	filter := bson.M{ORIGINAL_POST_ID_FIELD: postID}
-	update := bson.M{"$inc": bson.M{RETWEET_FIELD: -1}}
-
-	retweeter := bson.M{"$pull": bson.M{RETWEETERS_FIELD: userID}}
-
-	_, err := postCollection.UpdateMany(context.Background(), filter, update)
-	if err != nil {
-		log.Println(err)
-		return err
+	filter_retweet := bson.M{ORIGINAL_POST_ID_FIELD: postID, IS_RETWEET_FIELD: true, RETWEET_AUTHOR_FIELD: userID}
+	retweeted := true
+	var deletedRetweets []interface{}
+
+	steps := []writeStep{
+		{
+			name: STEP_RETWEETERS,
+			apply: func(ctx context.Context) error {
+				result, err := retweetCollection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{RETWEETERS_FIELD: userID}})
+				if err != nil {
+					return err
+				}
+
+				retweeted = result.ModifiedCount > 0
+				return nil
+			},
+			undo: func(ctx context.Context) error {
+				if !retweeted {
+					return nil
+				}
+				_, err := retweetCollection.UpdateOne(ctx, filter, bson.M{"$addToSet": bson.M{RETWEETERS_FIELD: userID}})
+				return err
+			},
+		},
+		{
+			name: STEP_RETWEETS_COUNTER,
+			apply: func(ctx context.Context) error {
+				// Only retweets that were in the set are taken off the counter.
+				if !retweeted {
+					return nil
+				}
+				_, err := postCollection.UpdateMany(ctx, filter, bson.M{"$inc": bson.M{RETWEET_FIELD: -1}})
+				return err
+			},
+			undo: func(ctx context.Context) error {
+				if !retweeted {
+					return nil
+				}
+				_, err := postCollection.UpdateMany(ctx, filter, bson.M{"$inc": bson.M{RETWEET_FIELD: 1}})
+				return err
+			},
+		},
+		{
+			// The retweet itself leaves the feeds along with the retweeter.
+			name: STEP_RETWEET_POSTS,
+			apply: func(ctx context.Context) error {
+				cursor, err := postCollection.Find(ctx, filter_retweet)
+				if err != nil {
+					return err
+				}
+
+				var retweets []bson.M
+				if err := cursor.All(ctx, &retweets); err != nil {
+					return err
+				}
+
+				deletedRetweets = nil
+				for _, retweet := range retweets {
+					deletedRetweets = append(deletedRetweets, retweet)
+				}
+
+				_, err = postCollection.DeleteMany(ctx, filter_retweet)
+				return err
+			},
+			undo: func(ctx context.Context) error {
+				if len(deletedRetweets) == 0 {
+					return nil
+				}
+				_, err := postCollection.InsertMany(ctx, deletedRetweets)
+				return err
+			},
+		},
+		when(&retweeted, d.eventStep(models.NewEngagementEvent(models.REMOVED_RETWEET, postID, userID))),
 	}
 
-	_, err = retweetCollection.UpdateOne(context.Background(), filter, retweeter)
+	err := d.runWrite(ctx, "DeleteRetweet", steps)
 
 	if err != nil {
 		log.Println(err)
@@ -76,17 +172,9 @@ func (d *AppDatabase) DeleteRetweet(postID string, userID string) error {
 	return err
 }
 
-func (d *AppDatabase) GetUserFeedRetweet(userId string, limitConfig models.LimitConfig, askerID string, following []string) ([]models.FrontPost, bool, error) {
+func (d *AppDatabase) GetUserFeedRetweet(ctx context.Context, userId string, limitConfig models.LimitConfig, askerID string, following []string) ([]models.FrontPost, bool, error) {
 	postCollection := d.db.Collection(FEED_COLLECTION)
-	parsedTime, err := time.Parse(time.RFC3339, limitConfig.FromTime)
-
-	if err != nil {
-		log.Println(err)
-		log.Println("User does not follow")
-	}
-
 	filter := bson.M{
-		TIME_FIELD:       bson.M{"$lt": parsedTime.UTC()},
 		IS_RETWEET_FIELD: true,
 		"$and": []bson.M{
 			{"$or": []bson.M{
@@ -101,14 +189,14 @@ func (d *AppDatabase) GetUserFeedRetweet(userId string, limitConfig models.Limit
 		},
 	}
 
-	cursor, err := postCollection.Find(context.Background(), filter, options.Find().
-		SetSort(bson.M{TIME_FIELD: -1}).SetSkip(int64(limitConfig.Skip)).SetLimit(int64(limitConfig.Limit)+1))
+	cursor, err := postCollection.Find(ctx, pageFilter(filter, limitConfig), pageOptions(limitConfig))
 	if err != nil {
 		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
-	posts, err := d.createPostList(cursor, askerID)
+	posts, err := d.createPostList(ctx, cursor, askerID)
 
 	if err != nil {
 		log.Println(err)
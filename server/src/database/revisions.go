@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"log"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"slices"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (d *AppDatabase) GetRevisions(ctx context.Context, postID string, limitConfig models.LimitConfig) ([]models.Revision, bool, error) {
+	if _, err := d.findPost(ctx, postID, d.db.Collection(FEED_COLLECTION)); err != nil {
+		return nil, false, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: NUMBER_FIELD, Value: -1}}).
+		SetSkip(int64(max(limitConfig.Skip, 0))).
+		SetLimit(int64(limitConfig.Limit) + 1)
+
+	cursor, err := d.db.Collection(REVISION_COLLECTION).Find(ctx, bson.M{POST_ID_FIELD: postID}, findOptions)
+	if err != nil {
+		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
+	}
+
+	revisions := []models.Revision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
+	}
+
+	hasMore := len(revisions) > limitConfig.Limit
+
+	if hasMore {
+		revisions = revisions[:limitConfig.Limit]
+	}
+
+	return revisions, hasMore, nil
+}
+
+func (d *AppDatabase) RevertPost(ctx context.Context, postID string, revision int, askerID string) (models.FrontPost, error) {
+	post, err := d.findPost(ctx, postID, d.db.Collection(FEED_COLLECTION))
+
+	if err != nil {
+		return models.FrontPost{}, err
+	}
+
+	if revision < 0 || revision > post.Edits {
+		return models.FrontPost{}, postErrors.ErrRevisionNotFound
+	}
+
+	filter := bson.M{POST_ID_FIELD: postID, NUMBER_FIELD: bson.M{"$gt": revision}}
+
+	cursor, err := d.db.Collection(REVISION_COLLECTION).Find(ctx, filter)
+	if err != nil {
+		return models.FrontPost{}, err
+	}
+
+	var newer []models.Revision
+	if err := cursor.All(ctx, &newer); err != nil {
+		return models.FrontPost{}, err
+	}
+
+	return d.saveRevision(ctx, post, revertedPost(post, newer), askerID, &revision)
+}
+
+// editedPost applies editInfo to post. The fields left out keep their value,
+// except for the mentions, which an edit always replaces.
+func editedPost(post models.DBPost, editInfo models.EditPostExpectedFormat) models.DBPost {
+	mentions := append([]string{}, editInfo.Mentions...)
+
+	return withVersion(post, models.PostVersion{
+		Content:    editInfo.Content,
+		Public:     editInfo.Public,
+		Media_Info: editInfo.MediaInfo,
+		Mentions:   &mentions,
+	})
+}
+
+// withVersion sets on post the fields version has, keeping the tags in step
+// with the content.
+func withVersion(post models.DBPost, version models.PostVersion) models.DBPost {
+	if version.Content != nil {
+		post.Content = *version.Content
+		post.Tags = contentTags(post.Content)
+		post.Tag_Keys = models.TagKeys(post.Tags)
+	}
+
+	if version.Public != nil {
+		post.Public = *version.Public
+	}
+
+	if version.Media_Info != nil {
+		post.Media_Info = *version.Media_Info
+	}
+
+	if version.Mentions != nil {
+		post.Mentions = slices.Clone(*version.Mentions)
+	}
+
+	return post
+}
+
+// revertedPost undoes on post the revisions made after the one it goes back
+// to, newest first.
+func revertedPost(post models.DBPost, newer []models.Revision) models.DBPost {
+	newer = slices.Clone(newer)
+
+	sort.Slice(newer, func(i, j int) bool {
+		return newer[i].Number > newer[j].Number
+	})
+
+	for _, revision := range newer {
+		post = withVersion(post, revision.Before)
+	}
+
+	return post
+}
@@ -3,91 +3,108 @@ package database
 import (
 	"context"
 	"log"
+	"regexp"
 	postErrors "server/src/all_errors"
 	"server/src/models"
+	"slices"
 	"strings"
-	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func (d *AppDatabase) GetUserHashtags(interests []string, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
-	postCollection := d.db.Collection(FEED_COLLECTION)
+// textSearch is the $search string of the text index for query. Terms are
+// plain words and phrases have no quotes, so user input cannot negate words
+// or change the search.
+func textSearch(query models.SearchQuery) string {
+	parts := slices.Clone(query.Terms)
 
-	if len(interests) == 0 {
-		return []models.FrontPost{}, false, nil
+	for _, phrase := range query.Phrases {
+		parts = append(parts, "\""+strings.ReplaceAll(phrase, "\"", " ")+"\"")
 	}
 
-	parsedTime, err := time.Parse(time.RFC3339, limitConfig.FromTime)
+	return strings.Join(parts, " ")
+}
 
-	if err != nil {
-		log.Println(err)
-	}
+// wordPattern matches word as a whole word, the way the text index splits
+// the content.
+func wordPattern(word string) primitive.Regex {
+	return primitive.Regex{Pattern: `(^|[^\p{L}\p{N}])` + regexp.QuoteMeta(word) + `([^\p{L}\p{N}]|$)`, Options: "i"}
+}
 
-	filter := bson.M{TAGS_FIELD: bson.M{"$all": interests}, TIME_FIELD: bson.M{"$lt": parsedTime.UTC()}, "$or": []bson.M{
+// searchFilter is every filter of query but the words, which go through the
+// text index.
+func searchFilter(query models.SearchQuery, following []string) bson.M {
+	filters := []bson.M{{"$or": []bson.M{
 		{PUBLIC_FIELD: true},
 		{PUBLIC_FIELD: false, AUTHOR_ID_FIELD: bson.M{"$in": following}},
 		{PUBLIC_FIELD: false, RETWEET_AUTHOR_FIELD: bson.M{"$in": following}},
-	}}
+	}}}
 
-	cursor, err := postCollection.Find(context.Background(), filter, options.Find().
-		SetSort(bson.M{TIME_FIELD: -1}).SetSkip(int64(limitConfig.Skip)).SetLimit(int64(limitConfig.Limit)+1))
-	if err != nil {
-		log.Println(err)
+	for _, word := range query.Excluded {
+		filters = append(filters, bson.M{CONTENT_FIELD: bson.M{"$not": wordPattern(word)}})
 	}
-	defer cursor.Close(context.Background())
 
-	posts, err := d.createPostList(cursor, askerID)
+	if len(query.Authors) > 0 {
+		filters = append(filters, bson.M{RETWEET_AUTHOR_FIELD: bson.M{"$in": query.Authors}})
+	}
 
-	if err != nil {
-		log.Println(err)
-		return nil, false, postErrors.DatabaseError(err.Error())
+	if len(query.Tags) > 0 {
+		filters = append(filters, bson.M{TAGS_FIELD: bson.M{"$all": query.Tags}})
 	}
 
-	hasMore := len(posts) > limitConfig.Limit
+	if len(query.Mentions) > 0 {
+		filters = append(filters, bson.M{MENTIONS_FIELD: bson.M{"$all": query.Mentions}})
+	}
 
-	if hasMore {
-		posts = posts[:len(posts)-1]
+	if query.Since != nil {
+		filters = append(filters, bson.M{TIME_FIELD: bson.M{"$gte": *query.Since}})
 	}
 
-	return posts, hasMore, err
+	if query.Until != nil {
+		filters = append(filters, bson.M{TIME_FIELD: bson.M{"$lt": *query.Until}})
+	}
+
+	if query.HasMedia {
+		filters = append(filters, bson.M{MEDIA_INFO_FIELD + ".media_url": bson.M{"$nin": bson.A{nil, ""}}})
+	}
+
+	if query.IsRetweet {
+		filters = append(filters, bson.M{IS_RETWEET_FIELD: true})
+	}
+
+	return bson.M{"$and": filters}
 }
 
-func (d *AppDatabase) WordSearchPosts(words string, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+func (d *AppDatabase) SearchPosts(ctx context.Context, query models.SearchQuery, following []string, askerID string, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
 
 	postCollection := d.db.Collection(FEED_COLLECTION)
 
-	filters := bson.A{}
+	filter := pageFilter(searchFilter(query, following), limitConfig)
 
-	for _, word := range strings.Split(words, " ") {
-		if word != "" {
-			filters = append(filters, bson.M{CONTENT_FIELD: bson.M{"$regex": word, "$options": "i"}})
-		}
+	if query.HasText() {
+		filter["$text"] = bson.M{"$search": textSearch(query)}
 	}
 
-	parsedTime, err := time.Parse(time.RFC3339, limitConfig.FromTime)
+	findOptions := pageOptions(limitConfig)
 
-	if err != nil {
-		log.Println(err)
+	if query.Sort == models.RELEVANCE_SORT && query.HasText() {
+		findOptions.SetSort(bson.D{
+			{Key: "score", Value: bson.M{"$meta": "textScore"}},
+			{Key: TIME_FIELD, Value: -1},
+			{Key: POST_ID_FIELD, Value: -1},
+		})
 	}
 
-	filter := bson.M{"$and": []bson.M{{"$or": filters}, {TIME_FIELD: bson.M{"$lt": parsedTime.UTC()}}, {"$or": []bson.M{
-		{PUBLIC_FIELD: true},
-		{PUBLIC_FIELD: false, AUTHOR_ID_FIELD: bson.M{"$in": following}},
-		{PUBLIC_FIELD: false, RETWEET_AUTHOR_FIELD: bson.M{"$in": following}},
-	}}}}
-
-	cursor, err := postCollection.Find(context.Background(), filter, options.Find().
-		SetSort(bson.M{TIME_FIELD: -1}).SetSkip(int64(limitConfig.Skip)).SetLimit(int64(limitConfig.Limit)+1))
+	cursor, err := postCollection.Find(ctx, filter, findOptions)
 
 	if err != nil {
 		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
-	posts, err := d.createPostList(cursor, askerID)
+	posts, err := d.createPostList(ctx, cursor, askerID)
 
 	if err != nil {
 		log.Println(err)
@@ -102,76 +119,3 @@ func (d *AppDatabase) WordSearchPosts(words string, following []string, askerID
 
 	return posts, hasMore, err
 }
-
-
-func (d *AppDatabase) GetTrendingTopics() ([]string, error) {
-	postCollection := d.db.Collection(FEED_COLLECTION)
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$unwind", Value: bson.D{{Key: "path", Value: "$" + TAGS_FIELD}}}},
-		{{Key: "$match", Value: bson.D{
-			{Key: TAGS_FIELD, Value: bson.D{{Key: "$type", Value: "string"}}},
-			{Key: TIME_FIELD, Value: bson.D{{Key: "$type", Value: "date"}}},
-		}}},
-		{{Key: "$project",
-			Value: bson.D{
-				{Key: TAGS_FIELD, Value: 1},
-				{Key: "timeDifference", Value: bson.D{
-					{Key: "$divide", Value: bson.A{
-						bson.D{{Key: "$subtract", Value: bson.A{
-							bson.D{{Key: "$literal", Value: time.Now()}},
-							"$" + TIME_FIELD,
-						}}},
-						1000 * 60 * 60,
-					}},
-				}},
-			},
-		}},
-		{{
-			Key: "$group",
-			Value: bson.D{
-				{Key: "_id", Value: "$" + TAGS_FIELD},
-				{Key: "totalOccurrences", Value: bson.D{{Key: "$sum", Value: 1}}},
-				{Key: "averageTimeDifference", Value: bson.D{{Key: "$avg", Value: "$timeDifference"}}},
-			},
-		}},
-		{{
-			Key: "$project",
-			Value: bson.D{
-				{Key: TAGS_FIELD, Value: "$_id"},
-				{Key: "score", Value: bson.D{
-					{Key: "$multiply", Value: bson.A{
-						"$totalOccurrences",
-						bson.D{{Key: "$exp", Value: bson.D{
-							{Key: "$multiply", Value: bson.A{-0.1, "$averageTimeDifference"}},
-						}}},
-					}},
-				}},
-			},
-		}},
-		{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}}}},
-		{{Key: "$limit", Value: 20}},
-	}
-
-	cursor, err := postCollection.Aggregate(context.Background(), pipeline)
-	if err != nil {
-		log.Println(err)
-		return nil, postErrors.DatabaseError(err.Error())
-	}
-
-	var trendingTags []struct {
-		Tag string `bson:"tags"`
-	}
-
-	if err = cursor.All(context.Background(), &trendingTags); err != nil {
-		log.Println("Error decoding aggregation results:", err)
-		return nil, postErrors.DatabaseError("Error decoding aggregation results")
-	}
-
-	tags := make([]string, len(trendingTags))
-	for i, t := range trendingTags {
-		tags[i] = t.Tag
-	}
-
-	return tags, nil
-}
\ No newline at end of file
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"server/src/models"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	TAG_DAY_LAYOUT = "2006-01-02"
+)
+
+// tagChanges is how many uses of each tag a write adds or, when negative,
+// removes. All of them count at the time of the post.
+type tagChanges map[string]int
+
+// postTagChanges counts every tag of an original post once. Retweets repeat
+// the tags of their post, so they are not counted again.
+func postTagChanges(post models.DBPost, sign int) tagChanges {
+	changes := tagChanges{}
+
+	if post.Is_Retweet {
+		return changes
+	}
+
+	for _, tag := range post.Tags {
+		changes[tag] = sign
+	}
+
+	return changes
+}
+
+// editTagChanges counts the tags an edit removed and added.
+func editTagChanges(oldTags []string, newTags []string) tagChanges {
+	changes := tagChanges{}
+
+	for _, tag := range oldTags {
+		if !slices.Contains(newTags, tag) {
+			changes[tag] = -1
+		}
+	}
+
+	for _, tag := range newTags {
+		if !slices.Contains(oldTags, tag) {
+			changes[tag] = 1
+		}
+	}
+
+	return changes
+}
+
+func (c tagChanges) inverse() tagChanges {
+	inverse := tagChanges{}
+	for tag, delta := range c {
+		inverse[tag] = -delta
+	}
+	return inverse
+}
+
+func tagDay(at time.Time) string {
+	return at.UTC().Format(TAG_DAY_LAYOUT)
+}
+
+func tagHour(at time.Time) string {
+	return fmt.Sprintf("%02d", at.UTC().Hour())
+}
+
+func hoursBetween(from time.Time, to time.Time) float64 {
+	return math.Max(0, to.Sub(from).Hours())
+}
+
+// trendWeight is what a post made at postTime adds to the trend at now.
+func trendWeight(postTime time.Time, now time.Time) float64 {
+	return math.Exp(-TRENDING_DECAY * hoursBetween(postTime, now))
+}
+
+// decayTrend brings a trend last updated at lastUpdated forward to now.
+func decayTrend(trend float64, lastUpdated time.Time, now time.Time) float64 {
+	return trend * math.Exp(-TRENDING_DECAY*hoursBetween(lastUpdated, now))
+}
+
+// updateTagMetrics adds the changes to the hourly count of the day of the
+// post and to the totals and trend of every tag. The trend is decayed and
+// updated in the same statement, so concurrent writes do not lose updates.
+func (d *AppDatabase) updateTagMetrics(ctx context.Context, postTime time.Time, changes tagChanges) error {
+	usageCollection := d.db.Collection(TWTMETRICS_COLLECTION)
+	tagCollection := d.db.Collection(TAGMETRICS_COLLECTION)
+
+	now := time.Now().UTC()
+	upsert := options.Update().SetUpsert(true)
+
+	for tag, delta := range changes {
+		if delta == 0 {
+			continue
+		}
+
+		_, err := usageCollection.UpdateOne(ctx,
+			bson.M{TAG_FIELD: tag, DAY: tagDay(postTime)},
+			bson.M{"$inc": bson.M{HOURLY_FRECUENCY + "." + tagHour(postTime): delta, TOTAL_TWEETS: delta}},
+			upsert)
+
+		if err != nil {
+			return err
+		}
+
+		elapsedHours := bson.M{"$max": bson.A{0, bson.M{"$divide": bson.A{
+			bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$" + LAST_UPDATED, now}}}},
+			float64(time.Hour.Milliseconds()),
+		}}}}
+		decayed := bson.M{"$multiply": bson.A{
+			bson.M{"$ifNull": bson.A{"$" + TREND, 0}},
+			bson.M{"$exp": bson.M{"$multiply": bson.A{-TRENDING_DECAY, elapsedHours}}},
+		}}
+
+		update := mongo.Pipeline{{{Key: "$set", Value: bson.D{
+			{Key: TOTAL_TWEETS, Value: bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$" + TOTAL_TWEETS, 0}}, delta}}},
+			{Key: TREND, Value: bson.M{"$max": bson.A{0, bson.M{"$add": bson.A{decayed, float64(delta) * trendWeight(postTime, now)}}}}},
+			{Key: LAST_UPDATED, Value: now},
+		}}}}
+
+		if _, err := tagCollection.UpdateOne(ctx, bson.M{TAG_FIELD: tag}, update, upsert); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *AppDatabase) GetTagSummary(ctx context.Context, tag string) (models.TagSummary, error) {
+	tagCollection := d.db.Collection(TAGMETRICS_COLLECTION)
+
+	var summary models.TagSummary
+
+	err := tagCollection.FindOne(ctx, bson.M{TAG_FIELD: tag}).Decode(&summary)
+
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.TagSummary{Tag: tag}, nil
+	}
+
+	if err != nil {
+		log.Println(err)
+		return models.TagSummary{}, err
+	}
+
+	now := time.Now().UTC()
+	summary.Trend = decayTrend(summary.Trend, summary.Last_Updated, now)
+	summary.Last_Updated = now
+
+	return summary, nil
+}
+
+func (d *AppDatabase) GetTagUsage(ctx context.Context, tag string, from time.Time, to time.Time) ([]models.TagUsage, error) {
+	usageCollection := d.db.Collection(TWTMETRICS_COLLECTION)
+
+	filter := bson.M{TAG_FIELD: tag, DAY: bson.M{"$gte": tagDay(from), "$lte": tagDay(to)}}
+	opts := options.Find().SetSort(bson.D{{Key: DAY, Value: 1}})
+
+	cursor, err := usageCollection.Find(ctx, filter, opts)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	usage := []models.TagUsage{}
+
+	if err := cursor.All(ctx, &usage); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"log"
+	"math"
+	"regexp"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// tagSuggestionScore adds how recently a tag is used, where every post
+// weighs less as it gets older, to how much it is used, which grows slowly
+// so that old popular tags do not bury the new ones.
+func tagSuggestionScore(recent float64, posts int) float64 {
+	return recent + math.Log1p(float64(posts))
+}
+
+// GetTagSuggestions completes prefix, a TagKey, with the tags of the
+// unblocked original posts that are public or by someone in following. Every
+// tag is shown with the spelling most posts use.
+func (d *AppDatabase) GetTagSuggestions(ctx context.Context, prefix string, following []string, now time.Time, limit int) ([]models.TagSuggestion, error) {
+	postCollection := d.db.Collection(FEED_COLLECTION)
+
+	startsWithPrefix := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}
+	hours := bson.D{{Key: "$max", Value: bson.A{0, bson.D{{Key: "$divide", Value: bson.A{
+		bson.D{{Key: "$subtract", Value: bson.A{now.UTC(), "$" + TIME_FIELD}}},
+		1000 * 60 * 60,
+	}}}}}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{
+			{Key: TAG_KEYS_FIELD, Value: startsWithPrefix},
+			{Key: IS_RETWEET_FIELD, Value: false},
+			{Key: BLOCKED_FIELD, Value: bson.D{{Key: "$ne", Value: true}}},
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: PUBLIC_FIELD, Value: true}},
+				bson.D{{Key: AUTHOR_ID_FIELD, Value: bson.D{{Key: "$in", Value: following}}}},
+			}},
+		}}},
+		// A post counts once for every tag it has, however many times it
+		// repeats it.
+		{{Key: "$project", Value: bson.D{
+			{Key: TIME_FIELD, Value: 1},
+			{Key: "pairs", Value: bson.D{{Key: "$setUnion", Value: bson.A{bson.D{{Key: "$zip", Value: bson.D{
+				{Key: "inputs", Value: bson.A{"$" + TAGS_FIELD, "$" + TAG_KEYS_FIELD}},
+			}}}}}}},
+		}}},
+		{{Key: "$unwind", Value: "$pairs"}},
+		{{Key: "$project", Value: bson.D{
+			{Key: TIME_FIELD, Value: 1},
+			{Key: TAG_FIELD, Value: bson.D{{Key: "$arrayElemAt", Value: bson.A{"$pairs", 0}}}},
+			{Key: "key", Value: bson.D{{Key: "$arrayElemAt", Value: bson.A{"$pairs", 1}}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "key", Value: startsWithPrefix}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "key", Value: "$key"}, {Key: TAG_FIELD, Value: "$" + TAG_FIELD}}},
+			{Key: "posts", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "recent", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$exp", Value: bson.D{{Key: "$multiply", Value: bson.A{-TRENDING_DECAY, hours}}}}}}}},
+			{Key: "last_used", Value: bson.D{{Key: "$max", Value: "$" + TIME_FIELD}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "posts", Value: -1}, {Key: "_id." + TAG_FIELD, Value: 1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$_id.key"},
+			{Key: TAG_FIELD, Value: bson.D{{Key: "$first", Value: "$_id." + TAG_FIELD}}},
+			{Key: "posts", Value: bson.D{{Key: "$sum", Value: "$posts"}}},
+			{Key: "recent", Value: bson.D{{Key: "$sum", Value: "$recent"}}},
+			{Key: "last_used", Value: bson.D{{Key: "$max", Value: "$last_used"}}},
+		}}},
+		{{Key: "$set", Value: bson.D{{Key: "score", Value: bson.D{{Key: "$add", Value: bson.A{
+			"$recent",
+			bson.D{{Key: "$ln", Value: bson.D{{Key: "$add", Value: bson.A{1, "$posts"}}}}},
+		}}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: TAG_FIELD, Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := postCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Println(err)
+		return nil, postErrors.DatabaseError(err.Error())
+	}
+
+	suggestions := []models.TagSuggestion{}
+
+	if err = cursor.All(ctx, &suggestions); err != nil {
+		log.Println("Error decoding aggregation results:", err)
+		return nil, postErrors.DatabaseError("Error decoding aggregation results")
+	}
+
+	return suggestions, nil
+}
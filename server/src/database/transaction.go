@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Steps of the writes that touch more than one collection. They are passed to
+// the WriteStepHook so tests can fail a write at a precise point.
+const (
+	STEP_POST             = "post"
+	STEP_RETWEET_POSTS    = "retweet_posts"
+	STEP_LIKES_COUNTER    = "likes_counter"
+	STEP_LIKERS           = "likers"
+	STEP_RETWEETS_COUNTER = "retweets_counter"
+	STEP_RETWEETERS       = "retweeters"
+	STEP_OUTBOX           = "outbox"
+	STEP_TAG_METRICS      = "tag_metrics"
+	STEP_REPLIES_COUNTER  = "replies_counter"
+	STEP_TOMBSTONE        = "tombstone"
+	STEP_QUOTES_COUNTER   = "quotes_counter"
+	STEP_REVISION         = "revision"
+)
+
+// WriteStepHook is called before each step of a multi-collection write with
+// the name of the operation and of the step about to run. Returning an error
+// aborts the write as if the step itself had failed.
+type WriteStepHook func(operation string, step string) error
+
+// WriteStepHooker is implemented by the databases that let tests inject
+// failures between the steps of their multi-collection writes.
+type WriteStepHooker interface {
+	SetWriteStepHook(hook WriteStepHook)
+}
+
+// writeStep is one change of a multi-collection write. undo reverts apply and
+// is only used when the write can not run inside a transaction, so the last
+// step of a write does not need one.
+type writeStep struct {
+	name  string
+	apply func(ctx context.Context) error
+	undo  func(ctx context.Context) error
+}
+
+// when skips step unless done holds by the time it runs, for the steps that
+// only make sense if an earlier step of the write changed something.
+func when(done *bool, step writeStep) writeStep {
+	apply, undo := step.apply, step.undo
+
+	step.apply = func(ctx context.Context) error {
+		if !*done {
+			return nil
+		}
+		return apply(ctx)
+	}
+
+	if undo != nil {
+		step.undo = func(ctx context.Context) error {
+			if !*done {
+				return nil
+			}
+			return undo(ctx)
+		}
+	}
+
+	return step
+}
+
+type writeHooks struct {
+	hook atomic.Pointer[WriteStepHook]
+}
+
+func (h *writeHooks) set(hook WriteStepHook) {
+	if hook == nil {
+		h.hook.Store(nil)
+		return
+	}
+	h.hook.Store(&hook)
+}
+
+func (h *writeHooks) beforeStep(operation string, step string) error {
+	hook := h.hook.Load()
+	if hook == nil {
+		return nil
+	}
+	return (*hook)(operation, step)
+}
+
+// runCompensated applies the steps in order. When one of them fails, the ones
+// already applied are undone in reverse order so nothing partial is left.
+func (h *writeHooks) runCompensated(ctx context.Context, operation string, steps []writeStep) error {
+	for i, step := range steps {
+		err := h.beforeStep(operation, step.name)
+		if err == nil {
+			err = step.apply(ctx)
+		}
+
+		if err != nil {
+			undoSteps(ctx, operation, steps[:i])
+			return err
+		}
+	}
+
+	return nil
+}
+
+func undoSteps(ctx context.Context, operation string, applied []writeStep) {
+	// The undo has to run even if the request was cancelled, otherwise a
+	// deadline would leave the write half done.
+	ctx = context.WithoutCancel(ctx)
+
+	for i := len(applied) - 1; i >= 0; i-- {
+		if applied[i].undo == nil {
+			continue
+		}
+		if err := applied[i].undo(ctx); err != nil {
+			log.Println("Could not undo step", applied[i].name, "of", operation, ":", err)
+		}
+	}
+}
+
+func (d *AppDatabase) SetWriteStepHook(hook WriteStepHook) {
+	d.hooks.set(hook)
+}
+
+func (m *MemoryDatabase) SetWriteStepHook(hook WriteStepHook) {
+	m.hooks.set(hook)
+}
+
+type transactionSupport struct {
+	mu        sync.Mutex
+	checked   bool
+	supported bool
+}
+
+// runWrite runs the steps inside a multi-document transaction. Standalone
+// servers do not support transactions, so there the steps are applied one by
+// one and undone if any of them fails.
+func (d *AppDatabase) runWrite(ctx context.Context, operation string, steps []writeStep) error {
+	supported, err := d.supportsTransactions(ctx)
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	if !supported {
+		return d.hooks.runCompensated(ctx, operation, steps)
+	}
+
+	session, err := d.db.Client().StartSession()
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (interface{}, error) {
+		for _, step := range steps {
+			if err := d.hooks.beforeStep(operation, step.name); err != nil {
+				return nil, err
+			}
+			if err := step.apply(sessionContext); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+
+	return err
+}
+
+// supportsTransactions asks the server whether it is part of a replica set or
+// a sharded cluster. The answer is cached once the server replied.
+func (d *AppDatabase) supportsTransactions(ctx context.Context) (bool, error) {
+	d.transactions.mu.Lock()
+	defer d.transactions.mu.Unlock()
+
+	if d.transactions.checked {
+		return d.transactions.supported, nil
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+
+	err := d.db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+
+	if err != nil {
+		return false, err
+	}
+
+	d.transactions.checked = true
+	d.transactions.supported = hello.SetName != "" || hello.Msg == "isdbgrid"
+
+	if !d.transactions.supported {
+		log.Println("MongoDB does not support transactions, multi-collection writes will be undone on failure instead")
+	}
+
+	return d.transactions.supported, nil
+}
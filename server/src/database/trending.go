@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ComputeTrendingTopics scores every tag as the posts using it times the decay
+// of their average age in hours.
+func (d *AppDatabase) ComputeTrendingTopics(ctx context.Context, since time.Time, now time.Time, decay float64, limit int) ([]models.TrendingTopic, error) {
+	postCollection := d.db.Collection(FEED_COLLECTION)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{
+			{Key: TIME_FIELD, Value: bson.D{{Key: "$gte", Value: since.UTC()}, {Key: "$lte", Value: now.UTC()}}},
+			{Key: IS_RETWEET_FIELD, Value: false},
+			{Key: PUBLIC_FIELD, Value: true},
+			{Key: BLOCKED_FIELD, Value: bson.D{{Key: "$ne", Value: true}}},
+		}}},
+		{{Key: "$unwind", Value: bson.D{{Key: "path", Value: "$" + TAGS_FIELD}}}},
+		{{Key: "$match", Value: bson.D{
+			{Key: TAGS_FIELD, Value: bson.D{{Key: "$type", Value: "string"}}},
+		}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$" + TAGS_FIELD},
+			{Key: "posts", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "averageHours", Value: bson.D{{Key: "$avg", Value: bson.D{
+				{Key: "$divide", Value: bson.A{
+					bson.D{{Key: "$subtract", Value: bson.A{now.UTC(), "$" + TIME_FIELD}}},
+					1000 * 60 * 60,
+				}},
+			}}}},
+		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: TAG_FIELD, Value: "$_id"},
+			{Key: "posts", Value: 1},
+			{Key: "score", Value: bson.D{{Key: "$multiply", Value: bson.A{
+				"$posts",
+				bson.D{{Key: "$exp", Value: bson.D{{Key: "$multiply", Value: bson.A{-decay, "$averageHours"}}}}},
+			}}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: TAG_FIELD, Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := postCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Println(err)
+		return nil, postErrors.DatabaseError(err.Error())
+	}
+
+	topics := []models.TrendingTopic{}
+
+	if err = cursor.All(ctx, &topics); err != nil {
+		log.Println("Error decoding aggregation results:", err)
+		return nil, postErrors.DatabaseError("Error decoding aggregation results")
+	}
+
+	return topics, nil
+}
+
+func (d *AppDatabase) SaveTrendingTopics(ctx context.Context, snapshot models.TrendingSnapshot) error {
+	trendingCollection := d.db.Collection(TRENDING_COLLECTION)
+
+	_, err := trendingCollection.ReplaceOne(ctx, bson.M{WINDOW_FIELD: snapshot.Window}, snapshot, options.Replace().SetUpsert(true))
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	return err
+}
+
+func (d *AppDatabase) GetTrendingTopics(ctx context.Context, window string) (models.TrendingSnapshot, error) {
+	trendingCollection := d.db.Collection(TRENDING_COLLECTION)
+
+	var snapshot models.TrendingSnapshot
+
+	err := trendingCollection.FindOne(ctx, bson.M{WINDOW_FIELD: window}).Decode(&snapshot)
+
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.TrendingSnapshot{Window: window, Topics: []models.TrendingTopic{}}, nil
+	}
+
+	if err != nil {
+		log.Println(err)
+		return models.TrendingSnapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+// trendingPostScore is the engagement of a post over its age.
+func trendingPostScore(post models.DBPost, now time.Time) float64 {
+	engagement := float64(post.Likes + TRENDING_RETWEET_WEIGHT*post.Retweets)
+	return engagement / math.Pow(hoursBetween(post.Time, now)+2, TRENDING_GRAVITY)
+}
+
+// GetTrendingPosts computes trendingPostScore in the pipeline. Posts with the
+// same score keep the newest first, so pages do not overlap.
+func (d *AppDatabase) GetTrendingPosts(ctx context.Context, following []string, askerID string, since time.Time, now time.Time, limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+	postCollection := d.db.Collection(FEED_COLLECTION)
+
+	ageHours := bson.D{{Key: "$max", Value: bson.A{0, bson.D{{Key: "$divide", Value: bson.A{
+		bson.D{{Key: "$subtract", Value: bson.A{now.UTC(), "$" + TIME_FIELD}}},
+		1000 * 60 * 60,
+	}}}}}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			TIME_FIELD:       bson.M{"$gte": since.UTC(), "$lte": now.UTC()},
+			IS_RETWEET_FIELD: false,
+			BLOCKED_FIELD:    false,
+			"$or": []bson.M{
+				{PUBLIC_FIELD: true},
+				{PUBLIC_FIELD: false, AUTHOR_ID_FIELD: bson.M{"$in": following}},
+			},
+		}}},
+		{{Key: "$addFields", Value: bson.D{{Key: "score", Value: bson.D{{Key: "$divide", Value: bson.A{
+			bson.D{{Key: "$add", Value: bson.A{"$" + LIKES_FIELD, bson.D{{Key: "$multiply", Value: bson.A{TRENDING_RETWEET_WEIGHT, "$" + RETWEET_FIELD}}}}}},
+			bson.D{{Key: "$pow", Value: bson.A{bson.D{{Key: "$add", Value: bson.A{ageHours, 2}}}, TRENDING_GRAVITY}}},
+		}}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: TIME_FIELD, Value: -1}, {Key: POST_ID_FIELD, Value: -1}}}},
+		{{Key: "$skip", Value: int64(limitConfig.Skip)}},
+		{{Key: "$limit", Value: int64(limitConfig.Limit) + 1}},
+	}
+
+	cursor, err := postCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	posts, err := d.createPostList(ctx, cursor, askerID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, false, postErrors.DatabaseError(err.Error())
+	}
+
+	hasMore := len(posts) > limitConfig.Limit
+
+	if hasMore {
+		posts = posts[:len(posts)-1]
+	}
+
+	return posts, hasMore, err
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"server/src/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// viewerState is what the asker did with the posts of a page. Likes and
+// retweets are keyed by original post ID and bookmarks by post ID, the same
+// keys hasLiked, hasRetweeted and hasBookmark look up one post at a time.
+type viewerState struct {
+	liked      map[string]bool
+	retweeted  map[string]bool
+	bookmarked map[string]bool
+	quoted     map[string]models.DBPost
+}
+
+func (s viewerState) frontPost(post models.DBPost) models.FrontPost {
+	author := defaultAuthorInfo(post.Author_ID)
+
+	post = normalizePostLists(post)
+
+	frontPost := models.NewFrontPost(post, author,
+		s.liked[post.Original_Post_ID], s.retweeted[post.Original_Post_ID], s.bookmarked[post.Post_ID])
+
+	return withQuotedPost(frontPost, s.quoted)
+}
+
+// loadViewerState resolves the state of the whole page, and the posts it
+// quotes, with one query per collection instead of several queries per post.
+func (d *AppDatabase) loadViewerState(ctx context.Context, posts []models.DBPost, askerID string) (viewerState, error) {
+	state := viewerState{liked: map[string]bool{}, retweeted: map[string]bool{}, bookmarked: map[string]bool{}, quoted: map[string]models.DBPost{}}
+
+	if len(posts) == 0 {
+		return state, nil
+	}
+
+	originalIDs := []string{}
+	postIDs := []string{}
+
+	for _, post := range posts {
+		originalIDs = append(originalIDs, post.Original_Post_ID)
+		postIDs = append(postIDs, post.Post_ID)
+	}
+
+	var err error
+
+	if askerID != ADMIN {
+		state.liked, err = d.findMemberships(ctx, LIKES_COLLECTION, LIKERS_FIELD, originalIDs, askerID)
+		if err != nil {
+			return viewerState{}, err
+		}
+
+		state.retweeted, err = d.findMemberships(ctx, RETWEET_COLLECTION, RETWEETERS_FIELD, originalIDs, askerID)
+		if err != nil {
+			return viewerState{}, err
+		}
+	}
+
+	state.bookmarked, err = d.findBookmarks(ctx, postIDs, askerID)
+	if err != nil {
+		return viewerState{}, err
+	}
+
+	state.quoted, err = d.findQuotedPosts(ctx, posts)
+	if err != nil {
+		return viewerState{}, err
+	}
+
+	return state, nil
+}
+
+// findMemberships returns which of the original posts have userID in their
+// likers or retweeters set.
+func (d *AppDatabase) findMemberships(ctx context.Context, collection string, membersField string, originalIDs []string, userID string) (map[string]bool, error) {
+	filter := bson.M{ORIGINAL_POST_ID_FIELD: bson.M{"$in": originalIDs}, membersField: userID}
+	projection := bson.M{"_id": 0, ORIGINAL_POST_ID_FIELD: 1}
+
+	cursor, err := d.db.Collection(collection).Find(ctx, filter, options.Find().SetProjection(projection))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Original_Post_ID string `bson:"original_post_id"`
+	}
+
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	members := map[string]bool{}
+
+	for _, result := range results {
+		members[result.Original_Post_ID] = true
+	}
+
+	return members, nil
+}
+
+// findBookmarks returns which of the posts userID bookmarked. Only the
+// bookmarks on the page are sent back, not the whole list of the user.
+func (d *AppDatabase) findBookmarks(ctx context.Context, postIDs []string, userID string) (map[string]bool, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{AUTHOR_ID_FIELD: userID, POST_ID_FIELD: bson.M{"$in": postIDs}}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":         0,
+			POST_ID_FIELD: bson.M{"$setIntersection": bson.A{"$" + POST_ID_FIELD, postIDs}},
+		}}},
+	}
+
+	cursor, err := d.db.Collection(BOOKMARK_COLLECTION).Aggregate(ctx, pipeline)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Post_IDs []string `bson:"post_id"`
+	}
+
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	bookmarked := map[string]bool{}
+
+	for _, result := range results {
+		for _, postID := range result.Post_IDs {
+			bookmarked[postID] = true
+		}
+	}
+
+	return bookmarked, nil
+}
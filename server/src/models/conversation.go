@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+const (
+	TREE_CONVERSATION   = "tree"
+	THREAD_CONVERSATION = "thread"
+	// THREAD_PATH_TIME is fixed width, so paths sort by time as strings.
+	THREAD_PATH_TIME = "2006-01-02T15:04:05.000Z"
+	// THREAD_PATH_SEPARATOR sorts before every character of a time or a post
+	// ID, so the replies of a post come before the posts that sort after it.
+	THREAD_PATH_SEPARATOR = " "
+)
+
+// Tombstone is what is left of a deleted post that had replies.
+type Tombstone struct {
+	Post_ID             string    `bson:"post_id" json:"post_id"`
+	In_Reply_To_Post_ID string    `bson:"in_reply_to_post_id" json:"in_reply_to_post_id"`
+	Conversation_ID     string    `bson:"conversation_id" json:"conversation_id"`
+	Time                time.Time `bson:"time" json:"time"`
+	Replies             int       `bson:"replies" json:"replies"`
+	Deleted_At          time.Time `bson:"deleted_at" json:"deleted_at"`
+	Thread_Path         string    `bson:"thread_path" json:"-"`
+}
+
+func NewTombstone(post DBPost) Tombstone {
+	return Tombstone{
+		Post_ID:             post.Post_ID,
+		In_Reply_To_Post_ID: post.In_Reply_To_Post_ID,
+		Conversation_ID:     post.Conversation_ID,
+		Time:                post.Time,
+		Replies:             post.Replies,
+		Deleted_At:          time.Now().UTC().Truncate(time.Millisecond),
+		Thread_Path:         post.Thread_Path,
+	}
+}
+
+// Post returns the place the deleted post had in its conversation.
+func (t Tombstone) Post() DBPost {
+	return DBPost{
+		Post_ID:             t.Post_ID,
+		Original_Post_ID:    t.Post_ID,
+		In_Reply_To_Post_ID: t.In_Reply_To_Post_ID,
+		Conversation_ID:     t.Conversation_ID,
+		Time:                t.Time,
+		Replies:             t.Replies,
+		Thread_Path:         t.Thread_Path,
+	}
+}
+
+// ThreadPath is the path of post in the thread of its conversation: the path
+// of the post it replies to followed by its own time and ID. Sorting by path
+// reads the thread with every post followed by its replies, oldest first.
+func ThreadPath(parentPath string, post DBPost) string {
+	segment := post.Time.UTC().Format(THREAD_PATH_TIME) + "_" + post.Post_ID
+
+	if parentPath == "" {
+		return segment
+	}
+	return parentPath + THREAD_PATH_SEPARATOR + segment
+}
+
+// ConversationNode is a post of a conversation with its replies, oldest
+// first.
+type ConversationNode struct {
+	Post    FrontPost          `json:"post"`
+	Replies []ConversationNode `json:"replies"`
+}
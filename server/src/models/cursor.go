@@ -0,0 +1,145 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// PostCursor points at the last post of a page. The next page holds the posts
+// that sort after it by time and then by post ID, both descending, so posts
+// sharing a timestamp are neither repeated nor skipped.
+type PostCursor struct {
+	Time    time.Time
+	Post_ID string
+}
+
+type encodedCursor struct {
+	Time    string `json:"t"`
+	Post_ID string `json:"id"`
+}
+
+// NewPostCursor returns the cursor of the page that ends with post.
+func NewPostCursor(post FrontPost) (PostCursor, error) {
+	postTime, err := time.Parse(time.RFC3339Nano, post.Time)
+
+	if err != nil {
+		return PostCursor{}, err
+	}
+
+	return PostCursor{Time: postTime.UTC(), Post_ID: post.Post_ID}, nil
+}
+
+// Encode returns the opaque form of the cursor that is handed to clients.
+func (c PostCursor) Encode() string {
+	data, _ := json.Marshal(encodedCursor{Time: c.Time.Format(time.RFC3339Nano), Post_ID: c.Post_ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodePostCursor(cursor string) (PostCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return PostCursor{}, err
+	}
+
+	var decoded encodedCursor
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return PostCursor{}, err
+	}
+
+	if decoded.Post_ID == "" {
+		return PostCursor{}, errors.New("cursor without post id")
+	}
+
+	postTime, err := time.Parse(time.RFC3339Nano, decoded.Time)
+
+	if err != nil {
+		return PostCursor{}, err
+	}
+
+	return PostCursor{Time: postTime.UTC(), Post_ID: decoded.Post_ID}, nil
+}
+
+// ThreadCursor points at the last post of a page of a thread. The next page
+// holds the posts whose thread path sorts after it.
+type ThreadCursor struct {
+	Thread_Path string
+}
+
+type encodedThreadCursor struct {
+	Thread_Path string `json:"p"`
+}
+
+// Encode returns the opaque form of the cursor that is handed to clients.
+func (c ThreadCursor) Encode() string {
+	data, _ := json.Marshal(encodedThreadCursor{Thread_Path: c.Thread_Path})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeThreadCursor(cursor string) (ThreadCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return ThreadCursor{}, err
+	}
+
+	var decoded encodedThreadCursor
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return ThreadCursor{}, err
+	}
+
+	if decoded.Thread_Path == "" {
+		return ThreadCursor{}, errors.New("cursor without thread path")
+	}
+
+	return ThreadCursor{Thread_Path: decoded.Thread_Path}, nil
+}
+
+// TrendingCursor points at the next page of a ranking: how many posts the
+// pages before listed, and when the ranking was computed, so every page ranks
+// the posts against the same time and they neither repeat nor go missing as
+// the scores age.
+type TrendingCursor struct {
+	Computed_At time.Time
+	Skip        int
+}
+
+type encodedTrendingCursor struct {
+	Computed_At string `json:"at"`
+	Skip        int    `json:"skip"`
+}
+
+func (c TrendingCursor) Encode() string {
+	data, _ := json.Marshal(encodedTrendingCursor{Computed_At: c.Computed_At.Format(time.RFC3339Nano), Skip: c.Skip})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeTrendingCursor(cursor string) (TrendingCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return TrendingCursor{}, err
+	}
+
+	var decoded encodedTrendingCursor
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return TrendingCursor{}, err
+	}
+
+	if decoded.Skip < 0 {
+		return TrendingCursor{}, errors.New("cursor with negative skip")
+	}
+
+	computedAt, err := time.Parse(time.RFC3339Nano, decoded.Computed_At)
+
+	if err != nil {
+		return TrendingCursor{}, err
+	}
+
+	return TrendingCursor{Computed_At: computedAt.UTC(), Skip: decoded.Skip}, nil
+}
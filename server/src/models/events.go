@@ -0,0 +1,83 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of the events published for other services.
+const (
+	NEW_CONTENT       = "NEW_CONTENT"
+	EDITED_CONTENT    = "EDITED_CONTENT"
+	DELETED_CONTENT   = "DELETED_CONTENT"
+	BLOCKED_CONTENT   = "BLOCKED_CONTENT"
+	UNBLOCKED_CONTENT = "UNBLOCKED_CONTENT"
+	NEW_LIKE          = "NEW_LIKE"
+	REMOVED_LIKE      = "REMOVED_LIKE"
+	NEW_RETWEET       = "NEW_RETWEET"
+	REMOVED_RETWEET   = "REMOVED_RETWEET"
+)
+
+// QueueMessage is an event as published. Events are delivered at least once,
+// so consumers should skip the message IDs they have already seen.
+type QueueMessage struct {
+	MessageID   string      `json:"message_id"`
+	MessageType string      `json:"message_type"`
+	Message     interface{} `json:"message"`
+}
+
+func NewQueueMessage(messageType string, message interface{}) QueueMessage {
+	return QueueMessage{MessageID: uuid.NewString(), MessageType: messageType, Message: message}
+}
+
+// DecodeQueueMessage reads an encoded event, keeping its message as raw JSON.
+func DecodeQueueMessage(data []byte) (QueueMessage, error) {
+	message := json.RawMessage{}
+	event := QueueMessage{Message: &message}
+
+	if err := json.Unmarshal(data, &event); err != nil {
+		return QueueMessage{}, err
+	}
+
+	return event, nil
+}
+
+// New_Content is the message of NEW_CONTENT and EDITED_CONTENT events.
+type New_Content struct {
+	Post_ID   string   `json:"post_id"`
+	Author_ID string   `json:"author_id"`
+	Hashtags  []string `json:"hashtags"`
+	Timestamp string   `json:"timestamp"`
+}
+
+// Content_Change is the message of DELETED_CONTENT, BLOCKED_CONTENT and
+// UNBLOCKED_CONTENT events.
+type Content_Change struct {
+	Post_ID   string `json:"post_id"`
+	Timestamp string `json:"timestamp"`
+}
+
+// Engagement is the message of the like and retweet events.
+type Engagement struct {
+	Post_ID   string `json:"post_id"`
+	User_ID   string `json:"user_id"`
+	Timestamp string `json:"timestamp"`
+}
+
+func eventTimestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+func NewContentEvent(messageType string, postID string, authorID string, hashtags []string) QueueMessage {
+	return NewQueueMessage(messageType, New_Content{Post_ID: postID, Author_ID: authorID, Hashtags: hashtags, Timestamp: eventTimestamp()})
+}
+
+func NewContentChangeEvent(messageType string, postID string) QueueMessage {
+	return NewQueueMessage(messageType, Content_Change{Post_ID: postID, Timestamp: eventTimestamp()})
+}
+
+func NewEngagementEvent(messageType string, postID string, userID string) QueueMessage {
+	return NewQueueMessage(messageType, Engagement{Post_ID: postID, User_ID: userID, Timestamp: eventTimestamp()})
+}
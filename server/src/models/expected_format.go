@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type PostExpectedFormat struct {
 	Content string   `json:"content" validate:"required"`
 	Public  bool     `json:"public"`
@@ -7,6 +9,10 @@ type PostExpectedFormat struct {
 	MediaInfo MediaInfo `json:"media_info"`
 }
 
+type RevertExpectedFormat struct {
+	Revision *int `json:"revision" validate:"required,min=0"`
+}
+
 type LikeExpectedFormat struct {
 	User_ID string `json:"user_id"`
 }
@@ -23,8 +29,21 @@ type ReturnPaginatedPosts struct {
 	Pagination  Pagination  `json:"pagination"`
 }
 
+type ReturnPaginatedRevisions struct {
+	Post_ID    string     `json:"post_id"`
+	Data       []Revision `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+type ReturnTagSuggestions struct {
+	Prefix string          `json:"prefix"`
+	Data   []TagSuggestion `json:"data"`
+}
+
 type ReturnPaginatesdTrendingTopics struct {
-	Topics        []string `json:"topics"`
+	Window      string `json:"window"`
+	Computed_At time.Time `json:"computed_at"`
+	Topics        []TrendingTopic `json:"topics"`
 	Pagination  Pagination  `json:"pagination"`
 }
 
@@ -14,11 +14,15 @@ type LimitConfig struct {
 	FromTime string
 	Skip int
 	Limit int
+	Cursor *PostCursor
 }
 
 type MetricLimits struct {
 	FromTime string
 	ToTime string
+	Interval string
+	Timezone string
+	Visibility string
 }
 
 func NewLimitConfig(fromTime string, skipS string, limitS string) LimitConfig {
@@ -1,8 +1,6 @@
 package models
 
-const (
-	NEW_CONTENT = "NEW_CONTENT"
-)
+import "time"
 
 type UserMetrics struct {
 	Likes    int `json:"likes"`
@@ -10,12 +8,132 @@ type UserMetrics struct {
 	Posts    int `json:"posts"`
 }
 
-type QueueMessage struct {
-	MessageType string `json:"message_type"`
-	Message     interface{} `json:"message"`
+type CacheMetrics struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	Hit_Rate float64 `json:"hit_rate"`
+	Entries  int     `json:"entries"`
+}
+
+type ServiceCacheMetrics struct {
+	Following CacheMetrics `json:"following"`
+	Authors   CacheMetrics `json:"authors"`
+}
+
+// TagUsage counts the posts that used a tag on one day (UTC), by hour.
+type TagUsage struct {
+	Tag              string         `bson:"tag" json:"tag"`
+	Day              string         `bson:"day" json:"day"`
+	Hourly_Frecuency map[string]int `bson:"hourly_frecuency" json:"hourly_frecuency"`
+	Total_Tweets     int            `bson:"total_tweets" json:"total_tweets"`
+}
+
+// TagSummary holds the totals of a tag. Trend counts every post with the tag,
+// each one decaying exponentially with its age.
+type TagSummary struct {
+	Tag          string    `bson:"tag" json:"tag"`
+	Total_Tweets int       `bson:"total_tweets" json:"total_tweets"`
+	Trend        float64   `bson:"trend" json:"trend"`
+	Last_Updated time.Time `bson:"last_updated" json:"last_updated"`
+}
+
+type MetricBucket struct {
+	Time  string `json:"time"`
+	Count int    `json:"count"`
+}
+
+type TagMetrics struct {
+	Tag          string         `json:"tag"`
+	Total_Tweets int            `json:"total_tweets"`
+	Trend        float64        `json:"trend"`
+	Interval     string         `json:"interval"`
+	Series       []MetricBucket `json:"series"`
+}
+
+const (
+	HOUR_INTERVAL  = "hour"
+	DAY_INTERVAL   = "day"
+	WEEK_INTERVAL  = "week"
+	MONTH_INTERVAL = "month"
+)
+
+// TruncateToInterval returns the start of the hour, day, week (starting on
+// Monday) or month of t, as seen in loc.
+func TruncateToInterval(t time.Time, interval string, loc *time.Location) time.Time {
+	t = t.In(loc)
+
+	switch interval {
+	case HOUR_INTERVAL:
+		// Going back from t keeps the right offset in the hour repeated when
+		// daylight saving time ends.
+		return t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	case WEEK_INTERVAL:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+	case MONTH_INTERVAL:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+// NextInterval returns the start of the bucket that follows the one starting
+// at start.
+func NextInterval(start time.Time, interval string) time.Time {
+	switch interval {
+	case HOUR_INTERVAL:
+		return start.Add(time.Hour)
+	case WEEK_INTERVAL:
+		return start.AddDate(0, 0, 7)
+	case MONTH_INTERVAL:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// UserMetricsBucket holds the metrics of the posts made in the bucket that
+// starts at Time.
+type UserMetricsBucket struct {
+	Time     time.Time `json:"time"`
+	Likes    int       `json:"likes"`
+	Retweets int       `json:"retweets"`
+	Posts    int       `json:"posts"`
 }
 
-type New_Content struct {
-	Hashtags []string `json:"hashtags"`
-	Timestamp string `json:"timestamp"`
-}
\ No newline at end of file
+type UserMetricsSeries struct {
+	Interval string              `json:"interval"`
+	Timezone string              `json:"timezone"`
+	Totals   UserMetrics         `json:"totals"`
+	Series   []UserMetricsBucket `json:"series"`
+}
+
+const (
+	ALL_VISIBILITY     = "all"
+	PUBLIC_VISIBILITY  = "public"
+	PRIVATE_VISIBILITY = "private"
+)
+
+// PlatformMetricsBucket holds the activity of the whole platform in the
+// bucket that starts at Time. Likes, bookmarks and blocked posts are those of
+// the posts created in the bucket; active authors posted or retweeted in it.
+type PlatformMetricsBucket struct {
+	Time           time.Time `json:"time"`
+	Posts          int       `json:"posts"`
+	Retweets       int       `json:"retweets"`
+	Likes          int       `json:"likes"`
+	Bookmarks      int       `json:"bookmarks"`
+	Blocked        int       `json:"blocked"`
+	Active_Authors int       `json:"active_authors"`
+	Media_Posts    int       `json:"media_posts"`
+	Mention_Posts  int       `json:"mention_posts"`
+	Media_Share    float64   `json:"media_share"`
+	Mention_Share  float64   `json:"mention_share"`
+}
+
+type PlatformMetrics struct {
+	Interval   string                  `json:"interval"`
+	Timezone   string                  `json:"timezone"`
+	Visibility string                  `json:"visibility"`
+	Series     []PlatformMetricsBucket `json:"series"`
+}
@@ -1,7 +1,13 @@
 package models
 
 type MentionNotificationRequest struct {
-	UserId   string `json:"user_id"`
-	TaggerId string `json:"tagger_id"`
-	PostId   string `json:"post_id"`
-}
\ No newline at end of file
+	UserId   string `json:"user_id" bson:"user_id"`
+	TaggerId string `json:"tagger_id" bson:"tagger_id"`
+	PostId   string `json:"post_id" bson:"post_id"`
+}
+type ReplyNotificationRequest struct {
+	UserId          string `json:"user_id" bson:"user_id"`
+	ReplierId       string `json:"replier_id" bson:"replier_id"`
+	PostId          string `json:"post_id" bson:"post_id"`
+	InReplyToPostId string `json:"in_reply_to_post_id" bson:"in_reply_to_post_id"`
+}
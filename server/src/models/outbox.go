@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OUTBOX_PENDING = "pending"
+	OUTBOX_FAILED  = "failed"
+
+	MENTION_NOTIFICATION = "mention_notification"
+	REPLY_NOTIFICATION   = "reply_notification"
+	EVENT                = "event"
+)
+
+// OutboxMessage is a notification or an event waiting to be delivered. It is
+// removed once delivered; messages that kept failing stay behind as failed.
+type OutboxMessage struct {
+	Message_ID   string                     `bson:"message_id" json:"message_id"`
+	Kind         string                     `bson:"kind" json:"kind"`
+	Mention      MentionNotificationRequest `bson:"mention" json:"mention"`
+	Reply        ReplyNotificationRequest   `bson:"reply" json:"reply"`
+	Event        json.RawMessage            `bson:"event,omitempty" json:"event,omitempty"`
+	Status       string                     `bson:"status" json:"status"`
+	Attempts     int                        `bson:"attempts" json:"attempts"`
+	Next_Attempt time.Time                  `bson:"next_attempt" json:"next_attempt"`
+	Last_Error   string                     `bson:"last_error" json:"last_error,omitempty"`
+	Created_At   time.Time                  `bson:"created_at" json:"created_at"`
+	// Claimed_By is the dispatcher holding the lease on the message, which
+	// lasts until Next_Attempt.
+	Claimed_By string `bson:"claimed_by" json:"claimed_by,omitempty"`
+}
+
+func NewMentionOutboxMessage(mention MentionNotificationRequest) OutboxMessage {
+	message := newOutboxMessage(MENTION_NOTIFICATION)
+	message.Mention = mention
+	return message
+}
+
+func NewReplyOutboxMessage(reply ReplyNotificationRequest) OutboxMessage {
+	message := newOutboxMessage(REPLY_NOTIFICATION)
+	message.Reply = reply
+	return message
+}
+
+func NewEventOutboxMessage(event QueueMessage) OutboxMessage {
+	message := newOutboxMessage(EVENT)
+	message.Event, _ = json.Marshal(event)
+	return message
+}
+
+func newOutboxMessage(kind string) OutboxMessage {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	return OutboxMessage{
+		Message_ID:   uuid.NewString(),
+		Kind:         kind,
+		Status:       OUTBOX_PENDING,
+		Next_Attempt: now,
+		Created_At:   now,
+	}
+}
+
+type OutboxSummary struct {
+	Pending  int             `json:"pending"`
+	Failed   int             `json:"failed"`
+	Messages []OutboxMessage `json:"messages"`
+}
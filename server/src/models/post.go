@@ -12,6 +12,9 @@ type AuthorInfo struct {
 	Username  string `json:"username"`
 	Alias     string `json:"alias"`
 	PthotoURL string `json:"photo_url"`
+	// Degraded is set when the users service could not be reached and only
+	// the id of the author is known.
+	Degraded bool `json:"degraded,omitempty"`
 }
 
 type MediaInfo struct {
@@ -26,6 +29,7 @@ type DBPost struct {
 	Time              time.Time `bson:"time"`
 	Public            bool      `bson:"public"`
 	Tags              []string  `bson:"tags"`
+	Tag_Keys          []string  `bson:"tag_keys"`
 	Likes             int       `bson:"likes"`
 	Retweets          int       `bson:"retweets"`
 	Is_Retweet        bool      `bson:"is_retweet"`
@@ -34,16 +38,36 @@ type DBPost struct {
 	Media_Info        MediaInfo    `bson:"media_info"`
 	Mentions 		[]string  `bson:"mentions"`
 	Blocked			 bool	  `bson:"blocked"`
+	// A post that replies to nothing starts its own conversation, whose ID is
+	// the post ID.
+	In_Reply_To_Post_ID string `bson:"in_reply_to_post_id"`
+	Conversation_ID     string `bson:"conversation_id"`
+	Replies             int    `bson:"replies"`
+	// A quote is a post of its own that embeds the original it quotes.
+	Quoted_Post_ID string `bson:"quoted_post_id"`
+	Quotes         int    `bson:"quotes"`
+	// Edits counts the revisions of the post, reverts included.
+	Edits int `bson:"edits"`
+	// Thread_Path places the post in the thread of its conversation. It is
+	// set by the database when the post is stored.
+	Thread_Path string `bson:"thread_path"`
+}
+
+// newPostID returns a time-ordered UUID, so posts created within the same
+// millisecond still sort newest first when listings break ties on the ID.
+func newPostID() string {
+	return uuid.Must(uuid.NewV7()).String()
 }
 
 func NewDBPost(author_id string, content string, tags []string, privacy bool, mediaInfo MediaInfo, mentions []string) DBPost {
-	postID := uuid.NewString()
+	postID := newPostID()
 	return DBPost{
 		Post_ID:           postID,
 		Content:           content,
 		Author_ID:         author_id,
 		Time:              time.Now().UTC(),
 		Tags:              tags,
+		Tag_Keys:          TagKeys(tags),
 		Public:            privacy,
 		Likes:             0,
 		Retweets:          0,
@@ -53,16 +77,18 @@ func NewDBPost(author_id string, content string, tags []string, privacy bool, me
 		Media_Info:        mediaInfo,
 		Mentions:  			mentions,
 		Blocked: 			false,
+		Conversation_ID:   postID,
 	}
 }
 
 func NewRetweetDBPost(post FrontPost, author_id string) DBPost {
 	return DBPost{
-		Post_ID:           uuid.NewString(),
+		Post_ID:           newPostID(),
 		Content:           post.Content,
 		Author_ID:         post.Author_Info.Author_ID,
 		Time:              time.Now().UTC(),
 		Tags:              post.Tags,
+		Tag_Keys:          TagKeys(post.Tags),
 		Public:            post.Public,
 		Likes:             post.Likes,
 		Retweets:          post.Retweets,
@@ -71,6 +97,12 @@ func NewRetweetDBPost(post FrontPost, author_id string) DBPost {
 		Is_Retweet:        true,
 		Media_Info:         post.Media_Info,
 		Mentions: 			post.Mentions,
+		In_Reply_To_Post_ID: post.In_Reply_To_Post_ID,
+		Conversation_ID:     post.Conversation_ID,
+		Replies:             post.Replies,
+		Quoted_Post_ID:      post.Quoted_Post_ID,
+		Quotes:              post.Quotes,
+		Edits:               post.Edits,
 	}
 }
 
@@ -92,6 +124,19 @@ type FrontPost struct {
 	Media_Info       MediaInfo  `json:"media_info"`
 	Bookmark		 bool       `json:"bookmark"`
 	Mentions 		[]string  	`json:"mentions"`
+	In_Reply_To_Post_ID string `json:"in_reply_to_post_id"`
+	Conversation_ID     string `json:"conversation_id"`
+	Replies             int    `json:"replies"`
+	Quoted_Post_ID      string `json:"quoted_post_id"`
+	// Quoted_Post is shown one level deep: what it quotes is only given by
+	// its Quoted_Post_ID.
+	Quoted_Post *FrontPost `json:"quoted_post,omitempty"`
+	Quotes      int        `json:"quotes"`
+	Edited      bool       `json:"edited"`
+	Edits       int        `json:"edits"`
+	// Tombstone stands in for a post of a conversation that was deleted,
+	// blocked or cannot be seen, so its replies keep their place.
+	Tombstone bool `json:"tombstone"`
 }
 
 func NewFrontPost(post DBPost, author AuthorInfo, liked bool, retweeted bool, bookmarked bool) FrontPost {
@@ -112,5 +157,33 @@ func NewFrontPost(post DBPost, author AuthorInfo, liked bool, retweeted bool, bo
 		Retweet_Author:   post.Retweet_Author_ID,
 		Bookmark:		  bookmarked,
 		Mentions: 		post.Mentions,
+		In_Reply_To_Post_ID: post.In_Reply_To_Post_ID,
+		Conversation_ID:     post.Conversation_ID,
+		Replies:             post.Replies,
+		Quoted_Post_ID:      post.Quoted_Post_ID,
+		Quotes:              post.Quotes,
+		Edited:              post.Edits > 0,
+		Edits:               post.Edits,
+	}
+}
+
+// NewTombstoneFrontPost keeps only where post sits in its conversation.
+func NewTombstoneFrontPost(post DBPost) FrontPost {
+	return TombstoneOf(NewFrontPost(post, AuthorInfo{}, false, false, false))
+}
+
+// TombstoneOf hides everything of post but where it sits in its
+// conversation.
+func TombstoneOf(post FrontPost) FrontPost {
+	return FrontPost{
+		Post_ID:             post.Post_ID,
+		Time:                post.Time,
+		Tags:                []string{},
+		Original_Post_ID:    post.Post_ID,
+		Mentions:            []string{},
+		In_Reply_To_Post_ID: post.In_Reply_To_Post_ID,
+		Conversation_ID:     post.Conversation_ID,
+		Replies:             post.Replies,
+		Tombstone:           true,
 	}
 }
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// PostVersion holds the editable fields of a post. In a revision only the
+// fields the edit changed are set.
+type PostVersion struct {
+	Content    *string    `bson:"content,omitempty" json:"content,omitempty"`
+	Public     *bool      `bson:"public,omitempty" json:"public,omitempty"`
+	Media_Info *MediaInfo `bson:"media_info,omitempty" json:"media_info,omitempty"`
+	Mentions   *[]string  `bson:"mentions,omitempty" json:"mentions,omitempty"`
+}
+
+// Revision is one edit of a post, numbered from 1 in the order they were
+// made, with the changed fields as they were before and after it.
+type Revision struct {
+	Post_ID   string      `bson:"post_id" json:"post_id"`
+	Number    int         `bson:"number" json:"number"`
+	Editor_ID string      `bson:"editor_id" json:"editor_id"`
+	Edited_At time.Time   `bson:"edited_at" json:"edited_at"`
+	Before    PostVersion `bson:"before" json:"before"`
+	After     PostVersion `bson:"after" json:"after"`
+	// Reverted_To is the revision a revert brought the post back to, 0 being
+	// the post as it was first written. Plain edits leave it nil.
+	Reverted_To *int `bson:"reverted_to,omitempty" json:"reverted_to,omitempty"`
+}
+
+// NewRevision diffs the editable fields of before and after. It returns false
+// when the edit changed nothing.
+func NewRevision(before DBPost, after DBPost, editorID string) (Revision, bool) {
+	revision := Revision{
+		Post_ID:   before.Post_ID,
+		Number:    before.Edits + 1,
+		Editor_ID: editorID,
+		Edited_At: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	changed := false
+
+	if before.Content != after.Content {
+		revision.Before.Content, revision.After.Content = &before.Content, &after.Content
+		changed = true
+	}
+
+	if before.Public != after.Public {
+		revision.Before.Public, revision.After.Public = &before.Public, &after.Public
+		changed = true
+	}
+
+	if before.Media_Info != after.Media_Info {
+		revision.Before.Media_Info, revision.After.Media_Info = &before.Media_Info, &after.Media_Info
+		changed = true
+	}
+
+	if !slices.Equal(before.Mentions, after.Mentions) {
+		beforeMentions, afterMentions := mentionsOrEmpty(before.Mentions), mentionsOrEmpty(after.Mentions)
+		revision.Before.Mentions, revision.After.Mentions = &beforeMentions, &afterMentions
+		changed = true
+	}
+
+	return revision, changed
+}
+
+func mentionsOrEmpty(mentions []string) []string {
+	return append([]string{}, mentions...)
+}
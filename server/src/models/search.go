@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	LATEST_SORT    = "latest"
+	RELEVANCE_SORT = "relevance"
+)
+
+// SearchQuery is a parsed search. A post matches when it has every phrase
+// or, without phrases, any of the terms, and passes every other filter that
+// is set: none of the excluded words, posted or retweeted by one of the
+// authors, every tag and mention, between Since and Until, with media and
+// being a retweet.
+type SearchQuery struct {
+	Terms     []string
+	Phrases   []string
+	Excluded  []string
+	Authors   []string
+	Tags      []string
+	Mentions  []string
+	Since     *time.Time
+	Until     *time.Time
+	HasMedia  bool
+	IsRetweet bool
+	Sort      string
+}
+
+// HasText tells whether the query searches for words, which is what the
+// relevance of a post is measured by.
+func (q SearchQuery) HasText() bool {
+	return len(q.Terms) > 0 || len(q.Phrases) > 0
+}
+
+// SearchTokens splits text into the lowercase words the search index keeps.
+// Anything that is not a letter or a digit separates words.
+func SearchTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ScoredTerms returns the distinct words of the terms and phrases, which are
+// the ones that weigh in the relevance of a post.
+func (q SearchQuery) ScoredTerms() []string {
+	terms := []string{}
+	seen := map[string]bool{}
+
+	for _, text := range append(append([]string{}, q.Terms...), q.Phrases...) {
+		for _, token := range SearchTokens(text) {
+			if !seen[token] {
+				seen[token] = true
+				terms = append(terms, token)
+			}
+		}
+	}
+
+	return terms
+}
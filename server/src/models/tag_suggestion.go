@@ -0,0 +1,44 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TagSuggestion is a hashtag that completes a typed prefix. Posts and
+// Last_Used count the visible posts with the tag; Score ranks it by how
+// recently and how much it is used.
+type TagSuggestion struct {
+	Tag       string    `bson:"tag" json:"tag"`
+	Posts     int       `bson:"posts" json:"posts"`
+	Last_Used time.Time `bson:"last_used" json:"last_used"`
+	Score     float64   `bson:"score" json:"score"`
+}
+
+// TagKey is how tag is compared when completing prefixes: lowercase and
+// without accents.
+func TagKey(tag string) string {
+	key := strings.Builder{}
+
+	for _, r := range norm.NFD.String(tag) {
+		if !unicode.Is(unicode.Mn, r) {
+			key.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return key.String()
+}
+
+// TagKeys is TagKey of every tag, in the same order.
+func TagKeys(tags []string) []string {
+	keys := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		keys = append(keys, TagKey(tag))
+	}
+
+	return keys
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+const (
+	TRENDING_HOUR_WINDOW = "1h"
+	TRENDING_DAY_WINDOW  = "24h"
+	TRENDING_WEEK_WINDOW = "7d"
+)
+
+// TrendingWindows are the windows trending topics are computed for, by name.
+var TrendingWindows = map[string]time.Duration{
+	TRENDING_HOUR_WINDOW: time.Hour,
+	TRENDING_DAY_WINDOW:  24 * time.Hour,
+	TRENDING_WEEK_WINDOW: 7 * 24 * time.Hour,
+}
+
+type TrendingTopic struct {
+	Tag   string  `bson:"tag" json:"tag"`
+	Score float64 `bson:"score" json:"score"`
+	Posts int     `bson:"posts" json:"posts"`
+}
+
+// TrendingSnapshot is the ranking of the topics of a window, best first, as
+// computed at Computed_At.
+type TrendingSnapshot struct {
+	Window      string          `bson:"window" json:"window"`
+	Computed_At time.Time       `bson:"computed_at" json:"computed_at"`
+	Topics      []TrendingTopic `bson:"topics" json:"topics"`
+}
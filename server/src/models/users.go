@@ -19,6 +19,7 @@ type PublicProfileExpectedFormat struct {
 
 type Pagination struct {
 	Next_Offset int `json:"next_offset,omitempty"`
+	Next_Cursor string `json:"next_cursor,omitempty"`
 	Limit       int `json:"limit"`
 }
 
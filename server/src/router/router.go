@@ -5,6 +5,7 @@ import (
 	"server/src/controller"
 	"server/src/database"
 	"server/src/middleware"
+	"server/src/service"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -12,7 +13,7 @@ import (
 	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
-func CreateRouter(db database.Database) *gin.Engine {
+func CreateRouter(db database.Database, users service.UsersClient, notifications service.NotificationClient) *gin.Engine {
 	r := gin.Default()
 
 	addCorsConfiguration(r)
@@ -21,16 +22,26 @@ func CreateRouter(db database.Database) *gin.Engine {
 	r.Use(middleware.ErrorManager())
 	r.Use(middleware.AuthMiddleware())
 
-	postController := controller.NewPostController(db)
+	postController := controller.NewPostController(db, users, notifications)
 
 	r.POST("/twitsnap", postController.NewPost)
 	
 	r.POST("/twitsnap/retweet/:id", postController.NewPostRetweet)
 
 	r.DELETE("/twitsnap/retweet/:id", postController.DeleteRetweet)
+
+	r.POST("/twitsnap/quote/:id", postController.NewQuote)
+
+	r.POST("/twitsnap/reply/:id", postController.NewReply)
+
+	r.GET("/twitsnap/conversation/:id", postController.GetConversation)
 	
 	r.PUT("/twitsnap/edit/:id", postController.UpdatePostByID)
 
+	r.GET("/twitsnap/revisions/:id", postController.GetRevisions)
+
+	r.POST("/twitsnap/revert/:id", postController.RevertPost)
+
 	r.GET("/twitsnap/feed", postController.GetUserFeed)
 
 	r.GET("/twitsnap/:id", postController.GetPostByID)
@@ -55,8 +66,22 @@ func CreateRouter(db database.Database) *gin.Engine {
 
 	r.GET("/twitsnap/metrics", postController.GetUserMetrics)
 
+	r.GET("/twitsnap/platform-metrics", postController.GetPlatformMetrics)
+
+	r.GET("/twitsnap/tag-metrics/:tag", postController.GetTagMetrics)
+
 	r.GET("/twitsnap/trending", postController.GetTrendingTopics)
 
+	r.GET("/twitsnap/trending-posts", postController.GetTrendingPosts)
+
+	r.GET("/twitsnap/tag-suggestions", postController.GetTagSuggestions)
+
+	r.DELETE("/twitsnap/following-cache/:id", postController.InvalidateFollowing)
+
+	r.GET("/twitsnap/cache-metrics", postController.GetCacheMetrics)
+
+	r.GET("/twitsnap/outbox", postController.GetOutbox)
+
 	r.POST("/twitsnap/block/:id", postController.BlockPost)
 
 	r.DELETE("/twitsnap/block/:id", postController.UnBlockPost)
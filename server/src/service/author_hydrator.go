@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_AUTHOR_CACHE_TTL      = 5 * time.Minute
+	DEFAULT_AUTHOR_CACHE_SIZE     = 10000
+	DEFAULT_AUTHOR_FETCH_PARALLEL = 8
+)
+
+// AuthorFetcher resolves the author info of a set of users. Implementations
+// backed by a bulk users endpoint can answer in one round trip; the default
+// one fetches each user on its own with bounded parallelism.
+type AuthorFetcher interface {
+	FetchAuthors(ctx context.Context, userIDs []string, token string) (map[string]models.AuthorInfo, error)
+}
+
+// AuthorFetcherFunc fetches a single user.
+type AuthorFetcherFunc func(ctx context.Context, userID string, token string) (models.AuthorInfo, error)
+
+// concurrentFetcher calls fetch for every user, at most parallel at a time.
+// The first failure cancels the fetches still in flight.
+type concurrentFetcher struct {
+	fetch    AuthorFetcherFunc
+	parallel int
+}
+
+// NewConcurrentAuthorFetcher wraps a single-user fetch into an AuthorFetcher
+// that runs at most parallel requests at the same time.
+func NewConcurrentAuthorFetcher(fetch AuthorFetcherFunc, parallel int) AuthorFetcher {
+	if parallel <= 0 {
+		parallel = 1
+	}
+	return &concurrentFetcher{fetch: fetch, parallel: parallel}
+}
+
+func (f *concurrentFetcher) FetchAuthors(ctx context.Context, userIDs []string, token string) (map[string]models.AuthorInfo, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+
+	authors := make(map[string]models.AuthorInfo, len(userIDs))
+	slots := make(chan struct{}, f.parallel)
+
+	for _, userID := range userIDs {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			author, err := f.fetch(ctx, userID, token)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			authors[userID] = author
+		}(userID)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return authors, nil
+}
+
+// AuthorHydrator fills in the author and retweet author of posts. Every page
+// asks for each user once, and users seen recently are served from a cache.
+type AuthorHydrator struct {
+	fetcher AuthorFetcher
+	cache   *ttlCache[models.AuthorInfo]
+}
+
+// NewAuthorHydrator returns a hydrator that keeps up to size authors for ttl.
+// A size or ttl of zero disables the cache.
+func NewAuthorHydrator(fetcher AuthorFetcher, ttl time.Duration, size int) *AuthorHydrator {
+	return &AuthorHydrator{fetcher: fetcher, cache: newTTLCache[models.AuthorInfo](ttl, size)}
+}
+
+// newDefaultAuthorHydrator reads the cache and parallelism settings from
+// AUTHOR_CACHE_TTL, AUTHOR_CACHE_SIZE and AUTHOR_FETCH_PARALLEL.
+func newDefaultAuthorHydrator(users UsersClient) *AuthorHydrator {
+	ttl := envDuration("AUTHOR_CACHE_TTL", DEFAULT_AUTHOR_CACHE_TTL)
+	size := envInt("AUTHOR_CACHE_SIZE", DEFAULT_AUTHOR_CACHE_SIZE)
+	parallel := envInt("AUTHOR_FETCH_PARALLEL", DEFAULT_AUTHOR_FETCH_PARALLEL)
+
+	return NewAuthorHydrator(NewConcurrentAuthorFetcher(degradeWhenUnavailable(users.GetAuthorInfo), parallel), ttl, size)
+}
+
+// degradeWhenUnavailable answers with only the id of the user, marked as
+// degraded, while the circuit breaker of the users service is open, so posts
+// can still be shown.
+func degradeWhenUnavailable(fetch AuthorFetcherFunc) AuthorFetcherFunc {
+	return func(ctx context.Context, userID string, token string) (models.AuthorInfo, error) {
+		author, err := fetch(ctx, userID, token)
+		if errors.Is(err, ErrCircuitOpen) {
+			return models.AuthorInfo{Author_ID: userID, Degraded: true}, nil
+		}
+		return author, err
+	}
+}
+
+// HydratePost fills in the author info of a single post.
+func (h *AuthorHydrator) HydratePost(ctx context.Context, post models.FrontPost, token string) (models.FrontPost, error) {
+	posts, err := h.Hydrate(ctx, []models.FrontPost{post}, token)
+	if err != nil {
+		return models.FrontPost{}, err
+	}
+	return posts[0], nil
+}
+
+// Hydrate fills in the author info of every post and of the posts they quote,
+// and replaces the retweet author id of retweets with its username.
+// Tombstones have no author.
+func (h *AuthorHydrator) Hydrate(ctx context.Context, posts []models.FrontPost, token string) ([]models.FrontPost, error) {
+	authors := map[string]models.AuthorInfo{}
+	missing := []string{}
+
+	for _, userID := range authorIDs(posts) {
+		if author, ok := h.cache.get(userID); ok {
+			authors[userID] = author
+		} else {
+			missing = append(missing, userID)
+		}
+	}
+
+	if len(missing) > 0 {
+		fetched, err := h.fetcher.FetchAuthors(ctx, missing, token)
+		if err != nil {
+			return nil, postErrors.UserInfoError("error getting info on the user, " + err.Error())
+		}
+
+		for _, userID := range missing {
+			author, ok := fetched[userID]
+			if !ok {
+				return nil, postErrors.UserInfoError("error getting info on the user, no info for user " + userID)
+			}
+			if !author.Degraded {
+				h.cache.add(userID, author)
+			}
+			authors[userID] = author
+		}
+	}
+
+	for i, post := range posts {
+		if post.Tombstone {
+			continue
+		}
+
+		post.Author_Info = authors[post.Author_Info.Author_ID]
+
+		if post.Is_Retweet {
+			post.Retweet_Author = authors[post.Retweet_Author].Username
+		} else {
+			post.Retweet_Author = ""
+		}
+
+		if quoted := post.Quoted_Post; quoted != nil && !quoted.Tombstone {
+			hydrated := *quoted
+			hydrated.Author_Info = authors[quoted.Author_Info.Author_ID]
+			hydrated.Retweet_Author = ""
+			post.Quoted_Post = &hydrated
+		}
+
+		posts[i] = post
+	}
+
+	return posts, nil
+}
+
+// authorIDs returns the users a page needs, each once.
+func authorIDs(posts []models.FrontPost) []string {
+	seen := map[string]bool{}
+	ids := []string{}
+
+	add := func(userID string) {
+		if !seen[userID] {
+			seen[userID] = true
+			ids = append(ids, userID)
+		}
+	}
+
+	for _, post := range posts {
+		if post.Tombstone {
+			continue
+		}
+
+		add(post.Author_Info.Author_ID)
+		if post.Is_Retweet {
+			add(post.Retweet_Author)
+		}
+		if quoted := post.Quoted_Post; quoted != nil && !quoted.Tombstone {
+			add(quoted.Author_Info.Author_ID)
+		}
+	}
+
+	return ids
+}
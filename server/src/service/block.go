@@ -1,16 +1,20 @@
 package service
 
 import (
+	"context"
 	"log/slog"
-	postErrors "server/src/all_errors"
 	"time"
 )
 
-func (c *Service) BlockPost(postID string) error {
-	err := c.db.BlockPost(postID)
+func (c *Service) BlockPost(ctx context.Context, postID string, session Session) error {
+	if err := Authorize(session, BLOCK_ACTION, ""); err != nil {
+		return err
+	}
+
+	err := c.db.BlockPost(ctx, postID)
 
 	if err != nil {
-		return postErrors.TwitsnapNotFound(postID)
+		return postError(postID, err)
 	}
 
 	slog.Info("Post blocked: ", "post_id", postID, "time", time.Now())
@@ -18,11 +22,15 @@ func (c *Service) BlockPost(postID string) error {
 	return nil
 }
 
-func (c *Service) UnBlockPost(postID string) error {
-	err := c.db.UnBlockPost(postID)
+func (c *Service) UnBlockPost(ctx context.Context, postID string, session Session) error {
+	if err := Authorize(session, BLOCK_ACTION, ""); err != nil {
+		return err
+	}
+
+	err := c.db.UnBlockPost(ctx, postID)
 
 	if err != nil {
-		return postErrors.TwitsnapNotFound(postID)
+		return postError(postID, err)
 	}
 
 	slog.Info("Post unblocked: ", "post_id", postID, "time", time.Now())
@@ -1,14 +1,15 @@
 package service
 
 import (
+	"context"
 	"log/slog"
 	postErrors "server/src/all_errors"
 	"server/src/models"
 	"time"
 )
 
-func (c *Service) BookmarkPost(postID string, userID string) error {
-	err := c.db.AddFavorite(postID, userID)
+func (c *Service) BookmarkPost(ctx context.Context, postID string, userID string) error {
+	err := c.db.AddFavorite(ctx, postID, userID)
 
 	if err != nil {
 		return postErrors.TwitsnapNotFound(postID)
@@ -19,8 +20,8 @@ func (c *Service) BookmarkPost(postID string, userID string) error {
 	return nil
 }
 
-func (c *Service) UnBookmarkPost(postID string, userID string) error {
-	err := c.db.RemoveFavorite(postID, userID)
+func (c *Service) UnBookmarkPost(ctx context.Context, postID string, userID string) error {
+	err := c.db.RemoveFavorite(ctx, postID, userID)
 
 	if err != nil {
 		return postErrors.TwitsnapNotFound(postID)
@@ -31,14 +32,24 @@ func (c *Service) UnBookmarkPost(postID string, userID string) error {
 	return nil
 }
 
-func (c *Service) GetUserFavorites(userID string, limitiConfig models.LimitConfig, token string) ([]models.FrontPost, bool, error) {
-	bookmarks, hasMore, err := c.db.GetUserFavorites(userID, limitiConfig)
+// GetUserFavorites lists the bookmarks of userID, or of the session user when
+// it is empty.
+func (c *Service) GetUserFavorites(ctx context.Context, userID string, limitiConfig models.LimitConfig, session Session, token string) ([]models.FrontPost, bool, error) {
+	if userID == "" {
+		userID = session.UserID
+	}
+
+	if err := Authorize(session, VIEW_BOOKMARKS_ACTION, userID); err != nil {
+		return []models.FrontPost{}, false, err
+	}
+
+	bookmarks, hasMore, err := c.db.GetUserFavorites(ctx, userID, limitiConfig)
 
 	if err != nil {
 		return []models.FrontPost{}, false, err
 	}
 
-	posts, err := addAuthorInfoToPosts(bookmarks, token)
+	posts, err := c.presentPosts(ctx, bookmarks, userID, token)
 
 	if err != nil {
 		return []models.FrontPost{}, false, err
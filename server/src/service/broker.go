@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"server/src/models"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	DEFAULT_EVENTS_EXCHANGE = "twitsnap.events"
+	EVENT_CONTENT_TYPE      = "application/json"
+)
+
+// ErrBrokerNack is returned when the broker refuses to take a message.
+var ErrBrokerNack = errors.New("broker did not accept the message")
+
+// BrokerChannel publishes a message to an exchange and returns once the
+// broker has taken responsibility for it.
+type BrokerChannel interface {
+	Publish(ctx context.Context, exchange string, routingKey string, messageID string, body []byte) error
+}
+
+// BrokerPublisher publishes the events to a topic exchange, with the event
+// type as routing key.
+type BrokerPublisher struct {
+	channel  BrokerChannel
+	exchange string
+}
+
+func NewBrokerPublisher(channel BrokerChannel, exchange string) *BrokerPublisher {
+	return &BrokerPublisher{channel: channel, exchange: exchange}
+}
+
+func (p *BrokerPublisher) Publish(ctx context.Context, event models.QueueMessage) error {
+	body, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	if err := p.channel.Publish(ctx, p.exchange, event.MessageType, event.MessageID, body); err != nil {
+		return err
+	}
+
+	slog.Info("Event published: ", "message_id", event.MessageID, "message_type", event.MessageType, "exchange", p.exchange)
+
+	return nil
+}
+
+// AMQPChannel publishes to an AMQP 0-9-1 broker such as RabbitMQ. It
+// connects on first use and again after the connection drops. Messages are
+// persistent and every publish waits for the broker confirm.
+type AMQPChannel struct {
+	url string
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	declared map[string]bool
+}
+
+func NewAMQPChannel(url string) *AMQPChannel {
+	return &AMQPChannel{url: url}
+}
+
+func (a *AMQPChannel) Publish(ctx context.Context, exchange string, routingKey string, messageID string, body []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	if !a.declared[exchange] {
+		if err := a.channel.ExchangeDeclare(exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+			a.close()
+			return err
+		}
+		a.declared[exchange] = true
+	}
+
+	confirmation, err := a.channel.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, false, false, amqp.Publishing{
+		ContentType:  EVENT_CONTENT_TYPE,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    messageID,
+		Timestamp:    time.Now().UTC(),
+		Body:         body,
+	})
+
+	if err != nil {
+		a.close()
+		return err
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if !acked {
+		return ErrBrokerNack
+	}
+
+	return nil
+}
+
+func (a *AMQPChannel) connect() error {
+	if a.channel != nil && !a.channel.IsClosed() {
+		return nil
+	}
+
+	a.close()
+
+	conn, err := amqp.Dial(a.url)
+
+	if err != nil {
+		return err
+	}
+
+	channel, err := conn.Channel()
+
+	if err == nil {
+		err = channel.Confirm(false)
+	}
+
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	a.conn = conn
+	a.channel = channel
+	a.declared = map[string]bool{}
+
+	return nil
+}
+
+func (a *AMQPChannel) close() {
+	if a.conn != nil {
+		_ = a.conn.Close()
+	}
+	a.conn = nil
+	a.channel = nil
+}
+
+// Close closes the connection to the broker.
+func (a *AMQPChannel) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.close()
+
+	return nil
+}
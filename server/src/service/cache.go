@@ -0,0 +1,189 @@
+package service
+
+import (
+	"container/list"
+	"log/slog"
+	"os"
+	"server/src/models"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type cacheEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// ttlCache is a least recently used cache whose entries expire after ttl.
+// A ttl or size of zero disables it.
+type ttlCache[V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	// removedAt keeps when each key was last removed, for ttl, so a value
+	// fetched before that is not cached.
+	removedAt map[string]time.Time
+	hits      atomic.Int64
+	misses    atomic.Int64
+}
+
+func newTTLCache[V any](ttl time.Duration, size int) *ttlCache[V] {
+	return &ttlCache[V]{ttl: ttl, size: size, order: list.New(), entries: map[string]*list.Element{}, removedAt: map[string]time.Time{}}
+}
+
+func (c *ttlCache[V]) enabled() bool {
+	return c.ttl > 0 && c.size > 0
+}
+
+func (c *ttlCache[V]) get(key string) (V, bool) {
+	var zero V
+
+	if !c.enabled() {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses.Add(1)
+		return zero, false
+	}
+
+	entry := element.Value.(*cacheEntry[V])
+
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		c.misses.Add(1)
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+	c.hits.Add(1)
+
+	return entry.value, true
+}
+
+func (c *ttlCache[V]) add(key string, value V) {
+	if !c.enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.store(key, value)
+}
+
+// addFetched adds value, fetched since fetchedAt, unless key was removed
+// meanwhile: the value may be older than what the removal announced.
+func (c *ttlCache[V]) addFetched(key string, value V, fetchedAt time.Time) {
+	if !c.enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if removedAt, ok := c.removedAt[key]; ok && !removedAt.Before(fetchedAt) {
+		return
+	}
+
+	c.store(key, value)
+}
+
+// store adds value under key, evicting the least recently used entries past
+// size. c.mu has to be held.
+func (c *ttlCache[V]) store(key string, value V) {
+	expiresAt := time.Now().Add(c.ttl)
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry[V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry[V]{key: key, value: value, expiresAt: expiresAt})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry[V]).key)
+	}
+}
+
+func (c *ttlCache[V]) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+
+	now := time.Now()
+
+	// No fetch lasts as long as the ttl, so older removals can be forgotten.
+	for removed, removedAt := range c.removedAt {
+		if now.Sub(removedAt) > c.ttl {
+			delete(c.removedAt, removed)
+		}
+	}
+
+	if c.enabled() {
+		c.removedAt[key] = now
+	}
+}
+
+func (c *ttlCache[V]) metrics() models.CacheMetrics {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+
+	metrics := models.CacheMetrics{Hits: c.hits.Load(), Misses: c.misses.Load(), Entries: entries}
+
+	if lookups := metrics.Hits + metrics.Misses; lookups > 0 {
+		metrics.Hit_Rate = float64(metrics.Hits) / float64(lookups)
+	}
+
+	return metrics
+}
+
+func envInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		slog.Warn("Invalid value, using default", "key", key, "value", value, "default", def)
+		return def
+	}
+
+	return parsed
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		slog.Warn("Invalid duration, using default", "key", key, "value", value, "default", def)
+		return def
+	}
+
+	return parsed
+}
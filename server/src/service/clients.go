@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"server/src/models"
+)
+
+// ErrUnknownUser is returned when there is no user with the username asked for.
+var ErrUnknownUser = errors.New("unknown user")
+
+// UsersClient is what the service needs from the users service.
+type UsersClient interface {
+	// GetFollowing returns the ids of all the users userID follows.
+	GetFollowing(ctx context.Context, userID string, token string) ([]string, error)
+	// GetAuthorInfo returns the public profile of userID as shown on posts.
+	GetAuthorInfo(ctx context.Context, userID string, token string) (models.AuthorInfo, error)
+	// GetInterests returns the tags userID is interested in.
+	GetInterests(ctx context.Context, userID string, token string) ([]string, error)
+	// GetUserID returns the id of the user with username, or ErrUnknownUser.
+	GetUserID(ctx context.Context, username string, token string) (string, error)
+}
+
+// NotificationClient is what the service needs from the notifications service.
+type NotificationClient interface {
+	SendMention(ctx context.Context, notification models.MentionNotificationRequest, token string) error
+	SendReply(ctx context.Context, notification models.ReplyNotificationRequest, token string) error
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"server/src/models"
+	"sync"
+)
+
+// EventPublisher hands the events to whoever listens to them. Events are
+// written to the outbox first and published by the outbox dispatcher, so a
+// publisher may see the same event more than once.
+type EventPublisher interface {
+	Publish(ctx context.Context, event models.QueueMessage) error
+}
+
+// InProcessPublisher calls its subscribers in the same process. It is used
+// when no broker is configured.
+type InProcessPublisher struct {
+	mu          sync.RWMutex
+	subscribers []func(ctx context.Context, event models.QueueMessage) error
+}
+
+func NewInProcessPublisher() *InProcessPublisher {
+	return &InProcessPublisher{}
+}
+
+// Subscribe adds a handler for every published event. A handler error fails
+// the publish, so the event is retried.
+func (p *InProcessPublisher) Subscribe(handler func(ctx context.Context, event models.QueueMessage) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subscribers = append(p.subscribers, handler)
+}
+
+func (p *InProcessPublisher) Publish(ctx context.Context, event models.QueueMessage) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, subscriber := range p.subscribers {
+		if err := subscriber(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Event published: ", "message_id", event.MessageID, "message_type", event.MessageType)
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"server/src/models"
+	"slices"
+	"sync"
+)
+
+// FakeUsersClient answers from memory. NewFakeUsersClient seeds it with the
+// test users; tests can change what any user sees or make a user fail.
+type FakeUsersClient struct {
+	mu               sync.Mutex
+	defaultFollowing []string
+	defaultInterests []string
+	following        map[string][]string
+	interests        map[string][]string
+	authors          map[string]models.AuthorInfo
+	failures         map[string]error
+}
+
+func NewFakeUsersClient() *FakeUsersClient {
+	fake := &FakeUsersClient{
+		defaultFollowing: []string{TEST_USER_ONE, TEST_USER_TWO, TEST_USER_THREE},
+		defaultInterests: []string{TEST_TAG_ONE, TEST_TAG_TWO, TEST_TAG_THREE},
+		following:        map[string][]string{},
+		interests:        map[string][]string{TEST_USER_NO_TAGS: {}},
+		authors:          map[string]models.AuthorInfo{},
+		failures:         map[string]error{},
+	}
+
+	fake.SetUsername(TEST_USER_ONE, TEST_USER_ONE_USERNAME)
+	fake.SetUsername(TEST_USER_TWO, TEST_USER_TWO_USERNAME)
+	fake.SetUsername(TEST_USER_THREE, TEST_USER_THREE_USERNAME)
+	fake.SetUsername(TEST_NOT_FOLLOWING_ID, TEST_NOT_FOLLOWING_USERNAME)
+
+	return fake
+}
+
+// SetFollowing replaces the users userID follows. Users without their own
+// list follow the test users one, two and three.
+func (f *FakeUsersClient) SetFollowing(userID string, following []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.following[userID] = slices.Clone(following)
+}
+
+// SetInterests replaces the interests of userID. Users without their own
+// interests have the three test tags.
+func (f *FakeUsersClient) SetInterests(userID string, interests []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.interests[userID] = slices.Clone(interests)
+}
+
+// SetAuthor replaces the author info returned for author.Author_ID.
+func (f *FakeUsersClient) SetAuthor(author models.AuthorInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.authors[author.Author_ID] = author
+}
+
+// SetUsername is SetAuthor for a test user with the default alias.
+func (f *FakeUsersClient) SetUsername(userID string, username string) {
+	f.SetAuthor(models.AuthorInfo{Author_ID: userID, Username: username, Alias: "alias", PthotoURL: ""})
+}
+
+// Fail makes every call about userID return err. A nil err clears it.
+func (f *FakeUsersClient) Fail(userID string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.failures, userID)
+		return
+	}
+	f.failures[userID] = err
+}
+
+func (f *FakeUsersClient) GetFollowing(ctx context.Context, userID string, token string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.failures[userID]; err != nil {
+		return nil, err
+	}
+	if following, ok := f.following[userID]; ok {
+		return slices.Clone(following), nil
+	}
+	return slices.Clone(f.defaultFollowing), nil
+}
+
+func (f *FakeUsersClient) GetAuthorInfo(ctx context.Context, userID string, token string) (models.AuthorInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.failures[userID]; err != nil {
+		return models.AuthorInfo{}, err
+	}
+	if author, ok := f.authors[userID]; ok {
+		return author, nil
+	}
+	return models.AuthorInfo{Author_ID: userID, Username: "", Alias: "alias", PthotoURL: ""}, nil
+}
+
+func (f *FakeUsersClient) GetInterests(ctx context.Context, userID string, token string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.failures[userID]; err != nil {
+		return nil, err
+	}
+	if interests, ok := f.interests[userID]; ok {
+		return slices.Clone(interests), nil
+	}
+	return slices.Clone(f.defaultInterests), nil
+}
+
+func (f *FakeUsersClient) GetUserID(ctx context.Context, username string, token string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, author := range f.authors {
+		if author.Username == username {
+			if err := f.failures[author.Author_ID]; err != nil {
+				return "", err
+			}
+			return author.Author_ID, nil
+		}
+	}
+	return "", ErrUnknownUser
+}
+
+// FakeNotificationClient records the notifications instead of sending them.
+type FakeNotificationClient struct {
+	mu      sync.Mutex
+	sent    []models.MentionNotificationRequest
+	replies []models.ReplyNotificationRequest
+	err     error
+}
+
+func NewFakeNotificationClient() *FakeNotificationClient {
+	return &FakeNotificationClient{}
+}
+
+// Fail makes every following send return err. A nil err clears it.
+func (f *FakeNotificationClient) Fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// Sent returns the notifications sent so far, oldest first.
+func (f *FakeNotificationClient) Sent() []models.MentionNotificationRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.sent)
+}
+
+// Replies returns the reply notifications sent so far, oldest first.
+func (f *FakeNotificationClient) Replies() []models.ReplyNotificationRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.replies)
+}
+
+func (f *FakeNotificationClient) SendMention(ctx context.Context, notification models.MentionNotificationRequest, token string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+
+	f.sent = append(f.sent, notification)
+	slog.Info("Notification sent to ", "user_id", notification.UserId)
+
+	return nil
+}
+
+func (f *FakeNotificationClient) SendReply(ctx context.Context, notification models.ReplyNotificationRequest, token string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+
+	f.replies = append(f.replies, notification)
+	slog.Info("Reply notification sent to ", "user_id", notification.UserId)
+
+	return nil
+}
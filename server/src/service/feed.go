@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"log/slog"
 	postErrors "server/src/all_errors"
 	"server/src/models"
@@ -8,26 +9,28 @@ import (
 )
 
 
-func (c *Service) FetchUserFeed(feedRequest *models.FeedRequesst, user_id string, limitConfig models.LimitConfig, token string) ([]models.FrontPost, bool, error) {
+func (c *Service) FetchUserFeed(ctx context.Context, feedRequest *models.FeedRequesst, user_id string, limitConfig models.LimitConfig, token string) ([]models.FrontPost, bool, error) {
 	switch feedRequest.FeedType {
 	case FOLLOWING:
-		return c.fetchFollowingFeed(limitConfig, user_id, token)
+		return c.fetchFollowingFeed(ctx, limitConfig, user_id, token)
 	case FORYOU:
-		return c.fetchForyouFeed(limitConfig, user_id, token)
+		return c.fetchForyouFeed(ctx, limitConfig, user_id, token)
 	case SINGLE:
-		return c.fetchForyouSingle(limitConfig, feedRequest.WantedUserID, user_id, token)
+		return c.fetchForyouSingle(ctx, limitConfig, feedRequest.WantedUserID, user_id, token)
 	case RETWEET:
-		return c.fetchRetweetFeed(limitConfig, feedRequest.WantedUserID, user_id, token)
+		return c.fetchRetweetFeed(ctx, limitConfig, feedRequest.WantedUserID, user_id, token)
+	case MENTIONS:
+		return c.fetchMentionsFeed(ctx, limitConfig, user_id, token)
 	}
 	return []models.FrontPost{}, false, postErrors.BadFeedRequest(feedRequest.FeedType)
 }
 
-func (c *Service) fetchFollowingFeed(limitConfig models.LimitConfig, userID string, token string) ([]models.FrontPost, bool, error) {
-	following, err := getUserFollowingWp(userID, limitConfig, token)
+func (c *Service) fetchFollowingFeed(ctx context.Context, limitConfig models.LimitConfig, userID string, token string) ([]models.FrontPost, bool, error) {
+	following, err := c.getFollowing(ctx, userID, token)
 	if err != nil {
 		return []models.FrontPost{}, false, err
 	}
-	posts, hasMore, err := c.db.GetUserFeedFollowing(following, userID, limitConfig)
+	posts, hasMore, err := c.db.GetUserFeedFollowing(ctx, following, userID, limitConfig)
 
 	if err != nil {
 		return []models.FrontPost{}, false, err
@@ -37,24 +40,24 @@ func (c *Service) fetchFollowingFeed(limitConfig models.LimitConfig, userID stri
 		return []models.FrontPost{}, false, nil
 	}
 
-	posts, err = addAuthorInfoToPosts(posts, token)
+	posts, err = c.presentPosts(ctx, posts, userID, token)
 
 	slog.Info("Following feed retrieved: ", "user_id", userID, "time", time.Now(), "count", len(posts))
 	return posts, hasMore, err
 }
 
-func (c *Service) fetchForyouFeed(limitConfig models.LimitConfig, userID string, token string) ([]models.FrontPost, bool, error) {
+func (c *Service) fetchForyouFeed(ctx context.Context, limitConfig models.LimitConfig, userID string, token string) ([]models.FrontPost, bool, error) {
 
-	interests, err := getUserInterestsWp(userID, token)
+	interests, err := c.users.GetInterests(ctx, userID, token)
 	if err != nil {
-		return []models.FrontPost{}, false, err
+		return []models.FrontPost{}, false, usersServiceError(err)
 	}
 
-	following, err := getUserFollowingWp(userID, limitConfig, token)
+	following, err := c.getFollowing(ctx, userID, token)
 	if err != nil {
 		return []models.FrontPost{}, false, err
 	}
-	posts, hasMore, err := c.db.GetUserFeedInterests(interests, following, userID, limitConfig)
+	posts, hasMore, err := c.db.GetUserFeedInterests(ctx, interests, following, userID, limitConfig)
 
 	if err != nil {
 		return []models.FrontPost{}, false, err
@@ -64,15 +67,15 @@ func (c *Service) fetchForyouFeed(limitConfig models.LimitConfig, userID string,
 		return []models.FrontPost{}, false, nil
 	}
 
-	posts, err = addAuthorInfoToPosts(posts, token)
+	posts, err = c.presentPosts(ctx, posts, userID, token)
 
 	slog.Info("Foryou feed retrieved: ", "user_id", userID, "time", time.Now(), "count", len(posts))
 	return posts, hasMore, err
 }
 
-func (c *Service) fetchForyouSingle(limitConfig models.LimitConfig, wantedUserID string, userID string, token string) ([]models.FrontPost, bool, error) {
+func (c *Service) fetchForyouSingle(ctx context.Context, limitConfig models.LimitConfig, wantedUserID string, userID string, token string) ([]models.FrontPost, bool, error) {
 
-	following, err := getUserFollowingWp(userID, limitConfig, token)
+	following, err := c.getFollowing(ctx, userID, token)
 	if err != nil {
 		return []models.FrontPost{}, false, err
 	}
@@ -81,7 +84,7 @@ func (c *Service) fetchForyouSingle(limitConfig models.LimitConfig, wantedUserID
 		following = append(following, userID)
 	}
 
-	posts, hasMore, err := c.db.GetUserFeedSingle(wantedUserID, limitConfig, userID, following)
+	posts, hasMore, err := c.db.GetUserFeedSingle(ctx, wantedUserID, limitConfig, userID, following)
 	if err != nil {
 		return []models.FrontPost{}, false, err
 	}
@@ -90,20 +93,20 @@ func (c *Service) fetchForyouSingle(limitConfig models.LimitConfig, wantedUserID
 		return []models.FrontPost{}, false, nil
 	}
 
-	posts, err = addAuthorInfoToPosts(posts, token)
+	posts, err = c.presentPosts(ctx, posts, userID, token)
 
 	slog.Info("Single feed retrieved: ", "user_id", userID, "time", time.Now(), "count", len(posts))
 	return posts, hasMore, err
 }
 
-func (c *Service) fetchRetweetFeed(limitConfig models.LimitConfig, wantedUserID string, userID string, token string) ([]models.FrontPost, bool, error) {
+func (c *Service) fetchRetweetFeed(ctx context.Context, limitConfig models.LimitConfig, wantedUserID string, userID string, token string) ([]models.FrontPost, bool, error) {
 
-	following, err := getUserFollowingWp(userID, limitConfig, token)
+	following, err := c.getFollowing(ctx, userID, token)
 	if err != nil {
 		return []models.FrontPost{}, false, err
 	}
 
-	posts, hasMore, err := c.db.GetUserFeedRetweet(wantedUserID, limitConfig, userID, following)
+	posts, hasMore, err := c.db.GetUserFeedRetweet(ctx, wantedUserID, limitConfig, userID, following)
 
 	if err != nil {
 		return []models.FrontPost{}, false, err
@@ -113,8 +116,31 @@ func (c *Service) fetchRetweetFeed(limitConfig models.LimitConfig, wantedUserID
 		return []models.FrontPost{}, false, nil
 	}
 
-	posts, err = addAuthorInfoToPosts(posts, token)
+	posts, err = c.presentPosts(ctx, posts, userID, token)
 
 	slog.Info("Retweet feed retrieved: ", "user_id", userID, "time", time.Now(), "count", len(posts))
 	return posts, hasMore, err
-}
\ No newline at end of file
+}
+
+func (c *Service) fetchMentionsFeed(ctx context.Context, limitConfig models.LimitConfig, userID string, token string) ([]models.FrontPost, bool, error) {
+
+	following, err := c.getFollowing(ctx, userID, token)
+	if err != nil {
+		return []models.FrontPost{}, false, err
+	}
+
+	posts, hasMore, err := c.db.GetUserFeedMentions(ctx, following, userID, limitConfig)
+
+	if err != nil {
+		return []models.FrontPost{}, false, err
+	}
+
+	if len(posts) == 0 {
+		return []models.FrontPost{}, false, nil
+	}
+
+	posts, err = c.presentPosts(ctx, posts, userID, token)
+
+	slog.Info("Mentions feed retrieved: ", "user_id", userID, "time", time.Now(), "count", len(posts))
+	return posts, hasMore, err
+}
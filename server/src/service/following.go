@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"slices"
+	"time"
+)
+
+const (
+	DEFAULT_FOLLOWING_CACHE_TTL  = time.Minute
+	DEFAULT_FOLLOWING_CACHE_SIZE = 10000
+)
+
+// getFollowing returns the users userID follows, from the cache when it is
+// fresh. Callers get their own copy and may append to it.
+func (c *Service) getFollowing(ctx context.Context, userID string, token string) ([]string, error) {
+	if following, ok := c.following.get(userID); ok {
+		return slices.Clone(following), nil
+	}
+
+	fetchedAt := time.Now()
+	following, err := c.users.GetFollowing(ctx, userID, token)
+
+	if err != nil {
+		return nil, usersServiceError(err)
+	}
+
+	// An invalidation that came during the fetch may be newer than the list.
+	c.following.addFetched(userID, slices.Clone(following), fetchedAt)
+
+	return following, nil
+}
+
+// InvalidateFollowing drops the cached following list of userID, so the next
+// request asks the users service again. A list being fetched meanwhile is not
+// cached either. It is called when the user follows or unfollows someone, by
+// the users service with a token it signs with the shared JWT secret and
+// user_admin set.
+func (c *Service) InvalidateFollowing(userID string, session Session) error {
+	if err := Authorize(session, INVALIDATE_ACTION, ""); err != nil {
+		return err
+	}
+
+	c.following.remove(userID)
+
+	slog.Info("Following cache invalidated: ", "user_id", userID)
+
+	return nil
+}
+
+func (c *Service) GetCacheMetrics() models.ServiceCacheMetrics {
+	return models.ServiceCacheMetrics{Following: c.following.metrics(), Authors: c.authors.cache.metrics()}
+}
+
+// usersServiceError turns a failed call to the users service into the error
+// answered to the client.
+func usersServiceError(err error) error {
+	if errors.Is(err, ErrCircuitOpen) {
+		return postErrors.DependencyUnavailable(USERS_DEPENDENCY)
+	}
+	return postErrors.UserInfoError(err.Error())
+}
@@ -1,21 +1,22 @@
 package service
 
 import (
+	"context"
 	"log/slog"
 	postErrors "server/src/all_errors"
 	"server/src/models"
 	"time"
 )
 
-func (c *Service) FetchPostByID(postID string, token string, userID string) (*models.FrontPost, error) {
+func (c *Service) FetchPostByID(ctx context.Context, postID string, token string, userID string) (*models.FrontPost, error) {
 
-	post, err := c.db.GetPost(postID, userID)
+	post, err := c.db.GetPost(ctx, postID, userID)
 
 	if err != nil {
 		return nil, postErrors.TwitsnapNotFound(postID)
 	}
 
-	post, err = addAuthorInfoToPost(post, token)
+	post, err = c.presentPost(ctx, post, userID, token)
 
 	if err != nil {
 		return nil, postErrors.UserInfoError(err.Error())
@@ -26,11 +27,15 @@ func (c *Service) FetchPostByID(postID string, token string, userID string) (*mo
 	return &post, nil
 }
 
-func (c *Service) RemovePostByID(postID string) error {
-	err := c.db.DeletePost(postID)
+func (c *Service) RemovePostByID(ctx context.Context, postID string, session Session) error {
+	if err := c.authorizePost(ctx, session, DELETE_ACTION, postID); err != nil {
+		return err
+	}
+
+	err := c.db.DeletePost(ctx, postID)
 
 	if err != nil {
-		return postErrors.TwitsnapNotFound(postID)
+		return postError(postID, err)
 	}
 
 	slog.Info("Post removed: ", "post_id", postID, "time", time.Now())
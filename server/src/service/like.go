@@ -1,19 +1,24 @@
 package service
 
 import (
+	"context"
 	"log/slog"
 	"time"
 	postErrors "server/src/all_errors"
 )
 
-func (c *Service) LikePost(postID string, userID string) error {
-	err := c.db.LikeAPost(postID, userID)
+func (c *Service) LikePost(ctx context.Context, postID string, userID string) error {
+	err := c.db.LikeAPost(ctx, postID, userID)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
-func (c *Service) UnLikePost(postID string, userID string) error {
-	err := c.db.UnLikeAPost(postID, userID)
+func (c *Service) UnLikePost(ctx context.Context, postID string, userID string) error {
+	err := c.db.UnLikeAPost(ctx, postID, userID)
 
 	if err != nil {
 		return postErrors.TwitsnapNotFound(postID)
@@ -1,12 +1,85 @@
 package service
 
 import (
+	"context"
 	"log/slog"
+	postErrors "server/src/all_errors"
 	"server/src/models"
+	"slices"
+	"strconv"
+	"time"
+
+	// The timezones are embedded because the runtime image has no tzdata.
+	_ "time/tzdata"
+)
+
+const (
+	MAX_METRIC_BUCKETS = 1000
 )
 
-func (c *Service) GetUserMetrics(userID string, limits models.MetricLimits) (models.UserMetrics, error) {
-	metrics, err := c.db.GetUserMetrics(userID, limits)
+// metricsRange is a validated [from, to) range split into buckets.
+type metricsRange struct {
+	from     time.Time
+	to       time.Time
+	interval string
+	location *time.Location
+}
+
+// parseMetricsRange validates the range of limits. The interval must be one of
+// intervals and defaults to the first of them.
+func parseMetricsRange(limits models.MetricLimits, intervals ...string) (metricsRange, error) {
+	from, err := time.Parse(time.RFC3339, limits.FromTime)
+	if err != nil {
+		return metricsRange{}, postErrors.InvalidMetricsRange("time must be an RFC3339 date")
+	}
+
+	to, err := time.Parse(time.RFC3339, limits.ToTime)
+	if err != nil {
+		return metricsRange{}, postErrors.InvalidMetricsRange("end_time must be an RFC3339 date")
+	}
+
+	if !from.Before(to) {
+		return metricsRange{}, postErrors.InvalidMetricsRange("time must be before end_time")
+	}
+
+	interval := limits.Interval
+	if interval == "" {
+		interval = intervals[0]
+	}
+
+	if !slices.Contains(intervals, interval) {
+		return metricsRange{}, postErrors.InvalidMetricsRange("there is no interval like that: " + interval)
+	}
+
+	location, err := time.LoadLocation(limits.Timezone)
+	if err != nil {
+		return metricsRange{}, postErrors.InvalidMetricsRange("there is no timezone like that: " + limits.Timezone)
+	}
+
+	r := metricsRange{from: from, to: to, interval: interval, location: location}
+
+	if buckets := len(r.buckets()); buckets > MAX_METRIC_BUCKETS {
+		return metricsRange{}, postErrors.InvalidMetricsRange(strconv.Itoa(buckets) + " buckets asked, at most " + strconv.Itoa(MAX_METRIC_BUCKETS) + " are allowed")
+	}
+
+	return r, nil
+}
+
+// buckets returns the start of every bucket that overlaps the range. It stops
+// one past MAX_METRIC_BUCKETS, so a range too big can be told apart.
+func (r metricsRange) buckets() []time.Time {
+	buckets := []time.Time{}
+
+	start := models.TruncateToInterval(r.from, r.interval, r.location)
+	for ; start.Before(r.to) && len(buckets) <= MAX_METRIC_BUCKETS; start = models.NextInterval(start, r.interval) {
+		buckets = append(buckets, start)
+	}
+
+	return buckets
+}
+
+func (c *Service) GetUserMetrics(ctx context.Context, userID string, limits models.MetricLimits) (models.UserMetrics, error) {
+	metrics, err := c.db.GetUserMetrics(ctx, userID, limits)
 
 	if err != nil {
 		return models.UserMetrics{}, err
@@ -15,4 +88,92 @@ func (c *Service) GetUserMetrics(userID string, limits models.MetricLimits) (mod
 	slog.Info("User metrics retrieved: ", "user_id", userID)
 
 	return metrics, nil
-}
\ No newline at end of file
+}
+
+// GetUserMetricsSeries returns the metrics of the user for every bucket of the
+// range, empty buckets included, along with their totals.
+func (c *Service) GetUserMetricsSeries(ctx context.Context, userID string, limits models.MetricLimits) (models.UserMetricsSeries, error) {
+	r, err := parseMetricsRange(limits, models.DAY_INTERVAL, models.HOUR_INTERVAL, models.WEEK_INTERVAL, models.MONTH_INTERVAL)
+
+	if err != nil {
+		return models.UserMetricsSeries{}, err
+	}
+
+	limits.Interval = r.interval
+
+	found, err := c.db.GetUserMetricsSeries(ctx, userID, limits)
+
+	if err != nil {
+		return models.UserMetricsSeries{}, postErrors.DatabaseError(err.Error())
+	}
+
+	byStart := map[int64]models.UserMetricsBucket{}
+	for _, bucket := range found {
+		byStart[bucket.Time.Unix()] = bucket
+	}
+
+	series := models.UserMetricsSeries{Interval: r.interval, Timezone: r.location.String(), Series: []models.UserMetricsBucket{}}
+
+	for _, start := range r.buckets() {
+		bucket := byStart[start.Unix()]
+		bucket.Time = start
+
+		series.Totals.Likes += bucket.Likes
+		series.Totals.Retweets += bucket.Retweets
+		series.Totals.Posts += bucket.Posts
+		series.Series = append(series.Series, bucket)
+	}
+
+	slog.Info("User metrics series retrieved: ", "user_id", userID, "interval", r.interval, "buckets", len(series.Series))
+
+	return series, nil
+}
+
+// GetPlatformMetrics returns the activity of every user for every bucket of
+// the range, empty buckets included.
+func (c *Service) GetPlatformMetrics(ctx context.Context, limits models.MetricLimits) (models.PlatformMetrics, error) {
+	r, err := parseMetricsRange(limits, models.DAY_INTERVAL, models.HOUR_INTERVAL, models.WEEK_INTERVAL, models.MONTH_INTERVAL)
+
+	if err != nil {
+		return models.PlatformMetrics{}, err
+	}
+
+	if limits.Visibility == "" {
+		limits.Visibility = models.ALL_VISIBILITY
+	}
+
+	if !slices.Contains([]string{models.ALL_VISIBILITY, models.PUBLIC_VISIBILITY, models.PRIVATE_VISIBILITY}, limits.Visibility) {
+		return models.PlatformMetrics{}, postErrors.InvalidMetricsRange("there is no visibility like that: " + limits.Visibility)
+	}
+
+	limits.Interval = r.interval
+
+	found, err := c.db.GetPlatformMetricsSeries(ctx, limits)
+
+	if err != nil {
+		return models.PlatformMetrics{}, postErrors.DatabaseError(err.Error())
+	}
+
+	byStart := map[int64]models.PlatformMetricsBucket{}
+	for _, bucket := range found {
+		byStart[bucket.Time.Unix()] = bucket
+	}
+
+	metrics := models.PlatformMetrics{Interval: r.interval, Timezone: r.location.String(), Visibility: limits.Visibility, Series: []models.PlatformMetricsBucket{}}
+
+	for _, start := range r.buckets() {
+		bucket := byStart[start.Unix()]
+		bucket.Time = start
+
+		if bucket.Posts > 0 {
+			bucket.Media_Share = float64(bucket.Media_Posts) / float64(bucket.Posts)
+			bucket.Mention_Share = float64(bucket.Mention_Posts) / float64(bucket.Posts)
+		}
+
+		metrics.Series = append(metrics.Series, bucket)
+	}
+
+	slog.Info("Platform metrics retrieved: ", "interval", r.interval, "visibility", limits.Visibility, "buckets", len(metrics.Series))
+
+	return metrics, nil
+}
@@ -1,7 +1,7 @@
 package service
 
 import (
-	"errors"
+	"context"
 	"log/slog"
 	"time"
 
@@ -11,23 +11,25 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
-func (c *Service) ModifyPostByID(postID string, editInfo models.EditPostExpectedFormat, token string, userID string) (*models.FrontPost, error) {
+func (c *Service) ModifyPostByID(ctx context.Context, postID string, editInfo models.EditPostExpectedFormat, token string, session Session) (*models.FrontPost, error) {
 	validate := validator.New()
 	if err := validate.Struct(editInfo); err != nil {
 		return nil, postErrors.TwitSnapImportantFieldsMissing(err)
 	}
 
-	modPost, err := c.db.EditPost(postID, editInfo, userID)
+	if err := c.authorizePost(ctx, session, EDIT_ACTION, postID); err != nil {
+		return nil, err
+	}
+
+	userID := session.UserID
+
+	modPost, err := c.db.EditPost(ctx, postID, editInfo, userID)
 
 	if err != nil {
-		if errors.Is(err, postErrors.ErrTwitsnapNotFound) {
-			return nil, postErrors.TwitsnapNotFound(postID)
-		} else {
-			return nil, postErrors.DatabaseError(err.Error())
-		}
+		return nil, editError(postID, 0, err)
 	}
 
-	modPost, err = addAuthorInfoToPost(modPost, token)
+	modPost, err = c.presentPost(ctx, modPost, userID, token)
 
 	if err != nil {
 		return nil, postErrors.UserInfoError(err.Error())
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"log/slog"
 	"server/src/database"
 	"server/src/models"
@@ -17,7 +18,7 @@ const (
 	MAX_CHAR = 280
 )
 
-func (c *Service) CreatePost(newPost *models.PostExpectedFormat, author_id string, token string) (*models.FrontPost, error) {
+func (c *Service) CreatePost(ctx context.Context, newPost *models.PostExpectedFormat, author_id string, token string) (*models.FrontPost, error) {
 
 	postNew, err := c.parsePost(newPost, author_id)
 
@@ -25,25 +26,33 @@ func (c *Service) CreatePost(newPost *models.PostExpectedFormat, author_id strin
 		return nil, err
 	}
 
-	newPosted, err := c.db.AddNewPost(postNew)
+	return c.addPost(ctx, postNew, nil, token)
+}
 
-	if err != nil {
-		return nil, postErrors.DatabaseError(err.Error())
+// addPost stores postNew with the messages it causes besides its mentions
+// and the NEW_CONTENT event.
+func (c *Service) addPost(ctx context.Context, postNew models.DBPost, messages []models.OutboxMessage, token string) (*models.FrontPost, error) {
+	// The mentions and the event are delivered by the outbox dispatcher, so a
+	// notifications service or broker that is down does not fail the post.
+	messages = append([]models.OutboxMessage{
+		models.NewEventOutboxMessage(models.NewContentEvent(models.NEW_CONTENT, postNew.Post_ID, postNew.Author_ID, postNew.Tags)),
+	}, messages...)
+
+	for _, user := range postNew.Mentions {
+		newMentionNotif := models.MentionNotificationRequest{UserId: user, TaggerId: postNew.Author_ID, PostId: postNew.Original_Post_ID}
+		messages = append(messages, models.NewMentionOutboxMessage(newMentionNotif))
 	}
 
-	newPosted, err = addAuthorInfoToPost(newPosted, token)
+	newPosted, err := c.db.AddNewPostWithOutbox(ctx, postNew, messages)
 
 	if err != nil {
-		return nil, postErrors.UserInfoError(err.Error())
+		return nil, postErrors.DatabaseError(err.Error())
 	}
 
-	for _, user := range newPost.Mentions {
-		newMentionNotif := models.MentionNotificationRequest{UserId: user, TaggerId: newPosted.Author_Info.Author_ID, PostId: newPosted.Original_Post_ID}
-		err = sendMentionNotif(newMentionNotif, token)
+	newPosted, err = c.presentPost(ctx, newPosted, postNew.Author_ID, token)
 
-		if err != nil {
-			return nil, postErrors.NotificationError(err.Error())
-		}
+	if err != nil {
+		return nil, postErrors.UserInfoError(err.Error())
 	}
 
 	slog.Info("New post created: ", "original_post_id", newPosted.Original_Post_ID, "author_id", newPosted.Author_Info.Author_ID, "timestamp", newPosted.Time)
@@ -77,9 +86,9 @@ func (c *Service) parsePost(post *models.PostExpectedFormat, author_id string) (
 	return postNew, nil
 }
 
-func (c *Service) FetchAllPosts(limitConfig models.LimitConfig, token string) ([]models.FrontPost, bool, error) {
+func (c *Service) FetchAllPosts(ctx context.Context, limitConfig models.LimitConfig, token string) ([]models.FrontPost, bool, error) {
 
-	posts, hasMore, err := c.db.GetAllPosts(limitConfig, database.ADMIN)
+	posts, hasMore, err := c.db.GetAllPosts(ctx, limitConfig, database.ADMIN)
 
 	if err != nil {
 		return []models.FrontPost{}, false, err
@@ -89,7 +98,7 @@ func (c *Service) FetchAllPosts(limitConfig models.LimitConfig, token string) ([
 		return []models.FrontPost{}, false, nil
 	}
 
-	posts, err = addAuthorInfoToPosts(posts, token)
+	posts, err = c.presentPosts(ctx, posts, database.ADMIN, token)
 
 	slog.Info("All posts retrieved: ", "time", time.Now(), "count", len(posts))
 
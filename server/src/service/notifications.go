@@ -1,49 +1,58 @@
 package service
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"log/slog"
-	"strconv"
 
 	"net/http"
-	"os"
 	"server/src/models"
 )
 
+// HTTPNotificationClient talks to the notifications service.
+type HTTPNotificationClient struct {
+	host     string
+	outbound *outboundClient
+}
 
-func sendMentionNotif(newMentionNotification models.MentionNotificationRequest, token string) error {
+func NewHTTPNotificationClient(host string, config OutboundConfig) *HTTPNotificationClient {
+	return &HTTPNotificationClient{host: host, outbound: newOutboundClient(NOTIFICATIONS_DEPENDENCY, config)}
+}
 
-	if os.Getenv("ENVIROMENT") == "test" {
-		slog.Info("Notification sent to ", "user_id", newMentionNotification.UserId)
-		return nil
-	}
+// SendMention is not retried here: the outbox dispatcher decides when to try
+// a failed mention again.
+func (n *HTTPNotificationClient) SendMention(ctx context.Context, newMentionNotification models.MentionNotificationRequest, token string) error {
 
-	url := "http://" + os.Getenv("NOTIF_HOST") + "/notification/mention"
+	url := "http://" + n.host + "/notification/mention"
 
 	marshalledData, _ := json.Marshal(newMentionNotification)
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(marshalledData))
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
+	_, err := n.outbound.do(ctx, http.MethodPost, url, token, marshalledData)
 
 	if err != nil {
-		return errors.New("error creating request")
+		return err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	slog.Info("Notification sent to ", "user_id", newMentionNotification.UserId)
 
-	if err != nil {
-		return errors.New("error sending request, " + err.Error())
-	}
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return errors.New("error sending request, status code: "  + strconv.Itoa(resp.StatusCode))
+// SendReply tells the author of a post about a reply to it. Like mentions,
+// replies are retried by the outbox dispatcher.
+func (n *HTTPNotificationClient) SendReply(ctx context.Context, newReplyNotification models.ReplyNotificationRequest, token string) error {
+
+	url := "http://" + n.host + "/notification/reply"
+
+	marshalledData, _ := json.Marshal(newReplyNotification)
+
+	_, err := n.outbound.do(ctx, http.MethodPost, url, token, marshalledData)
+
+	if err != nil {
+		return err
 	}
 
-	slog.Info("Notification sent to ", "user_id", newMentionNotification.UserId)
+	slog.Info("Reply notification sent to ", "user_id", newReplyNotification.UserId)
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_OUTBOUND_TIMEOUT = 2 * time.Second
+	DEFAULT_OUTBOUND_RETRIES = 2
+	DEFAULT_BACKOFF_BASE     = 50 * time.Millisecond
+	DEFAULT_BACKOFF_MAX      = time.Second
+	DEFAULT_BREAKER_FAILURES = 5
+	DEFAULT_BREAKER_COOLDOWN = 10 * time.Second
+	USERS_DEPENDENCY         = "users"
+	NOTIFICATIONS_DEPENDENCY = "notifications"
+)
+
+// ErrCircuitOpen is returned without calling the dependency while its
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// StatusError is returned when a dependency answers with a status other
+// than 2xx.
+type StatusError struct {
+	Dependency string
+	Status     int
+}
+
+func (e *StatusError) Error() string {
+	return "error sending request, " + e.Dependency + " answered with status code: " + strconv.Itoa(e.Status)
+}
+
+// OutboundConfig tunes the calls to one dependency.
+type OutboundConfig struct {
+	// Timeout bounds every attempt on its own.
+	Timeout time.Duration
+	// Retries is how many times a failed GET is tried again.
+	Retries int
+	// The wait before retry n is random between zero and
+	// min(BackoffMax, BackoffBase * 2^n).
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// The breaker opens after BreakerFailures failures in a row and lets a
+	// single call through once BreakerCooldown has passed.
+	BreakerFailures int
+	BreakerCooldown time.Duration
+}
+
+// LoadOutboundConfig reads the config of a dependency from the environment,
+// e.g. USERS_TIMEOUT=1s, USERS_RETRIES=3, USERS_BREAKER_FAILURES=10 and
+// USERS_BREAKER_COOLDOWN=30s for the prefix USERS.
+func LoadOutboundConfig(prefix string) OutboundConfig {
+	return OutboundConfig{
+		Timeout:         envDuration(prefix+"_TIMEOUT", DEFAULT_OUTBOUND_TIMEOUT),
+		Retries:         envInt(prefix+"_RETRIES", DEFAULT_OUTBOUND_RETRIES),
+		BackoffBase:     DEFAULT_BACKOFF_BASE,
+		BackoffMax:      DEFAULT_BACKOFF_MAX,
+		BreakerFailures: envInt(prefix+"_BREAKER_FAILURES", DEFAULT_BREAKER_FAILURES),
+		BreakerCooldown: envDuration(prefix+"_BREAKER_COOLDOWN", DEFAULT_BREAKER_COOLDOWN),
+	}
+}
+
+// outboundClient makes the calls to one dependency. Every attempt has its own
+// timeout, GETs are retried with jittered backoff, and a circuit breaker
+// stops calling a dependency that keeps failing.
+type outboundClient struct {
+	dependency string
+	config     OutboundConfig
+	client     *http.Client
+	breaker    *circuitBreaker
+}
+
+func newOutboundClient(dependency string, config OutboundConfig) *outboundClient {
+	return &outboundClient{
+		dependency: dependency,
+		config:     config,
+		client:     &http.Client{},
+		breaker:    newCircuitBreaker(dependency, config.BreakerFailures, config.BreakerCooldown),
+	}
+}
+
+// do sends the request and returns the body of a 2xx answer.
+func (o *outboundClient) do(ctx context.Context, method string, url string, token string, body []byte) ([]byte, error) {
+	retries := 0
+	if method == http.MethodGet {
+		retries = o.config.Retries
+	}
+
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		if !o.breaker.allow() {
+			return nil, ErrCircuitOpen
+		}
+
+		var response []byte
+		response, err = o.attempt(ctx, method, url, token, body)
+
+		if ctx.Err() != nil {
+			// The caller gave up, which says nothing about the dependency.
+			o.breaker.abandon()
+			return nil, err
+		}
+
+		// A 4xx answer shows the dependency is up, so only the failures
+		// worth retrying count against the breaker.
+		if err == nil || !retryable(err) {
+			o.breaker.record(true)
+			return response, err
+		}
+
+		o.breaker.record(false)
+
+		if attempt == retries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(o.backoff(attempt)):
+		}
+	}
+}
+
+func (o *outboundClient) attempt(ctx context.Context, method string, url string, token string, body []byte) ([]byte, error) {
+	if o.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.config.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+
+	if err != nil {
+		return nil, errors.New("error creating request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := o.client.Do(req)
+
+	if err != nil {
+		return nil, errors.New("error sending request, " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	response, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, errors.New("error reading request, " + err.Error())
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &StatusError{Dependency: o.dependency, Status: resp.StatusCode}
+	}
+
+	return response, nil
+}
+
+func (o *outboundClient) backoff(attempt int) time.Duration {
+	return jitteredBackoff(o.config.BackoffBase, o.config.BackoffMax, attempt)
+}
+
+// jitteredBackoff is random between zero and min(maxWait, base * 2^attempt).
+func jitteredBackoff(base time.Duration, maxWait time.Duration, attempt int) time.Duration {
+	ceiling := base << attempt
+	if ceiling <= 0 || ceiling > maxWait {
+		ceiling = maxWait
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+// retryable tells apart the failures that may go away on their own: the
+// dependency could not be reached, was too slow, or was overloaded.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status >= 500 || statusErr.Status == http.StatusTooManyRequests
+	}
+	return true
+}
+
+const (
+	BREAKER_CLOSED    = "closed"
+	BREAKER_OPEN      = "open"
+	BREAKER_HALF_OPEN = "half_open"
+)
+
+type circuitBreaker struct {
+	mu         sync.Mutex
+	dependency string
+	threshold  int
+	cooldown   time.Duration
+	state      string
+	failures   int
+	openedAt   time.Time
+	probing    bool
+}
+
+// newCircuitBreaker returns a closed breaker. A threshold of zero disables it.
+func newCircuitBreaker(dependency string, threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{dependency: dependency, threshold: threshold, cooldown: cooldown, state: BREAKER_CLOSED}
+}
+
+// allow reports whether a call may go out. Once the cooldown has passed an
+// open breaker lets exactly one call through to probe the dependency.
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BREAKER_OPEN:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BREAKER_HALF_OPEN
+		b.probing = true
+		return true
+	case BREAKER_HALF_OPEN:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+
+	return true
+}
+
+// abandon gives back the probe of a call whose outcome is unknown.
+func (b *circuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) record(success bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if success {
+		if b.state != BREAKER_CLOSED {
+			slog.Info("Circuit breaker closed", "dependency", b.dependency)
+		}
+		b.state = BREAKER_CLOSED
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+
+	if b.state == BREAKER_HALF_OPEN || b.failures >= b.threshold {
+		if b.state != BREAKER_OPEN {
+			slog.Warn("Circuit breaker opened", "dependency", b.dependency, "failures", b.failures)
+		}
+		b.state = BREAKER_OPEN
+		b.openedAt = time.Now()
+	}
+}
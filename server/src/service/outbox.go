@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	postErrors "server/src/all_errors"
+	"server/src/auth"
+	"server/src/database"
+	"server/src/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DEFAULT_OUTBOX_POLL_INTERVAL = time.Second
+	DEFAULT_OUTBOX_BATCH_SIZE    = 50
+	DEFAULT_OUTBOX_LEASE         = 30 * time.Second
+	DEFAULT_OUTBOX_MAX_ATTEMPTS  = 8
+	DEFAULT_OUTBOX_BACKOFF_BASE  = time.Second
+	DEFAULT_OUTBOX_BACKOFF_MAX   = 5 * time.Minute
+	OUTBOX_LIST_LIMIT            = 100
+)
+
+// OutboxConfig tunes the delivery of the outbox messages.
+type OutboxConfig struct {
+	// PollInterval is the wait between two passes over the outbox.
+	PollInterval time.Duration
+	// BatchSize is how many messages are delivered in one pass.
+	BatchSize int
+	// Lease is how long a claimed message is hidden from other dispatchers.
+	// Messages are claimed one at a time, so it has to be longer than a
+	// single delivery attempt.
+	Lease time.Duration
+	// After MaxAttempts failed deliveries the message is marked as failed
+	// and no longer retried.
+	MaxAttempts int
+	// The wait before retry n is random between zero and
+	// min(BackoffMax, BackoffBase * 2^n).
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// LoadOutboxConfig reads the outbox config from the environment:
+// OUTBOX_POLL_INTERVAL, OUTBOX_BATCH_SIZE, OUTBOX_LEASE, OUTBOX_MAX_ATTEMPTS,
+// OUTBOX_BACKOFF_BASE and OUTBOX_BACKOFF_MAX.
+func LoadOutboxConfig() OutboxConfig {
+	return OutboxConfig{
+		PollInterval: envDuration("OUTBOX_POLL_INTERVAL", DEFAULT_OUTBOX_POLL_INTERVAL),
+		BatchSize:    envInt("OUTBOX_BATCH_SIZE", DEFAULT_OUTBOX_BATCH_SIZE),
+		Lease:        envDuration("OUTBOX_LEASE", DEFAULT_OUTBOX_LEASE),
+		MaxAttempts:  envInt("OUTBOX_MAX_ATTEMPTS", DEFAULT_OUTBOX_MAX_ATTEMPTS),
+		BackoffBase:  envDuration("OUTBOX_BACKOFF_BASE", DEFAULT_OUTBOX_BACKOFF_BASE),
+		BackoffMax:   envDuration("OUTBOX_BACKOFF_MAX", DEFAULT_OUTBOX_BACKOFF_MAX),
+	}
+}
+
+// OutboxDispatcher delivers the notifications and events written to the
+// outbox. Messages are claimed with a lease, so several replicas can run a
+// dispatcher against the same database.
+type OutboxDispatcher struct {
+	// owner tells the leases of this dispatcher from those of the others.
+	owner         string
+	db            database.Database
+	notifications NotificationClient
+	events        EventPublisher
+	config        OutboxConfig
+}
+
+func NewOutboxDispatcher(db database.Database, notifications NotificationClient, events EventPublisher, config OutboxConfig) *OutboxDispatcher {
+	return &OutboxDispatcher{owner: uuid.NewString(), db: db, notifications: notifications, events: events, config: config}
+}
+
+// Run dispatches the outbox every PollInterval until ctx is done.
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Error dispatching the outbox: ", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce tries to deliver up to BatchSize messages that are due and
+// returns how many were delivered. Each message is claimed right before its
+// delivery, so its lease does not run out while the ones before it are sent.
+// Only messages due when the pass starts are claimed, so a message that fails
+// is not retried within the same pass.
+func (d *OutboxDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	dueBy := time.Now().UTC()
+	delivered := 0
+
+	for range d.config.BatchSize {
+		messages, err := d.db.ClaimOutboxMessages(ctx, d.owner, dueBy, 1, d.config.Lease)
+
+		if err != nil {
+			return delivered, err
+		}
+
+		if len(messages) == 0 {
+			break
+		}
+
+		message := messages[0]
+		err = d.deliver(ctx, message)
+
+		if err == nil {
+			delivered++
+			if err := d.db.DeleteOutboxMessage(ctx, message.Message_ID); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		err = d.db.UpdateOutboxMessage(ctx, d.failed(message, err))
+
+		// The delivery outlived the lease and the message is another
+		// dispatcher's now, which records its own attempt.
+		if errors.Is(err, postErrors.ErrOutboxLeaseLost) {
+			slog.Warn("Outbox lease lost: ", "message_id", message.Message_ID)
+			continue
+		}
+
+		if err != nil {
+			return delivered, err
+		}
+	}
+
+	return delivered, nil
+}
+
+func (d *OutboxDispatcher) deliver(ctx context.Context, message models.OutboxMessage) error {
+	if message.Kind == models.EVENT {
+		event, err := models.DecodeQueueMessage(message.Event)
+
+		if err != nil {
+			return err
+		}
+
+		return d.events.Publish(ctx, event)
+	}
+
+	if message.Kind == models.REPLY_NOTIFICATION {
+		return d.deliverReply(ctx, message)
+	}
+
+	return d.deliverMention(ctx, message)
+}
+
+// deliverMention sends the mention with a token of the user who caused it, as
+// the request that wrote it is long gone.
+func (d *OutboxDispatcher) deliverMention(ctx context.Context, message models.OutboxMessage) error {
+	token, err := auth.GenerateToken(message.Mention.TaggerId, "", false)
+
+	if err != nil {
+		return err
+	}
+
+	return d.notifications.SendMention(ctx, message.Mention, token)
+}
+
+// deliverReply sends the reply with a token of the user who replied.
+func (d *OutboxDispatcher) deliverReply(ctx context.Context, message models.OutboxMessage) error {
+	token, err := auth.GenerateToken(message.Reply.ReplierId, "", false)
+
+	if err != nil {
+		return err
+	}
+
+	return d.notifications.SendReply(ctx, message.Reply, token)
+}
+
+// failed records a failed delivery. The message is retried after a backoff
+// until it runs out of attempts.
+func (d *OutboxDispatcher) failed(message models.OutboxMessage, err error) models.OutboxMessage {
+	message.Attempts++
+	message.Last_Error = err.Error()
+
+	if message.Attempts >= d.config.MaxAttempts {
+		message.Status = models.OUTBOX_FAILED
+
+		slog.Warn("Outbox message failed: ", "message_id", message.Message_ID, "attempts", message.Attempts, "error", err)
+
+		return message
+	}
+
+	message.Next_Attempt = time.Now().UTC().Add(jitteredBackoff(d.config.BackoffBase, d.config.BackoffMax, message.Attempts-1))
+
+	return message
+}
+
+// GetOutbox returns how many messages are pending and failed, with the
+// oldest messages of the given status.
+func (c *Service) GetOutbox(ctx context.Context, status string) (models.OutboxSummary, error) {
+	if status != models.OUTBOX_PENDING && status != models.OUTBOX_FAILED {
+		return models.OutboxSummary{}, postErrors.BadOutboxStatus(status)
+	}
+
+	pending, err := c.db.CountOutboxMessages(ctx, models.OUTBOX_PENDING)
+
+	if err != nil {
+		return models.OutboxSummary{}, postErrors.DatabaseError(err.Error())
+	}
+
+	failed, err := c.db.CountOutboxMessages(ctx, models.OUTBOX_FAILED)
+
+	if err != nil {
+		return models.OutboxSummary{}, postErrors.DatabaseError(err.Error())
+	}
+
+	messages, err := c.db.GetOutboxMessages(ctx, status, OUTBOX_LIST_LIMIT)
+
+	if err != nil {
+		return models.OutboxSummary{}, postErrors.DatabaseError(err.Error())
+	}
+
+	return models.OutboxSummary{Pending: pending, Failed: failed, Messages: messages}, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	postErrors "server/src/all_errors"
+)
+
+// Actions the policy decides on.
+const (
+	EDIT_ACTION           = "edit"
+	DELETE_ACTION         = "delete"
+	BLOCK_ACTION          = "block"
+	REVERT_ACTION         = "revert"
+	VIEW_BOOKMARKS_ACTION = "view bookmarks"
+	ADMIN_LISTING_ACTION  = "admin listing"
+	INVALIDATE_ACTION     = "invalidate following"
+)
+
+// Session is who makes a request, as told by its token.
+type Session struct {
+	UserID string
+	Admin  bool
+}
+
+// Authorize decides whether session may take action on something owned by
+// ownerID, which is empty for the actions that are not about a post or a
+// user:
+//   - only the author edits a post;
+//   - the author or an admin deletes it;
+//   - no one edits or deletes a retweet as a post;
+//   - only admins block, unblock and revert posts, see the admin listings and
+//     invalidate cached following lists;
+//   - bookmarks are seen by their owner or an admin.
+func Authorize(session Session, action string, ownerID string) error {
+	allowed := false
+
+	// A session without a user is never allowed, not even to match an empty
+	// owner.
+	if session.UserID == "" {
+		return postErrors.ActionDenied(action)
+	}
+
+	switch action {
+	case EDIT_ACTION:
+		allowed = session.UserID == ownerID
+	case DELETE_ACTION, VIEW_BOOKMARKS_ACTION:
+		allowed = session.Admin || session.UserID == ownerID
+	case BLOCK_ACTION, REVERT_ACTION, ADMIN_LISTING_ACTION, INVALIDATE_ACTION:
+		allowed = session.Admin
+	}
+
+	if !allowed {
+		return postErrors.ActionDenied(action)
+	}
+
+	return nil
+}
+
+// authorizePost applies the policy to the post postID, which has to exist.
+// Retweets are neither edited nor deleted as posts: their text is the one of
+// their author, and they go away by undoing the retweet.
+func (c *Service) authorizePost(ctx context.Context, session Session, action string, postID string) error {
+	post, err := c.db.GetPost(ctx, postID, session.UserID)
+
+	if err != nil {
+		return postError(postID, err)
+	}
+
+	if post.Is_Retweet {
+		return postErrors.ActionDenied(action)
+	}
+
+	return Authorize(session, action, post.Author_Info.Author_ID)
+}
+
+// postError answers a failed read or write of the post postID: not found only
+// when the post is missing, and a database error otherwise.
+func postError(postID string, err error) error {
+	if errors.Is(err, postErrors.ErrTwitsnapNotFound) {
+		return postErrors.TwitsnapNotFound(postID)
+	}
+
+	return postErrors.DatabaseError(err.Error())
+}
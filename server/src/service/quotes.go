@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	postErrors "server/src/all_errors"
+	"server/src/database"
+	"server/src/models"
+	"slices"
+)
+
+// QuotePost posts newPost quoting postID, which the author has to be able to
+// see. Quoting a retweet quotes its original.
+func (c *Service) QuotePost(ctx context.Context, postID string, newPost *models.PostExpectedFormat, authorID string, token string) (*models.FrontPost, error) {
+	quote, err := c.parsePost(newPost, authorID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	quoted, err := c.db.GetPost(ctx, postID, authorID)
+
+	if err != nil {
+		return nil, postErrors.TwitsnapNotFound(postID)
+	}
+
+	quotedAuthor := quoted.Author_Info.Author_ID
+
+	if !quoted.Public && quotedAuthor != authorID {
+		following, err := c.getFollowing(ctx, authorID, token)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(following, quotedAuthor) {
+			return nil, postErrors.TwitsnapNotFound(postID)
+		}
+	}
+
+	quote.Quoted_Post_ID = quoted.Original_Post_ID
+
+	posted, err := c.addPost(ctx, quote, nil, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Post quoted: ", "post_id", posted.Post_ID, "quoted_post_id", posted.Quoted_Post_ID, "author_id", authorID)
+
+	return posted, nil
+}
+
+// presentPosts gets posts ready to be shown to userID: the private posts
+// they quote that userID cannot see become tombstones and every author is
+// filled in.
+func (c *Service) presentPosts(ctx context.Context, posts []models.FrontPost, userID string, token string) ([]models.FrontPost, error) {
+	posts, err := c.hideQuotes(ctx, posts, userID, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.authors.Hydrate(ctx, posts, token)
+}
+
+func (c *Service) presentPost(ctx context.Context, post models.FrontPost, userID string, token string) (models.FrontPost, error) {
+	posts, err := c.presentPosts(ctx, []models.FrontPost{post}, userID, token)
+
+	if err != nil {
+		return models.FrontPost{}, err
+	}
+
+	return posts[0], nil
+}
+
+// hideQuotes turns the quoted posts userID cannot see into tombstones. Who
+// userID follows is only asked for when a private post is quoted.
+func (c *Service) hideQuotes(ctx context.Context, posts []models.FrontPost, userID string, token string) ([]models.FrontPost, error) {
+	var following []string
+	loaded := false
+
+	for i, post := range posts {
+		quoted := post.Quoted_Post
+
+		if quoted == nil || quoted.Tombstone || quoted.Public || userID == database.ADMIN || quoted.Author_Info.Author_ID == userID {
+			continue
+		}
+
+		if !loaded {
+			var err error
+			if following, err = c.getFollowing(ctx, userID, token); err != nil {
+				return nil, err
+			}
+			loaded = true
+		}
+
+		if !slices.Contains(following, quoted.Author_Info.Author_ID) {
+			hidden := models.TombstoneOf(*quoted)
+			posts[i].Quoted_Post = &hidden
+		}
+	}
+
+	return posts, nil
+}
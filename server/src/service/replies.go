@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"slices"
+	"time"
+)
+
+const (
+	CONVERSATION_PAGE_LIMIT = 20
+)
+
+// ReplyToPost posts newPost as a reply to postID, which the author has to be
+// able to see. The author of the post is told about the reply.
+func (c *Service) ReplyToPost(ctx context.Context, postID string, newPost *models.PostExpectedFormat, authorID string, token string) (*models.FrontPost, error) {
+	reply, err := c.parsePost(newPost, authorID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := c.db.GetPost(ctx, postID, authorID)
+
+	if err != nil {
+		return nil, postErrors.TwitsnapNotFound(postID)
+	}
+
+	following, err := c.getFollowing(ctx, authorID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	parentAuthor := parent.Author_Info.Author_ID
+
+	if !parent.Public && parentAuthor != authorID && !slices.Contains(following, parentAuthor) {
+		return nil, postErrors.TwitsnapNotFound(postID)
+	}
+
+	reply.In_Reply_To_Post_ID = parent.Original_Post_ID
+	reply.Conversation_ID = conversationID(parent)
+
+	messages := []models.OutboxMessage{}
+
+	if parentAuthor != authorID {
+		notification := models.ReplyNotificationRequest{UserId: parentAuthor, ReplierId: authorID, PostId: reply.Post_ID, InReplyToPostId: reply.In_Reply_To_Post_ID}
+		messages = append(messages, models.NewReplyOutboxMessage(notification))
+	}
+
+	posted, err := c.addPost(ctx, reply, messages, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Reply posted: ", "post_id", posted.Post_ID, "in_reply_to_post_id", posted.In_Reply_To_Post_ID, "author_id", authorID)
+
+	return posted, nil
+}
+
+// FetchConversation returns the conversation of postID as a tree from its
+// first post, or flattened in reading order one page at a time, along with
+// the cursor of the next page.
+func (c *Service) FetchConversation(ctx context.Context, postID string, mode string, limitConfig models.LimitConfig, after *models.ThreadCursor, userID string, token string) (models.ConversationNode, []models.FrontPost, *models.ThreadCursor, error) {
+	if mode == "" {
+		mode = models.TREE_CONVERSATION
+	}
+
+	if mode != models.TREE_CONVERSATION && mode != models.THREAD_CONVERSATION {
+		return models.ConversationNode{}, nil, nil, postErrors.BadConversationMode(mode)
+	}
+
+	post, err := c.db.GetPost(ctx, postID, userID)
+
+	if err != nil {
+		return models.ConversationNode{}, nil, nil, postErrors.TwitsnapNotFound(postID)
+	}
+
+	following, err := c.getFollowing(ctx, userID, token)
+	if err != nil {
+		return models.ConversationNode{}, nil, nil, err
+	}
+
+	following = append(following, userID)
+
+	if mode == models.THREAD_CONVERSATION {
+		thread, next, err := c.db.GetConversationThread(ctx, conversationID(post), following, userID, limitConfig, after)
+
+		if err != nil {
+			return models.ConversationNode{}, nil, nil, postErrors.DatabaseError(err.Error())
+		}
+
+		thread, err = c.presentPosts(ctx, thread, userID, token)
+
+		if err != nil {
+			return models.ConversationNode{}, nil, nil, err
+		}
+
+		slog.Info("Conversation retrieved: ", "post_id", postID, "conversation_id", conversationID(post), "mode", mode, "count", len(thread), "time", time.Now())
+
+		return models.ConversationNode{}, thread, next, nil
+	}
+
+	posts, err := c.db.GetConversation(ctx, conversationID(post), following, userID)
+
+	if err != nil {
+		return models.ConversationNode{}, nil, nil, postErrors.DatabaseError(err.Error())
+	}
+
+	posts, err = c.presentPosts(ctx, posts, userID, token)
+
+	if err != nil {
+		return models.ConversationNode{}, nil, nil, err
+	}
+
+	tree := conversationTree(posts, conversationID(post))
+
+	slog.Info("Conversation retrieved: ", "post_id", postID, "conversation_id", tree.Post.Conversation_ID, "mode", mode, "count", len(posts), "time", time.Now())
+
+	return tree, nil, nil, nil
+}
+
+// conversationID is the conversation post belongs to. Posts from before
+// replies that were not migrated start their own.
+func conversationID(post models.FrontPost) string {
+	if post.Conversation_ID == "" {
+		return post.Original_Post_ID
+	}
+	return post.Conversation_ID
+}
+
+// conversationTree hangs every post under the one it replies to. Replies
+// whose parent left no trace hang from the first post.
+func conversationTree(posts []models.FrontPost, rootID string) models.ConversationNode {
+	children := map[string][]models.FrontPost{}
+	ids := map[string]bool{}
+	root := models.NewTombstoneFrontPost(models.DBPost{Post_ID: rootID, Conversation_ID: rootID})
+
+	for _, post := range posts {
+		ids[post.Post_ID] = true
+
+		if post.Post_ID == rootID {
+			root = post
+		}
+	}
+
+	for _, post := range posts {
+		if post.Post_ID == rootID {
+			continue
+		}
+
+		parentID := post.In_Reply_To_Post_ID
+		if !ids[parentID] {
+			parentID = rootID
+		}
+		children[parentID] = append(children[parentID], post)
+	}
+
+	return conversationNode(root, children)
+}
+
+func conversationNode(post models.FrontPost, children map[string][]models.FrontPost) models.ConversationNode {
+	node := models.ConversationNode{Post: post, Replies: []models.ConversationNode{}}
+
+	for _, reply := range children[post.Post_ID] {
+		node.Replies = append(node.Replies, conversationNode(reply, children))
+	}
+
+	return node
+}
@@ -1,14 +1,16 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	postErrors "server/src/all_errors"
 	"server/src/models"
 	"time"
 )
 
-func (c *Service) RetweetPost(postId string, userID string, token string) (*models.FrontPost, error) {
-	post, err := c.db.GetPost(postId, userID)
+func (c *Service) RetweetPost(ctx context.Context, postId string, userID string, token string) (*models.FrontPost, error) {
+	post, err := c.db.GetPost(ctx, postId, userID)
 
 	if err != nil {
 		return nil, postErrors.TwitsnapNotFound(postId)
@@ -16,13 +18,17 @@ func (c *Service) RetweetPost(postId string, userID string, token string) (*mode
 
 	retweet := models.NewRetweetDBPost(post, userID)
 
-	newRetweet, err := c.db.AddNewRetweet(retweet)
+	newRetweet, err := c.db.AddNewRetweet(ctx, retweet)
+
+	if errors.Is(err, postErrors.ErrAlreadyRetweeted) {
+		return nil, postErrors.AlreadyRetweeted(postId)
+	}
 
 	if err != nil {
 		return nil, postErrors.DatabaseError(err.Error())
 	}
 
-	newRetweet, err = addAuthorInfoToPost(newRetweet, token)
+	newRetweet, err = c.presentPost(ctx, newRetweet, userID, token)
 
 	if err != nil {
 		return nil, postErrors.UserInfoError(err.Error())
@@ -33,11 +39,11 @@ func (c *Service) RetweetPost(postId string, userID string, token string) (*mode
 	return &newRetweet, nil
 }
 
-func (c *Service) RemoveRetweet(postId string, userID string) error {
-	err := c.db.DeleteRetweet(postId, userID)
+func (c *Service) RemoveRetweet(ctx context.Context, postId string, userID string) error {
+	err := c.db.DeleteRetweet(ctx, postId, userID)
 
 	if err != nil {
-		return postErrors.TwitsnapNotFound(postId)
+		return postError(postId, err)
 	}
 
 	slog.Info("Retweet removed: ", "post_id", postId, "time", time.Now())
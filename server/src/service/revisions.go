@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	REVISIONS_PAGE_LIMIT = 20
+)
+
+func (c *Service) FetchRevisions(ctx context.Context, postID string, limitConfig models.LimitConfig) ([]models.Revision, bool, error) {
+	revisions, hasMore, err := c.db.GetRevisions(ctx, postID, limitConfig)
+
+	if err != nil {
+		if errors.Is(err, postErrors.ErrTwitsnapNotFound) {
+			return nil, false, postErrors.TwitsnapNotFound(postID)
+		}
+		return nil, false, postErrors.DatabaseError(err.Error())
+	}
+
+	return revisions, hasMore, nil
+}
+
+// RevertPost brings the post back to one of its revisions. Only admins may.
+func (c *Service) RevertPost(ctx context.Context, postID string, revertInfo models.RevertExpectedFormat, session Session, token string) (*models.FrontPost, error) {
+	if err := Authorize(session, REVERT_ACTION, ""); err != nil {
+		return nil, err
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(revertInfo); err != nil {
+		return nil, postErrors.TwitSnapImportantFieldsMissing(err)
+	}
+
+	userID := session.UserID
+
+	post, err := c.db.RevertPost(ctx, postID, *revertInfo.Revision, userID)
+
+	if err != nil {
+		return nil, editError(postID, *revertInfo.Revision, err)
+	}
+
+	post, err = c.presentPost(ctx, post, userID, token)
+
+	if err != nil {
+		return nil, postErrors.UserInfoError(err.Error())
+	}
+
+	slog.Info("Post reverted: ", "post_id", postID, "revision", *revertInfo.Revision, "User", userID)
+
+	return &post, nil
+}
+
+func editError(postID string, revision int, err error) error {
+	switch {
+	case errors.Is(err, postErrors.ErrTwitsnapNotFound):
+		return postErrors.TwitsnapNotFound(postID)
+	case errors.Is(err, postErrors.ErrRevisionNotFound):
+		return postErrors.RevisionNotFound(postID, revision)
+	case errors.Is(err, postErrors.ErrEditConflict):
+		return postErrors.EditConflict(postID)
+	default:
+		return postErrors.DatabaseError(err.Error())
+	}
+}
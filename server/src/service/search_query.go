@@ -0,0 +1,254 @@
+package service
+
+import (
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	FROM_OPERATOR  = "from"
+	SINCE_OPERATOR = "since"
+	UNTIL_OPERATOR = "until"
+	HAS_OPERATOR   = "has"
+	IS_OPERATOR    = "is"
+
+	SEARCH_DATE_FORMAT = "2006-01-02"
+)
+
+// searchRequest is a parsed search whose authors and mentions are still
+// usernames.
+type searchRequest struct {
+	query    models.SearchQuery
+	authors  []string
+	mentions []string
+}
+
+// parseSearchQuery reads a search:
+//
+//	word            posts with the word; several words match any of them
+//	"some phrase"   posts with the phrase as written
+//	-word           posts without the word
+//	#tag            posts with the tag
+//	@username       posts mentioning the user
+//	from:username   posts the user posted or retweeted
+//	since:date      posts from the date on, as 2006-01-02 or RFC3339
+//	until:date      posts before the date
+//	has:media       posts with media
+//	is:retweet      retweets only
+//
+// Every problem of the query is reported in the same error.
+func parseSearchQuery(text string, sort string) (searchRequest, error) {
+	request := searchRequest{query: models.SearchQuery{Terms: []string{}, Phrases: []string{}, Sort: sort}}
+	problems := []string{}
+
+	if request.query.Sort == "" {
+		request.query.Sort = models.LATEST_SORT
+	}
+
+	if request.query.Sort != models.LATEST_SORT && request.query.Sort != models.RELEVANCE_SORT {
+		problems = append(problems, "there is no sort like "+sort)
+	}
+
+	tokens, closed := splitSearchQuery(text)
+
+	if !closed {
+		// The last token is the one that opened the phrase.
+		tokens = tokens[:len(tokens)-1]
+		problems = append(problems, "a phrase is missing its closing quote")
+	}
+
+	for _, token := range tokens {
+		if problem := request.add(token); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	empty := request.isEmpty()
+
+	if empty && len(problems) == 0 {
+		problems = append(problems, "there is nothing to search")
+	}
+
+	query := request.query
+
+	if query.Since != nil && query.Until != nil && !query.Since.Before(*query.Until) {
+		problems = append(problems, "since: must be before until:")
+	}
+
+	if query.Sort == models.RELEVANCE_SORT && !query.HasText() && !empty {
+		problems = append(problems, "sorting by relevance needs words to search")
+	}
+
+	if len(problems) > 0 {
+		return searchRequest{}, postErrors.InvalidSearchQuery(strings.Join(problems, "; "))
+	}
+
+	return request, nil
+}
+
+// splitSearchQuery splits text on the spaces outside quotes. It reports
+// whether every quote was closed.
+func splitSearchQuery(text string) ([]string, bool) {
+	tokens := []string{}
+	current := strings.Builder{}
+	quoted := false
+
+	for _, r := range text {
+		if r == '"' {
+			quoted = !quoted
+		}
+
+		if unicode.IsSpace(r) && !quoted {
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, !quoted
+}
+
+func (r *searchRequest) isEmpty() bool {
+	q := r.query
+	return !q.HasText() && len(q.Excluded) == 0 && len(r.authors) == 0 && len(q.Tags) == 0 &&
+		len(r.mentions) == 0 && q.Since == nil && q.Until == nil && !q.HasMedia && !q.IsRetweet
+}
+
+// add reads one token of the query into r. It returns what is wrong with the
+// token, if anything.
+func (r *searchRequest) add(token string) string {
+	switch {
+	case strings.HasPrefix(token, "\"") && strings.HasSuffix(token, "\"") && len(token) > 1:
+		phrase := strings.Join(strings.Fields(strings.Trim(token, "\"")), " ")
+
+		if len(models.SearchTokens(phrase)) == 0 {
+			return token + " has no words"
+		}
+		r.query.Phrases = append(r.query.Phrases, phrase)
+
+	case strings.Contains(token, "\""):
+		return token + ": quotes must go around a whole phrase"
+
+	case strings.HasPrefix(token, "-") && len(token) > 1:
+		word := token[1:]
+
+		if strings.ContainsAny(word[:1], "#@-") || isSearchOperator(word) {
+			return token + ": only words can be excluded"
+		}
+
+		words := models.SearchTokens(word)
+
+		if len(words) == 0 {
+			return token + ": there is no word to exclude"
+		}
+		r.query.Excluded = append(r.query.Excluded, words...)
+
+	case strings.HasPrefix(token, "#"):
+		tag := token[1:]
+
+		if tag == "" || strings.ContainsAny(tag, "#@:") {
+			return token + ": a hashtag needs a name"
+		}
+		r.query.Tags = append(r.query.Tags, tag)
+
+	case strings.HasPrefix(token, "@"):
+		username := token[1:]
+
+		if username == "" || strings.ContainsAny(username, "#@:") {
+			return token + ": a mention needs a username"
+		}
+		r.mentions = append(r.mentions, username)
+
+	case isSearchOperator(token):
+		return r.addOperator(token)
+
+	default:
+		r.query.Terms = append(r.query.Terms, models.SearchTokens(token)...)
+	}
+
+	return ""
+}
+
+// isSearchOperator tells whether token is one of the operator:value ones.
+// Other words with a colon are searched as words.
+func isSearchOperator(token string) bool {
+	operator, _, found := strings.Cut(token, ":")
+
+	switch strings.ToLower(operator) {
+	case FROM_OPERATOR, SINCE_OPERATOR, UNTIL_OPERATOR, HAS_OPERATOR, IS_OPERATOR:
+		return found
+	}
+	return false
+}
+
+func (r *searchRequest) addOperator(token string) string {
+	operator, value, _ := strings.Cut(token, ":")
+	operator = strings.ToLower(operator)
+
+	if value == "" {
+		return operator + ": needs a value"
+	}
+
+	switch operator {
+	case FROM_OPERATOR:
+		username := strings.TrimPrefix(value, "@")
+
+		if username == "" {
+			return token + ": needs a username"
+		}
+		r.authors = append(r.authors, username)
+
+	case SINCE_OPERATOR, UNTIL_OPERATOR:
+		date, ok := parseSearchDate(value)
+
+		if !ok {
+			return token + ": dates look like " + SEARCH_DATE_FORMAT + " or " + time.RFC3339
+		}
+
+		bound := &r.query.Since
+		if operator == UNTIL_OPERATOR {
+			bound = &r.query.Until
+		}
+
+		if *bound != nil {
+			return operator + ": can only be given once"
+		}
+		*bound = &date
+
+	case HAS_OPERATOR:
+		if strings.ToLower(value) != "media" {
+			return token + ": only has:media is supported"
+		}
+		r.query.HasMedia = true
+
+	case IS_OPERATOR:
+		if strings.ToLower(value) != "retweet" {
+			return token + ": only is:retweet is supported"
+		}
+		r.query.IsRetweet = true
+	}
+
+	return ""
+}
+
+// parseSearchDate reads a day, taken in UTC, or a moment in RFC3339.
+func parseSearchDate(value string) (time.Time, bool) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date.UTC(), true
+	}
+
+	date, err := time.Parse(SEARCH_DATE_FORMAT, value)
+
+	return date, err == nil
+}
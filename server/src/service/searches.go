@@ -1,41 +1,90 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	postErrors "server/src/all_errors"
 	"server/src/models"
+	"strings"
 	"time"
 )
 
-func (c *Service) FetchUserPostsByHashtags(hashtags []string, limitConfig models.LimitConfig, userID string, token string) ([]models.FrontPost, bool, error) {
+// FetchUserPostsByHashtags searches the posts with every one of hashtags that
+// also match query, if there is one.
+func (c *Service) FetchUserPostsByHashtags(ctx context.Context, query string, hashtags []string, limitConfig models.LimitConfig, userID string, token string) ([]models.FrontPost, bool, error) {
+	request := searchRequest{query: models.SearchQuery{Sort: models.LATEST_SORT}}
 
-	following, err := getUserFollowingWp(userID, limitConfig, token)
+	if query != "" {
+		parsed, err := parseSearchQuery(query, models.LATEST_SORT)
+		if err != nil {
+			return []models.FrontPost{}, false, err
+		}
+		request = parsed
+	}
+
+	for _, hashtag := range hashtags {
+		if hashtag = strings.TrimPrefix(hashtag, "#"); hashtag != "" {
+			request.query.Tags = append(request.query.Tags, hashtag)
+		}
+	}
+
+	if request.isEmpty() {
+		return []models.FrontPost{}, false, nil
+	}
+
+	posts, hasMore, err := c.searchPosts(ctx, request, limitConfig, userID, token)
+
+	slog.Info("Hashtags feed retrieved: ", "user_id", userID, "time", time.Now(), "count", len(posts), "hashtags", request.query.Tags, "query", query)
+
+	return posts, hasMore, err
+}
+
+func (c *Service) WordsSearch(ctx context.Context, words string, sort string, limitConfig models.LimitConfig, userID string, token string) ([]models.FrontPost, bool, error) {
+	request, err := parseSearchQuery(words, sort)
 	if err != nil {
 		return []models.FrontPost{}, false, err
 	}
 
-	posts, hasMore, err := c.db.GetUserHashtags(hashtags, following, userID, limitConfig)
+	posts, hasMore, err := c.searchPosts(ctx, request, limitConfig, userID, token)
+
+	slog.Info("Words search feed retrieved: ", "user_id", userID, "time", time.Now(), "count", len(posts), "words", words, "sort", request.query.Sort)
+
+	return posts, hasMore, err
+}
+
+// searchPosts runs request for userID once its usernames are user ids. A
+// search from users that do not exist, or mentioning one, finds nothing.
+func (c *Service) searchPosts(ctx context.Context, request searchRequest, limitConfig models.LimitConfig, userID string, token string) ([]models.FrontPost, bool, error) {
+	query := request.query
+
+	if query.Sort == models.RELEVANCE_SORT && limitConfig.Cursor != nil {
+		return []models.FrontPost{}, false, postErrors.InvalidSearchQuery("cursors can only page the latest posts")
+	}
 
+	authors, err := c.userIDs(ctx, request.authors, token)
 	if err != nil {
 		return []models.FrontPost{}, false, err
 	}
 
-	if len(posts) == 0 {
-		return []models.FrontPost{}, false, nil
+	mentions, err := c.userIDs(ctx, request.mentions, token)
+	if err != nil {
+		return []models.FrontPost{}, false, err
 	}
 
-	posts, err = addAuthorInfoToPosts(posts, token)
-
-	slog.Info("Hashtags feed retrieved: ", "user_id", userID, "time", time.Now(), "count", len(posts), "hashtags", hashtags)
+	if (len(request.authors) > 0 && len(authors) == 0) || len(mentions) < len(request.mentions) {
+		return []models.FrontPost{}, false, nil
+	}
 
-	return posts, hasMore, err
-}
+	query.Authors = authors
+	query.Mentions = mentions
 
-func (c *Service) WordsSearch(words string, limitConfig models.LimitConfig, userID string, token string) ([]models.FrontPost, bool, error) {
-	following, err := getUserFollowingWp(userID, limitConfig, token)
+	following, err := c.getFollowing(ctx, userID, token)
 	if err != nil {
 		return []models.FrontPost{}, false, err
 	}
-	posts, hasMore, err := c.db.WordSearchPosts(words, following, userID, limitConfig)
+
+	posts, hasMore, err := c.db.SearchPosts(ctx, query, following, userID, limitConfig)
 
 	if err != nil {
 		return []models.FrontPost{}, false, err
@@ -45,9 +94,26 @@ func (c *Service) WordsSearch(words string, limitConfig models.LimitConfig, user
 		return []models.FrontPost{}, false, nil
 	}
 
-	posts, err = addAuthorInfoToPosts(posts, token)
-
-	slog.Info("Words search feed retrieved: ", "user_id", userID, "time", time.Now(), "count", len(posts), "words", words)
+	posts, err = c.presentPosts(ctx, posts, userID, token)
 
 	return posts, hasMore, err
-}
\ No newline at end of file
+}
+
+// userIDs returns the ids of the users of usernames that exist.
+func (c *Service) userIDs(ctx context.Context, usernames []string, token string) ([]string, error) {
+	ids := []string{}
+
+	for _, username := range usernames {
+		id, err := c.users.GetUserID(ctx, username, token)
+
+		if errors.Is(err, ErrUnknownUser) {
+			continue
+		}
+		if err != nil {
+			return nil, usersServiceError(err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
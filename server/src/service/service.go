@@ -9,12 +9,22 @@ const (
 	FORYOU    = "foryou"
 	SINGLE    = "single"
 	RETWEET   = "retweet"
+	MENTIONS  = "mentions"
 )
 
 type Service struct {
-	db   database.Database
+	db            database.Database
+	users         UsersClient
+	notifications NotificationClient
+	authors       *AuthorHydrator
+	following     *ttlCache[[]string]
+	trending      TrendingConfig
 }
 
-func NewService(db database.Database) *Service {
-	return &Service{db: db}
+func NewService(db database.Database, users UsersClient, notifications NotificationClient) *Service {
+	following := newTTLCache[[]string](
+		envDuration("FOLLOWING_CACHE_TTL", DEFAULT_FOLLOWING_CACHE_TTL),
+		envInt("FOLLOWING_CACHE_SIZE", DEFAULT_FOLLOWING_CACHE_SIZE))
+
+	return &Service{db: db, users: users, notifications: notifications, authors: newDefaultAuthorHydrator(users), following: following, trending: LoadTrendingConfig()}
 }
\ No newline at end of file
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"time"
+)
+
+// GetTagMetrics returns the totals of tag and how many posts used it in every
+// bucket of the range, empty buckets included.
+func (c *Service) GetTagMetrics(ctx context.Context, tag string, limits models.MetricLimits) (models.TagMetrics, error) {
+	// Usage is counted by UTC day and hour.
+	limits.Timezone = "UTC"
+	r, err := parseMetricsRange(limits, models.DAY_INTERVAL, models.HOUR_INTERVAL)
+
+	if err != nil {
+		return models.TagMetrics{}, err
+	}
+
+	summary, err := c.db.GetTagSummary(ctx, tag)
+
+	if err != nil {
+		return models.TagMetrics{}, postErrors.DatabaseError(err.Error())
+	}
+
+	usage, err := c.db.GetTagUsage(ctx, tag, r.from, r.to)
+
+	if err != nil {
+		return models.TagMetrics{}, postErrors.DatabaseError(err.Error())
+	}
+
+	days := map[string]models.TagUsage{}
+	for _, day := range usage {
+		days[day.Day] = day
+	}
+
+	series := []models.MetricBucket{}
+
+	for _, start := range r.buckets() {
+		day := days[start.Format(time.DateOnly)]
+
+		count := day.Total_Tweets
+		if r.interval == models.HOUR_INTERVAL {
+			count = day.Hourly_Frecuency[start.Format("15")]
+		}
+
+		series = append(series, models.MetricBucket{Time: start.Format(time.RFC3339), Count: count})
+	}
+
+	slog.Info("Tag metrics retrieved: ", "tag", tag, "interval", r.interval, "buckets", len(series))
+
+	return models.TagMetrics{Tag: tag, Total_Tweets: summary.Total_Tweets, Trend: summary.Trend, Interval: r.interval, Series: series}, nil
+}
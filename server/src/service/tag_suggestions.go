@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"strings"
+	"time"
+)
+
+const (
+	TAG_SUGGESTIONS_LIMIT     = 10
+	MAX_TAG_SUGGESTIONS_LIMIT = 50
+)
+
+// SuggestTags completes the hashtag being typed, case and accents aside,
+// with the tags of the posts the user can see.
+func (c *Service) SuggestTags(ctx context.Context, prefix string, limit int, userID string, token string) ([]models.TagSuggestion, error) {
+	key := models.TagKey(strings.TrimPrefix(strings.TrimSpace(prefix), "#"))
+
+	if len(models.SearchTokens(key)) == 0 {
+		return nil, postErrors.InvalidTagPrefix()
+	}
+
+	if limit <= 0 {
+		limit = TAG_SUGGESTIONS_LIMIT
+	}
+	limit = min(limit, MAX_TAG_SUGGESTIONS_LIMIT)
+
+	following, err := c.getFollowing(ctx, userID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	following = append(following, userID)
+
+	suggestions, err := c.db.GetTagSuggestions(ctx, key, following, time.Now().UTC(), limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Tag suggestions retrieved: ", "user_id", userID, "prefix", prefix, "count", len(suggestions))
+
+	return suggestions, nil
+}
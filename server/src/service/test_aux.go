@@ -23,18 +23,3 @@ const (
 
 	TEST_NOT_FOLLOWING_USERNAME = "username_not_following"
 )
-
-func getTestUsername(userID string) string {
-	switch userID {
-	case TEST_USER_ONE:
-		return TEST_USER_ONE_USERNAME
-	case TEST_USER_TWO:
-		return TEST_USER_TWO_USERNAME
-	case TEST_USER_THREE:
-		return TEST_USER_THREE_USERNAME
-	case TEST_NOT_FOLLOWING_ID:
-		return TEST_NOT_FOLLOWING_USERNAME
-	default:
-		return ""
-	}
-}
\ No newline at end of file
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"time"
+)
+
+// GetTrendingPosts returns a page of the posts of window that got likes and
+// retweets fastest, among those the user can see. The window ends at now and
+// the posts are ranked as of now, so the pages of one ranking share it.
+func (c *Service) GetTrendingPosts(ctx context.Context, window string, userID string, limitConfig models.LimitConfig, now time.Time, token string) ([]models.FrontPost, bool, error) {
+	if window == "" {
+		window = models.TRENDING_DAY_WINDOW
+	}
+
+	length, ok := models.TrendingWindows[window]
+	if !ok {
+		return []models.FrontPost{}, false, postErrors.BadTrendingWindow(window)
+	}
+
+	following, err := c.getFollowing(ctx, userID, token)
+	if err != nil {
+		return []models.FrontPost{}, false, err
+	}
+
+	following = append(following, userID)
+
+	posts, hasMore, err := c.db.GetTrendingPosts(ctx, following, userID, now.Add(-length), now, limitConfig)
+
+	if err != nil {
+		return []models.FrontPost{}, false, err
+	}
+
+	if len(posts) == 0 {
+		return []models.FrontPost{}, false, nil
+	}
+
+	posts, err = c.presentPosts(ctx, posts, userID, token)
+
+	slog.Info("Trending posts retrieved: ", "user_id", userID, "window", window, "count", len(posts))
+	return posts, hasMore, err
+}
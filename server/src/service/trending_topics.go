@@ -1,18 +1,151 @@
 package service
 
 import (
+	"context"
 	"log/slog"
+	postErrors "server/src/all_errors"
+	"server/src/database"
+	"server/src/models"
 	"time"
 )
 
-func (c *Service) GetTrendingTopics() ([]string, error) {
-	topics, err := c.db.GetTrendingTopics()
+const (
+	DEFAULT_TRENDING_REFRESH_INTERVAL = time.Minute
+	DEFAULT_TRENDING_MAX_AGE          = 5 * time.Minute
+	DEFAULT_TRENDING_TOPICS_KEPT      = 100
+	TRENDING_PAGE_LIMIT               = 20
+)
+
+// TrendingConfig tunes how trending topics are computed and served.
+type TrendingConfig struct {
+	// RefreshInterval is the wait between two computations of every window.
+	RefreshInterval time.Duration
+	// A snapshot older than MaxAge is computed again when asked for, so the
+	// topics stay fresh even if no refresher is running.
+	MaxAge time.Duration
+	// Kept is how many topics of each window are saved.
+	Kept int
+}
+
+// LoadTrendingConfig reads the trending config from the environment:
+// TRENDING_REFRESH_INTERVAL, TRENDING_MAX_AGE and TRENDING_TOPICS_KEPT.
+func LoadTrendingConfig() TrendingConfig {
+	return TrendingConfig{
+		RefreshInterval: envDuration("TRENDING_REFRESH_INTERVAL", DEFAULT_TRENDING_REFRESH_INTERVAL),
+		MaxAge:          envDuration("TRENDING_MAX_AGE", DEFAULT_TRENDING_MAX_AGE),
+		Kept:            envInt("TRENDING_TOPICS_KEPT", DEFAULT_TRENDING_TOPICS_KEPT),
+	}
+}
+
+// trendingDecay is the decay per hour of a window. It is TRENDING_DECAY for a
+// day and scales with the window, so every window weighs its posts alike.
+func trendingDecay(window time.Duration) float64 {
+	return database.TRENDING_DECAY * 24 / window.Hours()
+}
+
+// refreshTrending computes and saves the snapshot of window.
+func refreshTrending(ctx context.Context, db database.Database, config TrendingConfig, window string) (models.TrendingSnapshot, error) {
+	now := time.Now().UTC()
+	length := models.TrendingWindows[window]
+
+	topics, err := db.ComputeTrendingTopics(ctx, now.Add(-length), now, trendingDecay(length), config.Kept)
+
+	if err != nil {
+		return models.TrendingSnapshot{}, err
+	}
+
+	snapshot := models.TrendingSnapshot{Window: window, Computed_At: now, Topics: topics}
+
+	if err := db.SaveTrendingTopics(ctx, snapshot); err != nil {
+		return models.TrendingSnapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+// TrendingRefresher computes the trending topics of every window in the
+// background, so requests only read the saved snapshots.
+type TrendingRefresher struct {
+	db     database.Database
+	config TrendingConfig
+}
+
+func NewTrendingRefresher(db database.Database, config TrendingConfig) *TrendingRefresher {
+	return &TrendingRefresher{db: db, config: config}
+}
+
+// Run refreshes every window each RefreshInterval until ctx is done.
+func (r *TrendingRefresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RefreshOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Error refreshing trending topics: ", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshOnce computes and saves the snapshot of every window.
+func (r *TrendingRefresher) RefreshOnce(ctx context.Context) error {
+	for window := range models.TrendingWindows {
+		if _, err := refreshTrending(ctx, r.db, r.config, window); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetTrendingTopics returns a page of the saved trending topics of window.
+func (c *Service) GetTrendingTopics(ctx context.Context, window string, limitConfig models.LimitConfig) (models.ReturnPaginatesdTrendingTopics, error) {
+	if window == "" {
+		window = models.TRENDING_DAY_WINDOW
+	}
+
+	if _, ok := models.TrendingWindows[window]; !ok {
+		return models.ReturnPaginatesdTrendingTopics{}, postErrors.BadTrendingWindow(window)
+	}
+
+	snapshot, err := c.db.GetTrendingTopics(ctx, window)
 
 	if err != nil {
-		return []string{}, err
+		return models.ReturnPaginatesdTrendingTopics{}, postErrors.DatabaseError(err.Error())
+	}
+
+	if time.Since(snapshot.Computed_At) > c.trending.MaxAge {
+		snapshot, err = refreshTrending(ctx, c.db, c.trending, window)
+
+		if err != nil {
+			return models.ReturnPaginatesdTrendingTopics{}, postErrors.DatabaseError(err.Error())
+		}
+	}
+
+	limit := limitConfig.Limit
+	if limit <= 0 {
+		limit = TRENDING_PAGE_LIMIT
+	}
+
+	start := min(max(limitConfig.Skip, 0), len(snapshot.Topics))
+	end := min(start+limit, len(snapshot.Topics))
+
+	pagination := models.Pagination{Limit: limit}
+	if end < len(snapshot.Topics) {
+		pagination.Next_Offset = end
 	}
 
-	slog.Info("Trending topics retrieved: ", "time", time.Now(), "count", len(topics), "topics", topics)
+	slog.Info("Trending topics retrieved: ", "window", window, "computed_at", snapshot.Computed_At, "count", end-start)
 
-	return topics, nil
-}
\ No newline at end of file
+	return models.ReturnPaginatesdTrendingTopics{
+		Window:      window,
+		Computed_At: snapshot.Computed_At,
+		Topics:      snapshot.Topics[start:end],
+		Pagination:  pagination,
+	}, nil
+}
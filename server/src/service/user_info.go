@@ -1,104 +1,141 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"io"
-
-	// "log"
+	"log/slog"
 	"net/http"
-	"os"
+	neturl "net/url"
 	"server/src/models"
 	"strconv"
+	"time"
 )
 
-func getUserFollowingWp(userID string, limitConfig models.LimitConfig, token string) ([]string, error) {
-	if os.Getenv("ENVIROMENT") == "test" {
-		return []string{TEST_USER_ONE, TEST_USER_TWO, TEST_USER_THREE}, nil
-	} else {
+const (
+	FOLLOWING_PAGE_SIZE = 100
+	MAX_FOLLOWING_PAGES = 100
+)
 
-		return getUserFollowing(userID, []string{}, limitConfig, INITIAL_SKIP, token)
-	}
+// HTTPUsersClient talks to the users service.
+type HTTPUsersClient struct {
+	host     string
+	outbound *outboundClient
+}
+
+func NewHTTPUsersClient(host string, config OutboundConfig) *HTTPUsersClient {
+	return &HTTPUsersClient{host: host, outbound: newOutboundClient(USERS_DEPENDENCY, config)}
 }
 
-func getUserFollowing(userID string, following []string, limitConfig models.LimitConfig, skip int, token string) ([]string, error) {
+func (u *HTTPUsersClient) GetFollowing(ctx context.Context, userID string, token string) ([]string, error) {
+	following := []string{}
+	pages := newFollowingPages(u, userID, token)
 
-	limit := strconv.Itoa(limitConfig.Limit)
-	skipStr := strconv.Itoa(skip)
+	for pages.Next(ctx) {
+		following = append(following, pages.Page()...)
+	}
 
-	// log.Println("userID: ", userID)
-	// log.Println("token: ", token)
+	if err := pages.Err(); err != nil {
+		return nil, err
+	}
 
-	url := "http://" + os.Getenv("USERS_HOST") + "/users/" + userID + "/following" + "?timestamp=" + limitConfig.FromTime + "&skip=" + skipStr + "&limit=" + limit
+	return following, nil
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+// followingPages walks the following list of a user one page at a time. Every
+// page is asked for with the same timestamp so the pages do not shift while
+// it walks them. It gives up after MAX_FOLLOWING_PAGES pages, and fails if the
+// users service answers with an offset that does not move forward.
+type followingPages struct {
+	client    *HTTPUsersClient
+	userID    string
+	token     string
+	timestamp string
+	skip      int
+	fetched   int
+	done      bool
+	page      []string
+	err       error
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
+func newFollowingPages(client *HTTPUsersClient, userID string, token string) *followingPages {
+	return &followingPages{client: client, userID: userID, token: token, skip: INITIAL_SKIP,
+		timestamp: time.Now().UTC().Format(time.RFC3339)}
+}
 
-	if err != nil {
-		return nil, errors.New("error creating request")
+// Next fetches the next page and reports whether there was one.
+func (p *followingPages) Next(ctx context.Context) bool {
+	if p.done {
+		return false
 	}
 
-	resp, err := http.DefaultClient.Do(req)
-
-	if err != nil {
-		return nil, errors.New("error sending request, " + err.Error())
+	if p.fetched == MAX_FOLLOWING_PAGES {
+		slog.Warn("Following list too long, keeping the first pages", "user_id", p.userID, "pages", p.fetched)
+		p.done = true
+		return false
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	url := "http://" + p.client.host + "/users/" + p.userID + "/following" + "?timestamp=" + p.timestamp +
+		"&skip=" + strconv.Itoa(p.skip) + "&limit=" + strconv.Itoa(FOLLOWING_PAGE_SIZE)
+
+	body, err := p.client.get(ctx, url, p.token)
 
 	if err != nil {
-		return nil, err
+		return p.fail(err)
 	}
 
 	user := struct {
 		Data       []models.UserInfoExpectedFormat `json:"data"`
 		Pagination models.Pagination               `json:"pagination"`
 	}{}
-	err = json.Unmarshal(body, &user)
 
-	if err != nil {
-		return nil, err
+	if err := json.Unmarshal(body, &user); err != nil {
+		return p.fail(errors.New("error unmarshaling request, " + err.Error()))
 	}
 
+	p.page = make([]string, 0, len(user.Data))
 	for _, data := range user.Data {
-		following = append(following, data.Profile.ID)
+		p.page = append(p.page, data.Profile.ID)
 	}
 
-	if user.Pagination.Next_Offset != 0 {
+	p.fetched++
 
-		newLimit := models.NewLimitConfig(limitConfig.FromTime, limit, strconv.Itoa(user.Pagination.Next_Offset+limitConfig.Skip))
-
-		return getUserFollowing(userID, following, newLimit, skip+limitConfig.Skip, token)
+	switch {
+	case user.Pagination.Next_Offset == 0:
+		p.done = true
+	case user.Pagination.Next_Offset <= p.skip:
+		return p.fail(errors.New("error paging following, offset " + strconv.Itoa(user.Pagination.Next_Offset) +
+			" does not follow " + strconv.Itoa(p.skip)))
+	default:
+		p.skip = user.Pagination.Next_Offset
 	}
 
-	return following, nil
+	return true
 }
 
-func getUserData(userID string, token string) (models.AuthorInfo, error) {
-
-	url := "http://" + os.Getenv("USERS_HOST") + "/users/" + userID
-
-	req, err := http.NewRequest("GET", url, nil)
+func (p *followingPages) Page() []string {
+	return p.page
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
+func (p *followingPages) Err() error {
+	return p.err
+}
 
-	if err != nil {
-		return models.AuthorInfo{}, errors.New("error creating request")
-	}
+func (p *followingPages) fail(err error) bool {
+	p.err = err
+	p.done = true
+	p.page = nil
+	return false
+}
 
-	resp, err := http.DefaultClient.Do(req)
+func (u *HTTPUsersClient) GetAuthorInfo(ctx context.Context, userID string, token string) (models.AuthorInfo, error) {
 
-	if err != nil {
-		return models.AuthorInfo{}, errors.New("error sending request, " + err.Error())
-	}
+	url := "http://" + u.host + "/users/" + userID
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := u.get(ctx, url, token)
 
 	if err != nil {
-		return models.AuthorInfo{}, errors.New("error reading request, " + err.Error())
+		return models.AuthorInfo{}, err
 	}
 
 	user := struct {
@@ -117,38 +154,13 @@ func getUserData(userID string, token string) (models.AuthorInfo, error) {
 	return authorInfo, nil
 }
 
-func getUserInterestsWp(userID string, token string) ([]string, error) {
-	if os.Getenv("ENVIROMENT") == "test" && userID != TEST_USER_NO_TAGS {
-		return []string{TEST_TAG_ONE, TEST_TAG_TWO, TEST_TAG_THREE}, nil
-	} else if os.Getenv("ENVIROMENT") == "test" && userID == TEST_USER_NO_TAGS {
-		return []string{}, nil
-	} else {
-		return getUsersInterests(userID, token)
-	}
-}
-
-func getUsersInterests(userID string, token string) ([]string, error) {
-	url := "http://" + os.Getenv("USERS_HOST") + "/users/" + userID
-
-	req, err := http.NewRequest("GET", url, nil)
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	if err != nil {
-		return []string{}, errors.New("error creating request")
-	}
-
-	resp, err := http.DefaultClient.Do(req)
+func (u *HTTPUsersClient) GetInterests(ctx context.Context, userID string, token string) ([]string, error) {
+	url := "http://" + u.host + "/users/" + userID
 
-	if err != nil {
-		return []string{}, errors.New("error sending request, " + err.Error())
-	}
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := u.get(ctx, url, token)
 
 	if err != nil {
-		return []string{}, errors.New("error reading request, " + err.Error())
+		return []string{}, err
 	}
 
 	user := struct {
@@ -164,78 +176,30 @@ func getUsersInterests(userID string, token string) ([]string, error) {
 	return user.Profile.Interests, nil
 }
 
-func addAuthorInfoToPost(post models.FrontPost, token string) (models.FrontPost, error) {
+func (u *HTTPUsersClient) GetUserID(ctx context.Context, username string, token string) (string, error) {
+	url := "http://" + u.host + "/users?username=" + neturl.QueryEscape(username)
 
-	var authorInfo models.AuthorInfo
-	var err error
+	body, err := u.get(ctx, url, token)
 
-	if os.Getenv("ENVIROMENT") == "test" {
-		authorInfo, err = getUserDataForTests(post)
-	} else {
-		authorInfo, err = getUserData(post.Author_Info.Author_ID, token)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.Status == http.StatusNotFound {
+		return "", ErrUnknownUser
 	}
 
 	if err != nil {
-		return models.FrontPost{}, errors.New("error getting info on the user, " + err.Error())
-	}
-
-	post.Author_Info = authorInfo
-
-	if post.Is_Retweet {
-		post, err = addRetweetAuthorInfoToPost(post, token)
-		if err != nil {
-			return models.FrontPost{}, errors.New("error getting info on the user, " + err.Error())
-		}
-	} else {
-		post.Retweet_Author = ""
+		return "", err
 	}
 
-	return post, nil
-}
-
-func addRetweetAuthorInfoToPost(post models.FrontPost, token string) (models.FrontPost, error) {
-	var authorInfo models.AuthorInfo
-	var err error
-
-	if os.Getenv("ENVIROMENT") == "test" {
-		authorInfo, err = getUserDataRetweetForTests(post)
-	} else {
-		authorInfo, err = getUserData(post.Retweet_Author, token)
-	}
+	user := models.UserInfoExpectedFormat{}
+	err = json.Unmarshal(body, &user)
 
 	if err != nil {
-		return models.FrontPost{}, errors.New("error getting info on the user, " + err.Error())
-	}
-
-	post.Retweet_Author = authorInfo.Username
-
-	return post, nil
-}
-
-func addAuthorInfoToPosts(posts []models.FrontPost, token string) ([]models.FrontPost, error) {
-	for i, post := range posts {
-		post, err := addAuthorInfoToPost(post, token)
-		if err != nil {
-			return nil, err
-		}
-
-		posts[i] = post
+		return "", errors.New("error unmarshaling request, " + err.Error())
 	}
-	return posts, nil
-}
-
-func getUserDataForTests(post models.FrontPost) (models.AuthorInfo, error) {
-
-	authorInfo := models.AuthorInfo{Author_ID: post.Author_Info.Author_ID, Username: getTestUsername(post.Author_Info.Author_ID),
-		Alias: "alias", PthotoURL: ""}
 
-	return authorInfo, nil
+	return user.Profile.ID, nil
 }
 
-func getUserDataRetweetForTests(post models.FrontPost) (models.AuthorInfo, error) {
-
-	authorInfo := models.AuthorInfo{Author_ID: post.Retweet_Author, Username: getTestUsername(post.Retweet_Author),
-		Alias: "alias", PthotoURL: ""}
-
-	return authorInfo, nil
+func (u *HTTPUsersClient) get(ctx context.Context, url string, token string) ([]byte, error) {
+	return u.outbound.do(ctx, http.MethodGet, url, token, nil)
 }
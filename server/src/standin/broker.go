@@ -0,0 +1,59 @@
+package standin
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// BrokerMessage is a message as received by the stand-in broker.
+type BrokerMessage struct {
+	Exchange   string
+	RoutingKey string
+	MessageID  string
+	Body       []byte
+}
+
+// Broker stands in for the message broker. It keeps every message it
+// accepts, in the order they were published.
+type Broker struct {
+	mu       sync.Mutex
+	messages []BrokerMessage
+	err      error
+}
+
+func NewBroker() *Broker {
+	return &Broker{}
+}
+
+// Fail makes every following publish return err, as a broker that is down
+// or refuses the message would. A nil err clears it.
+func (b *Broker) Fail(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.err = err
+}
+
+// Messages returns the messages accepted so far, oldest first.
+func (b *Broker) Messages() []BrokerMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.messages)
+}
+
+func (b *Broker) Publish(ctx context.Context, exchange string, routingKey string, messageID string, body []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+
+	b.messages = append(b.messages, BrokerMessage{Exchange: exchange, RoutingKey: routingKey, MessageID: messageID, Body: slices.Clone(body)})
+
+	return nil
+}
@@ -0,0 +1,148 @@
+// Package standin has small stand-ins for the services the feed depends on,
+// so the HTTP clients can be run against something that answers like them.
+package standin
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/src/models"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// User is a user of the stand-in users service.
+type User struct {
+	ID          string   `json:"id"`
+	FirstName   string   `json:"first_name"`
+	LastName    string   `json:"last_name"`
+	Username    string   `json:"username"`
+	PicturePath string   `json:"picture_path"`
+	Interests   []string `json:"interests"`
+	Following   []string `json:"following"`
+}
+
+// UsersService answers the users service endpoints the feed calls:
+// GET /users/{id}, GET /users/{id}/following and GET /users?username=.
+type UsersService struct {
+	mu    sync.RWMutex
+	users map[string]User
+}
+
+func NewUsersService(users ...User) *UsersService {
+	service := &UsersService{users: map[string]User{}}
+	for _, user := range users {
+		service.AddUser(user)
+	}
+	return service
+}
+
+// AddUser adds user, replacing any user with the same id.
+func (s *UsersService) AddUser(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[user.ID] = user
+}
+
+func (s *UsersService) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", s.getUser)
+	mux.HandleFunc("GET /users/{id}/following", s.getFollowing)
+	mux.HandleFunc("GET /users", s.findUser)
+	return mux
+}
+
+func (s *UsersService) getUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	user, ok := s.users[r.PathValue("id")]
+	s.mu.RUnlock()
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"title": "User not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Following bool                                `json:"following"`
+		Profile   models.PrivateProfileExpectedFormat `json:"profile"`
+	}{Profile: privateProfile(user)})
+}
+
+// findUser looks a user up by the username query parameter.
+func (s *UsersService) findUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	username := r.URL.Query().Get("username")
+
+	for _, user := range s.users {
+		if username != "" && user.Username == username {
+			writeJSON(w, http.StatusOK, models.UserInfoExpectedFormat{Profile: publicProfile(user)})
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusNotFound, map[string]string{"title": "User not found"})
+}
+
+// getFollowing pages the followed users with skip and limit. next_offset is
+// left out on the last page, like the users service does.
+func (s *UsersService) getFollowing(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[r.PathValue("id")]
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"title": "User not found"})
+		return
+	}
+
+	skip, err := strconv.Atoi(r.URL.Query().Get("skip"))
+	if err != nil || skip < 0 {
+		skip = 0
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = len(user.Following)
+	}
+
+	data := []models.UserInfoExpectedFormat{}
+
+	for _, followedID := range user.Following[min(skip, len(user.Following)):min(skip+limit, len(user.Following))] {
+		followed, ok := s.users[followedID]
+		if !ok {
+			followed = User{ID: followedID}
+		}
+		data = append(data, models.UserInfoExpectedFormat{Follows: true, Profile: publicProfile(followed)})
+	}
+
+	pagination := models.Pagination{Limit: limit}
+
+	if skip+limit < len(user.Following) {
+		pagination.Next_Offset = skip + limit
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Data       []models.UserInfoExpectedFormat `json:"data"`
+		Pagination models.Pagination               `json:"pagination"`
+	}{data, pagination})
+}
+
+func publicProfile(user User) models.PublicProfileExpectedFormat {
+	return models.PublicProfileExpectedFormat{ID: user.ID, FisrtName: user.FirstName, LastName: user.LastName,
+		Username: user.Username, Following: len(user.Following), PicturePath: user.PicturePath}
+}
+
+func privateProfile(user User) models.PrivateProfileExpectedFormat {
+	return models.PrivateProfileExpectedFormat{ID: user.ID, FisrtName: user.FirstName, LastName: user.LastName,
+		Username: user.Username, Interests: slices.Clone(user.Interests), Following: len(user.Following),
+		PicturePath: user.PicturePath}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
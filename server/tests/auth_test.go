@@ -13,7 +13,6 @@ import (
 	"net/http/httptest"
 
 	postErrors "server/src/all_errors"
-	"server/src/router"
 
 	"net/http"
 )
@@ -37,7 +36,7 @@ func TestAuthenticationTokenErrorHeaderRequired(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	postBody := PostBody{Content: "", Tags: []string{"tag1", "tag2"}, Public: true, Mentions: []string{}}
 	req := newPostRequest(postBody)
@@ -68,7 +67,7 @@ func TestAuthenticationErrorInvalidHeader(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	postBody := PostBody{Content: "", Tags: []string{"tag1", "tag2"}, Public: true, Mentions: []string{}}
 	req := newPostRequest(postBody)
@@ -101,7 +100,7 @@ func TestAuthenticationErrorInvalidToken(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1234"
 	postBody := PostBody{Content: "content #tag1 #tag2", Tags: []string{"tag1", "tag2"}, Mentions: []string{}, Public: true}
@@ -0,0 +1,222 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/auth"
+	"server/src/database"
+	"server/src/models"
+	"server/src/standin"
+)
+
+// countingUsersServer serves the stand-in users service and records how many
+// times each user was asked for and how many requests ran at once.
+type countingUsersServer struct {
+	mu        sync.Mutex
+	users     *standin.UsersService
+	hits      map[string]int
+	inFlight  int
+	maxFlight int
+	delay     time.Duration
+}
+
+// newCountingUsersServer starts a users service whose users are named after
+// their id, and returns it with a router that fetches authors from it.
+func newCountingUsersServer(t *testing.T, db database.Database, delay time.Duration, userIDs ...string) (*countingUsersServer, *gin.Engine) {
+	users := &countingUsersServer{users: standin.NewUsersService(), hits: map[string]int{}, delay: delay}
+
+	for _, userID := range userIDs {
+		users.users.AddUser(standin.User{ID: userID, FirstName: "first" + userID, LastName: "last" + userID,
+			Username: "user" + userID, PicturePath: "pic" + userID})
+	}
+
+	server := httptest.NewServer(users.count(users.users.Handler()))
+	t.Cleanup(server.Close)
+
+	usersClient := newUsersClient(server.URL, testOutboundConfig())
+
+	return users, createRouterWithUsersClient(db, usersClient)
+}
+
+func (s *countingUsersServer) count(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits[strings.TrimPrefix(r.URL.Path, "/users/")]++
+		s.inFlight++
+		s.maxFlight = max(s.maxFlight, s.inFlight)
+		s.mu.Unlock()
+
+		time.Sleep(s.delay)
+		next.ServeHTTP(w, r)
+
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	})
+}
+
+func (s *countingUsersServer) hitsFor(userID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[userID]
+}
+
+func (s *countingUsersServer) totalHits() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, hits := range s.hits {
+		total += hits
+	}
+	return total
+}
+
+func seedPosts(t *testing.T, db database.Database, authors []string) []models.FrontPost {
+	posts := []models.FrontPost{}
+
+	for _, author := range authors {
+		post, err := db.AddNewPost(context.Background(), models.NewDBPost(author, "content by "+author, []string{}, true, models.MediaInfo{}, []string{}))
+		assert.Equal(t, nil, err, "Error should be nil")
+		posts = append(posts, post)
+	}
+
+	return posts
+}
+
+func adminToken(t *testing.T) string {
+	token, err := auth.GenerateToken("1", "username", true)
+	assert.Equal(t, nil, err, "Error should be nil")
+	return token
+}
+
+func getAllPostsRequest(token string) *http.Request {
+	from := time.Now().Add(time.Minute).Format(time.RFC3339)
+
+	getAll, _ := http.NewRequest("GET", "/twitsnap/all?time="+from+"&skip=0&limit=20", nil)
+	addAuthorization(getAll, token)
+
+	return getAll
+}
+
+func getAllPosts(t *testing.T, r http.Handler) models.ReturnPaginatedPosts {
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, getAllPostsRequest(adminToken(t)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, "Status should be 200")
+
+	result := models.ReturnPaginatedPosts{}
+	assert.Equal(t, nil, json.Unmarshal(recorder.Body.Bytes(), &result), "Error should be nil")
+
+	return result
+}
+
+func TestAuthorsFetchedOncePerPage(t *testing.T) {
+	log.Println("TestAuthorsFetchedOncePerPage")
+
+	db := connectToDatabase()
+	users, r := newCountingUsersServer(t, db, 0, "a", "b", "c")
+
+	posts := seedPosts(t, db, []string{"a", "b", "a", "c", "b", "a"})
+
+	_, err := db.AddNewRetweet(context.Background(), models.NewRetweetDBPost(posts[1], "c"))
+	assert.Equal(t, nil, err, "Error should be nil")
+
+	result := getAllPosts(t, r)
+
+	assert.Equal(t, 7, len(result.Data))
+	assert.Equal(t, 1, users.hitsFor("a"))
+	assert.Equal(t, 1, users.hitsFor("b"))
+	assert.Equal(t, 1, users.hitsFor("c"))
+
+	for _, post := range result.Data {
+		author := post.Author_Info.Author_ID
+		assert.Equal(t, "user"+author, post.Author_Info.Username)
+		assert.Equal(t, "first"+author+" last"+author, post.Author_Info.Alias)
+		assert.Equal(t, "pic"+author, post.Author_Info.PthotoURL)
+
+		if post.Is_Retweet {
+			assert.Equal(t, "userc", post.Retweet_Author)
+		} else {
+			assert.Equal(t, "", post.Retweet_Author)
+		}
+	}
+}
+
+func TestAuthorsServedFromCache(t *testing.T) {
+	log.Println("TestAuthorsServedFromCache")
+
+	db := connectToDatabase()
+	users, r := newCountingUsersServer(t, db, 0, "a", "b")
+
+	seedPosts(t, db, []string{"a", "b"})
+
+	first := getAllPosts(t, r)
+	assert.Equal(t, 2, users.totalHits())
+
+	second := getAllPosts(t, r)
+	assert.Equal(t, 2, users.totalHits(), "Second page should not call the users service")
+	assert.Equal(t, first.Data, second.Data)
+}
+
+func TestAuthorCacheExpires(t *testing.T) {
+	log.Println("TestAuthorCacheExpires")
+
+	t.Setenv("AUTHOR_CACHE_TTL", "50ms")
+
+	db := connectToDatabase()
+	users, r := newCountingUsersServer(t, db, 0, "a")
+
+	seedPosts(t, db, []string{"a"})
+
+	getAllPosts(t, r)
+	time.Sleep(100 * time.Millisecond)
+	getAllPosts(t, r)
+
+	assert.Equal(t, 2, users.hitsFor("a"))
+}
+
+func TestAuthorCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	log.Println("TestAuthorCacheEvictsLeastRecentlyUsed")
+
+	t.Setenv("AUTHOR_CACHE_SIZE", "2")
+
+	db := connectToDatabase()
+	users, r := newCountingUsersServer(t, db, 0, "a", "b", "c")
+
+	seedPosts(t, db, []string{"a", "b", "c"})
+
+	getAllPosts(t, r)
+	getAllPosts(t, r)
+
+	// Three authors do not fit in two entries, so every page misses one.
+	assert.Less(t, 3, users.totalHits())
+}
+
+func TestAuthorFetchParallelismIsBounded(t *testing.T) {
+	log.Println("TestAuthorFetchParallelismIsBounded")
+
+	t.Setenv("AUTHOR_FETCH_PARALLEL", "2")
+
+	db := connectToDatabase()
+	users, r := newCountingUsersServer(t, db, 30*time.Millisecond, "a", "b", "c", "d", "e", "f")
+
+	seedPosts(t, db, []string{"a", "b", "c", "d", "e", "f"})
+
+	result := getAllPosts(t, r)
+
+	assert.Equal(t, 6, len(result.Data))
+	assert.Equal(t, 6, users.totalHits())
+	assert.LessOrEqual(t, users.maxFlight, 2)
+}
@@ -7,7 +7,6 @@ import (
 	"net/http/httptest"
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 	"testing"
 	"time"
@@ -20,7 +19,7 @@ func TestBlockedPost(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 	retweeter_id := service.TEST_USER_TWO
@@ -64,7 +63,7 @@ func TestBlockeedInFeedFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{"tag1", "tag2"}
 
@@ -83,7 +82,7 @@ func TestBlockeedInFeedFollowing(t *testing.T) {
 	assert.Equal(t, err, nil, "Error should be nil")
 
 	retweeter := service.TEST_USER_TWO
-	tokenRetweeterer, err := auth.GenerateToken(retweeter, service.TEST_USER_TWO_USERNAME, false)
+	tokenRetweeterer, err := auth.GenerateToken(retweeter, service.TEST_USER_TWO_USERNAME, true)
 
 	assert.Equal(t, err, nil, "Error should be nil")
 
@@ -129,7 +128,7 @@ func TestUnBlockAPost(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1"
 	retweeter_id := service.TEST_USER_TWO
@@ -187,7 +186,7 @@ func TestBlockInFeedForyou(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag5"}
 
@@ -211,7 +210,7 @@ func TestBlockInFeedForyou(t *testing.T) {
 
 	retweeter := service.TEST_USER_TWO
 	username := service.TEST_USER_TWO_USERNAME
-	tokenRetweeterer, err := auth.GenerateToken(retweeter, username, false)
+	tokenRetweeterer, err := auth.GenerateToken(retweeter, username, true)
 
 	assert.Equal(t, err, nil, "Error should be nil")
 
@@ -256,7 +255,7 @@ func TestBlockInSingleRetweeterFeed(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag2"}
 
@@ -276,7 +275,7 @@ func TestBlockInSingleRetweeterFeed(t *testing.T) {
 
 	retweeter := service.TEST_USER_ONE
 	username := service.TEST_USER_ONE_USERNAME
-	tokenRetweeterer, err := auth.GenerateToken(retweeter, username, false)
+	tokenRetweeterer, err := auth.GenerateToken(retweeter, username, true)
 
 	assert.Equal(t, err, nil, "Error should be nil")
 
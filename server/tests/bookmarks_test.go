@@ -7,7 +7,6 @@ import (
 	"net/http/httptest"
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 	"testing"
 	"time"
@@ -22,7 +21,7 @@ func TestBookmarkPost(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 	bookmarker_id := service.TEST_USER_TWO
@@ -66,7 +65,7 @@ func TestUnBookmarkPost(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 	bookmarker_id := service.TEST_USER_TWO
@@ -132,7 +131,7 @@ func TestFetchBookmarkedPosts(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	bookmarker_id := service.TEST_USER_TWO
 
@@ -197,7 +196,7 @@ func TestBookmarkReTweetedPost(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 	bookmarker_id := service.TEST_USER_TWO
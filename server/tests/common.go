@@ -11,6 +11,7 @@ import (
 	"server/src/auth"
 	"server/src/database"
 	"server/src/models"
+	"server/src/router"
 	"server/src/service"
 	"slices"
 	"strings"
@@ -44,7 +45,45 @@ func addAuthorization(req *http.Request, token string) {
 	req.Header.Add("Authorization", "Bearer "+token)
 }
 
+const (
+	TEST_DATABASE_ENV = "TEST_DATABASE"
+	MONGO_BACKEND     = "mongo"
+	MEMORY_BACKEND    = "memory"
+)
+
+// testDatabaseBackend picks the backend the router tests run against. It can be
+// forced with TEST_DATABASE=mongo|memory; otherwise MongoDB is used whenever
+// MONGO_URI is set.
+func testDatabaseBackend() string {
+	switch os.Getenv(TEST_DATABASE_ENV) {
+	case MONGO_BACKEND:
+		return MONGO_BACKEND
+	case MEMORY_BACKEND:
+		return MEMORY_BACKEND
+	}
+
+	if os.Getenv("MONGO_URI") != "" {
+		return MONGO_BACKEND
+	}
+	return MEMORY_BACKEND
+}
+
+// createRouter builds the router with the fake users and notification
+// clients, seeded with the test users.
+func createRouter(db database.Database) *gin.Engine {
+	return router.CreateRouter(db, service.NewFakeUsersClient(), service.NewFakeNotificationClient())
+}
+
 func connectToDatabase() database.Database {
+	if testDatabaseBackend() == MEMORY_BACKEND {
+		log.Println("Using in-memory database")
+		return database.NewMemoryDatabase()
+	}
+
+	return connectToMongoDatabase()
+}
+
+func connectToMongoDatabase() database.Database {
 
 	log.Println("Connect to database")
 
@@ -59,12 +98,16 @@ func connectToDatabase() database.Database {
 
 	db := database.NewAppDatabase(client)
 
-	err_2 := db.ClearDB()
+	err_2 := db.ClearDB(context.Background())
 
 	if err_2 != nil {
 		log.Fatal("Error clearing database: ", err)
 	}
 
+	if err := database.RunMigrations(context.Background(), client); err != nil {
+		log.Fatal("Error running migrations: ", err)
+	}
+
 	return db
 }
 
@@ -0,0 +1,1351 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	postErrors "server/src/all_errors"
+	"server/src/database"
+	"server/src/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The conformance suite runs the same scenarios against every Database
+// implementation so the in-memory backend keeps AppDatabase's semantics.
+
+func TestMemoryDatabaseConformance(t *testing.T) {
+	runDatabaseConformance(t, database.NewMemoryDatabase)
+}
+
+func TestMongoDatabaseConformance(t *testing.T) {
+	if os.Getenv("MONGO_URI") == "" {
+		t.Skip("MONGO_URI is not set")
+	}
+	runDatabaseConformance(t, connectToMongoDatabase)
+}
+
+type conformanceCase struct {
+	name string
+	run  func(t *testing.T, ctx context.Context, db database.Database)
+}
+
+func runDatabaseConformance(t *testing.T, newDatabase func() database.Database) {
+	cases := []conformanceCase{
+		{"AddAndGetPost", conformanceAddAndGetPost},
+		{"GetMissingPost", conformanceGetMissingPost},
+		{"BlockHidesPost", conformanceBlockHidesPost},
+		{"DeletePostRemovesRetweets", conformanceDeletePostRemovesRetweets},
+		{"EditPost", conformanceEditPost},
+		{"Revisions", conformanceRevisions},
+		{"LikeAndUnlike", conformanceLikeAndUnlike},
+		{"RetweetAndDeleteRetweet", conformanceRetweetAndDeleteRetweet},
+		{"Bookmarks", conformanceBookmarks},
+		{"FeedFollowing", conformanceFeedFollowing},
+		{"FeedInterests", conformanceFeedInterests},
+		{"FeedSingleAndRetweet", conformanceFeedSingleAndRetweet},
+		{"FeedMentions", conformanceFeedMentions},
+		{"Conversations", conformanceConversations},
+		{"ConversationThread", conformanceConversationThread},
+		{"Quotes", conformanceQuotes},
+		{"Searches", conformanceSearches},
+		{"SearchRelevanceAndPhrases", conformanceSearchRelevanceAndPhrases},
+		{"SearchOperators", conformanceSearchOperators},
+		{"AllPosts", conformanceAllPosts},
+		{"Metrics", conformanceMetrics},
+		{"MetricsSeries", conformanceMetricsSeries},
+		{"PlatformMetricsSeries", conformancePlatformMetricsSeries},
+		{"TrendingTopics", conformanceTrendingTopics},
+		{"TrendingPosts", conformanceTrendingPosts},
+		{"TagMetrics", conformanceTagMetrics},
+		{"TagSuggestions", conformanceTagSuggestions},
+		{"CancelledContext", conformanceCancelledContext},
+		{"CountersFollowMembership", conformanceCountersFollowMembership},
+		{"FailedWritesLeaveNothingPartial", conformanceFailedWritesLeaveNothingPartial},
+		{"CursorPagination", conformanceCursorPagination},
+		{"PageViewerStateMatchesSinglePost", conformancePageViewerStateMatchesSinglePost},
+		{"Outbox", conformanceOutbox},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newDatabase()
+			assert.Nil(t, db.ClearDB(ctx))
+			c.run(t, ctx, db)
+		})
+	}
+}
+
+func conformanceBaseTime() time.Time {
+	return time.Now().UTC().Add(-time.Hour).Truncate(time.Millisecond)
+}
+
+func conformanceNow() models.LimitConfig {
+	return models.NewLimitConfig(time.Now().UTC().Add(time.Minute).Format(time.RFC3339), "0", "10")
+}
+
+func insertConformancePost(t *testing.T, ctx context.Context, db database.Database, authorID string, content string, tags []string, public bool, postTime time.Time) models.DBPost {
+	post := models.NewDBPost(authorID, content, tags, public, models.MediaInfo{}, []string{})
+	post.Time = postTime
+
+	_, err := db.AddNewPost(ctx, post)
+	assert.Nil(t, err)
+
+	return post
+}
+
+func insertConformanceRetweet(t *testing.T, ctx context.Context, db database.Database, original models.DBPost, retweeterID string, postTime time.Time) models.FrontPost {
+	front, err := db.GetPost(ctx, original.Post_ID, retweeterID)
+	assert.Nil(t, err)
+
+	retweet := models.NewRetweetDBPost(front, retweeterID)
+	retweet.Time = postTime
+
+	result, err := db.AddNewRetweet(ctx, retweet)
+	assert.Nil(t, err)
+
+	return result
+}
+
+func postContents(posts []models.FrontPost) []string {
+	contents := []string{}
+	for _, post := range posts {
+		contents = append(contents, post.Content)
+	}
+	return contents
+}
+
+func conformanceAddAndGetPost(t *testing.T, ctx context.Context, db database.Database) {
+	post := models.NewDBPost("1", "hello #go", []string{"go"}, true, models.MediaInfo{Media_URL: "url", Media_Type: "IMAGE"}, nil)
+
+	created, err := db.AddNewPost(ctx, post)
+	assert.Nil(t, err)
+	assert.Equal(t, post.Post_ID, created.Post_ID)
+	assert.Equal(t, "1", created.Author_Info.Author_ID)
+	assert.Equal(t, []string{}, created.Mentions)
+
+	fetched, err := db.GetPost(ctx, post.Post_ID, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello #go", fetched.Content)
+	assert.Equal(t, []string{"go"}, fetched.Tags)
+	assert.Equal(t, "url", fetched.Media_Info.Media_URL)
+	assert.Equal(t, post.Time.Truncate(time.Millisecond).Format(time.RFC3339Nano), fetched.Time)
+	assert.False(t, fetched.User_Liked)
+	assert.False(t, fetched.User_Retweet)
+	assert.False(t, fetched.Bookmark)
+}
+
+func conformanceGetMissingPost(t *testing.T, ctx context.Context, db database.Database) {
+	_, err := db.GetPost(ctx, "missing", "1")
+	assert.ErrorIs(t, err, postErrors.ErrTwitsnapNotFound)
+
+	err = db.DeletePost(ctx, "missing")
+	assert.ErrorIs(t, err, postErrors.ErrTwitsnapNotFound)
+
+	_, err = db.EditPost(ctx, "missing", models.EditPostExpectedFormat{}, "1")
+	assert.ErrorIs(t, err, postErrors.ErrTwitsnapNotFound)
+}
+
+func conformanceBlockHidesPost(t *testing.T, ctx context.Context, db database.Database) {
+	post := insertConformancePost(t, ctx, db, "1", "blocked", nil, true, conformanceBaseTime())
+
+	assert.Nil(t, db.BlockPost(ctx, post.Post_ID))
+
+	_, err := db.GetPost(ctx, post.Post_ID, "1")
+	assert.ErrorIs(t, err, postErrors.ErrTwitsnapNotFound)
+
+	feed, _, err := db.GetUserFeedFollowing(ctx, []string{"1"}, "1", conformanceNow())
+	assert.Nil(t, err)
+	assert.Empty(t, feed)
+
+	assert.Nil(t, db.UnBlockPost(ctx, post.Post_ID))
+
+	_, err = db.GetPost(ctx, post.Post_ID, "1")
+	assert.Nil(t, err)
+
+	assert.ErrorIs(t, db.BlockPost(ctx, "missing"), postErrors.ErrTwitsnapNotFound)
+	assert.ErrorIs(t, db.UnBlockPost(ctx, "missing"), postErrors.ErrTwitsnapNotFound)
+}
+
+func conformanceDeletePostRemovesRetweets(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	post := insertConformancePost(t, ctx, db, "1", "original", nil, true, base)
+	retweet := insertConformanceRetweet(t, ctx, db, post, "2", base.Add(time.Second))
+
+	assert.Nil(t, db.DeletePost(ctx, post.Post_ID))
+
+	_, err := db.GetPost(ctx, post.Post_ID, "1")
+	assert.ErrorIs(t, err, postErrors.ErrTwitsnapNotFound)
+
+	_, err = db.GetPost(ctx, retweet.Post_ID, "1")
+	assert.ErrorIs(t, err, postErrors.ErrTwitsnapNotFound)
+}
+
+func conformanceEditPost(t *testing.T, ctx context.Context, db database.Database) {
+	post := insertConformancePost(t, ctx, db, "1", "before #old", []string{"old"}, true, conformanceBaseTime())
+
+	content := "after #new #other"
+	public := false
+	edited, err := db.EditPost(ctx, post.Post_ID, models.EditPostExpectedFormat{Content: &content, Public: &public, Mentions: []string{"3"}}, "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, content, edited.Content)
+	assert.Equal(t, []string{"new", "other"}, edited.Tags)
+	assert.Equal(t, false, edited.Public)
+	assert.Equal(t, []string{"3"}, edited.Mentions)
+
+	edited, err = db.EditPost(ctx, post.Post_ID, models.EditPostExpectedFormat{}, "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, content, edited.Content)
+	assert.Equal(t, []string{}, edited.Mentions)
+}
+
+func conformanceRevisions(t *testing.T, ctx context.Context, db database.Database) {
+	post := insertConformancePost(t, ctx, db, "1", "first #one", []string{"one"}, true, conformanceBaseTime())
+	page := models.NewLimitConfig("", "0", "10")
+
+	second, third := "second #two", "third #two"
+	private := false
+
+	_, err := db.EditPost(ctx, post.Post_ID, models.EditPostExpectedFormat{Content: &second}, "1")
+	assert.Nil(t, err)
+	_, err = db.EditPost(ctx, post.Post_ID, models.EditPostExpectedFormat{Content: &third, Public: &private, Mentions: []string{"2"}}, "1")
+	assert.Nil(t, err)
+
+	unchanged, err := db.EditPost(ctx, post.Post_ID, models.EditPostExpectedFormat{Mentions: []string{"2"}}, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, unchanged.Edits, "An edit that changes nothing should not be a revision")
+	assert.True(t, unchanged.Edited)
+
+	revisions, hasMore, err := db.GetRevisions(ctx, post.Post_ID, page)
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []int{2, 1}, revisionNumbers(revisions))
+
+	assert.Equal(t, "second #two", *revisions[0].Before.Content)
+	assert.Equal(t, "third #two", *revisions[0].After.Content)
+	assert.Equal(t, true, *revisions[0].Before.Public)
+	assert.Equal(t, []string{}, *revisions[0].Before.Mentions)
+	assert.Equal(t, []string{"2"}, *revisions[0].After.Mentions)
+	assert.Nil(t, revisions[0].Before.Media_Info, "Fields the edit left alone should not be in the diff")
+	assert.Nil(t, revisions[1].Before.Public)
+	assert.Equal(t, "1", revisions[1].Editor_ID)
+
+	reverted, err := db.RevertPost(ctx, post.Post_ID, 0, "admin")
+	assert.Nil(t, err)
+	assert.Equal(t, "first #one", reverted.Content)
+	assert.Equal(t, []string{"one"}, reverted.Tags)
+	assert.True(t, reverted.Public)
+	assert.Equal(t, []string{}, reverted.Mentions)
+	assert.Equal(t, 3, reverted.Edits)
+
+	revisions, _, err = db.GetRevisions(ctx, post.Post_ID, page)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, *revisions[0].Reverted_To)
+	assert.Equal(t, "admin", revisions[0].Editor_ID)
+
+	reverted, err = db.RevertPost(ctx, post.Post_ID, 2, "admin")
+	assert.Nil(t, err)
+	assert.Equal(t, "third #two", reverted.Content)
+	assert.False(t, reverted.Public)
+
+	revisions, hasMore, err = db.GetRevisions(ctx, post.Post_ID, models.NewLimitConfig("", "1", "2"))
+	assert.Nil(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []int{3, 2}, revisionNumbers(revisions))
+
+	_, err = db.RevertPost(ctx, post.Post_ID, 5, "admin")
+	assert.ErrorIs(t, err, postErrors.ErrRevisionNotFound)
+
+	_, _, err = db.GetRevisions(ctx, "missing", page)
+	assert.ErrorIs(t, err, postErrors.ErrTwitsnapNotFound)
+
+	summary, err := db.GetTagSummary(ctx, "two")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, summary.Total_Tweets, "Reverts should keep the tag metrics in step")
+}
+
+func revisionNumbers(revisions []models.Revision) []int {
+	numbers := []int{}
+	for _, revision := range revisions {
+		numbers = append(numbers, revision.Number)
+	}
+	return numbers
+}
+
+func conformanceLikeAndUnlike(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	post := insertConformancePost(t, ctx, db, "1", "likeable", nil, true, base)
+	retweet := insertConformanceRetweet(t, ctx, db, post, "3", base.Add(time.Second))
+
+	assert.Nil(t, db.LikeAPost(ctx, post.Post_ID, "2"))
+
+	err := db.LikeAPost(ctx, post.Post_ID, "2")
+	assert.Equal(t, postErrors.AlreadyLiked(post.Post_ID), err)
+
+	liked, err := db.GetPost(ctx, post.Post_ID, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, liked.Likes)
+	assert.True(t, liked.User_Liked)
+
+	likedRetweet, err := db.GetPost(ctx, retweet.Post_ID, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, likedRetweet.Likes)
+	assert.True(t, likedRetweet.User_Liked)
+
+	notLiker, err := db.GetPost(ctx, post.Post_ID, "1")
+	assert.Nil(t, err)
+	assert.False(t, notLiker.User_Liked)
+
+	assert.Nil(t, db.UnLikeAPost(ctx, post.Post_ID, "2"))
+
+	unliked, err := db.GetPost(ctx, post.Post_ID, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, unliked.Likes)
+	assert.False(t, unliked.User_Liked)
+}
+
+func conformanceRetweetAndDeleteRetweet(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	post := insertConformancePost(t, ctx, db, "1", "retweetable", []string{"tag"}, true, base)
+	retweet := insertConformanceRetweet(t, ctx, db, post, "2", base.Add(time.Second))
+
+	assert.True(t, retweet.Is_Retweet)
+	assert.True(t, retweet.User_Retweet)
+	assert.Equal(t, 1, retweet.Retweets)
+	assert.Equal(t, "2", retweet.Retweet_Author)
+	assert.Equal(t, post.Post_ID, retweet.Original_Post_ID)
+
+	original, err := db.GetPost(ctx, post.Post_ID, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, original.Retweets)
+	assert.True(t, original.User_Retweet)
+
+	// Retweeting again changes nothing, so the counter stays the size of the
+	// retweeters set.
+	again := models.NewRetweetDBPost(original, "2")
+	again.Time = base.Add(2 * time.Second)
+	_, err = db.AddNewRetweet(ctx, again)
+	assert.ErrorIs(t, err, postErrors.ErrAlreadyRetweeted)
+
+	original, err = db.GetPost(ctx, post.Post_ID, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, original.Retweets)
+
+	_, err = db.GetPost(ctx, again.Post_ID, "2")
+	assert.ErrorIs(t, err, postErrors.ErrTwitsnapNotFound, "The second retweet should not be stored")
+
+	assert.Nil(t, db.DeleteRetweet(ctx, post.Post_ID, "2"))
+
+	original, err = db.GetPost(ctx, post.Post_ID, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, original.Retweets)
+	assert.False(t, original.User_Retweet)
+
+	_, err = db.GetPost(ctx, retweet.Post_ID, "2")
+	assert.ErrorIs(t, err, postErrors.ErrTwitsnapNotFound, "The retweet should be deleted with it")
+}
+
+func conformanceBookmarks(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	first := insertConformancePost(t, ctx, db, "1", "first", nil, true, base)
+	second := insertConformancePost(t, ctx, db, "1", "second", nil, true, base.Add(time.Second))
+	insertConformancePost(t, ctx, db, "1", "third", nil, true, base.Add(2*time.Second))
+
+	assert.Nil(t, db.AddFavorite(ctx, first.Post_ID, "2"))
+	assert.Nil(t, db.AddFavorite(ctx, second.Post_ID, "2"))
+	assert.Nil(t, db.AddFavorite(ctx, second.Post_ID, "2"))
+
+	bookmarked, err := db.GetPost(ctx, first.Post_ID, "2")
+	assert.Nil(t, err)
+	assert.True(t, bookmarked.Bookmark)
+
+	favorites, hasMore, err := db.GetUserFavorites(ctx, "2", conformanceNow())
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"second", "first"}, postContents(favorites))
+
+	assert.Nil(t, db.RemoveFavorite(ctx, first.Post_ID, "2"))
+
+	favorites, _, err = db.GetUserFavorites(ctx, "2", conformanceNow())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"second"}, postContents(favorites))
+
+	favorites, _, err = db.GetUserFavorites(ctx, "3", conformanceNow())
+	assert.Nil(t, err)
+	assert.Empty(t, favorites)
+}
+
+func conformanceFeedFollowing(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	first := insertConformancePost(t, ctx, db, "1", "one", nil, true, base)
+	insertConformancePost(t, ctx, db, "2", "two", nil, false, base.Add(time.Second))
+	insertConformancePost(t, ctx, db, "4", "not followed", nil, true, base.Add(2*time.Second))
+	insertConformanceRetweet(t, ctx, db, first, "4", base.Add(3*time.Second))
+	insertConformancePost(t, ctx, db, "3", "three", nil, true, base.Add(4*time.Second))
+
+	following := []string{"1", "2", "3"}
+
+	page, hasMore, err := db.GetUserFeedFollowing(ctx, following, "1", models.NewLimitConfig(time.Now().UTC().Format(time.RFC3339), "0", "2"))
+	assert.Nil(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"three", "one"}, postContents(page))
+	assert.True(t, page[1].Is_Retweet)
+
+	page, hasMore, err = db.GetUserFeedFollowing(ctx, following, "1", models.NewLimitConfig(time.Now().UTC().Format(time.RFC3339), "2", "2"))
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"two", "one"}, postContents(page))
+
+	page, _, err = db.GetUserFeedFollowing(ctx, following, "1", models.NewLimitConfig(base.Add(time.Second).Format(time.RFC3339), "0", "10"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"one"}, postContents(page))
+}
+
+func conformanceFeedInterests(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	insertConformancePost(t, ctx, db, "4", "public stranger", []string{"go"}, true, base)
+	insertConformancePost(t, ctx, db, "4", "private stranger", []string{"go"}, false, base.Add(time.Second))
+	insertConformancePost(t, ctx, db, "2", "private friend", []string{"rust", "go"}, false, base.Add(2*time.Second))
+	insertConformancePost(t, ctx, db, "2", "other tag", []string{"java"}, true, base.Add(3*time.Second))
+
+	_, _, err := db.GetUserFeedInterests(ctx, []string{}, []string{"2"}, "1", conformanceNow())
+	assert.Equal(t, postErrors.NoTagsFound(), err)
+
+	page, hasMore, err := db.GetUserFeedInterests(ctx, []string{"go"}, []string{"2"}, "1", conformanceNow())
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"private friend", "public stranger"}, postContents(page))
+}
+
+func conformanceFeedSingleAndRetweet(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	own := insertConformancePost(t, ctx, db, "1", "own", nil, true, base)
+	private := insertConformancePost(t, ctx, db, "1", "own private", nil, false, base.Add(time.Second))
+	other := insertConformancePost(t, ctx, db, "2", "other", nil, true, base.Add(2*time.Second))
+	insertConformanceRetweet(t, ctx, db, other, "1", base.Add(3*time.Second))
+	insertConformanceRetweet(t, ctx, db, own, "2", base.Add(4*time.Second))
+
+	single, _, err := db.GetUserFeedSingle(ctx, "1", conformanceNow(), "3", []string{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"other", "own"}, postContents(single))
+
+	single, _, err = db.GetUserFeedSingle(ctx, "1", conformanceNow(), "1", []string{"1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"other", "own private", "own"}, postContents(single))
+
+	assert.Nil(t, db.BlockPost(ctx, private.Post_ID))
+
+	single, _, err = db.GetUserFeedSingle(ctx, "1", conformanceNow(), "1", []string{"1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"other", "own"}, postContents(single))
+
+	retweets, _, err := db.GetUserFeedRetweet(ctx, "1", conformanceNow(), "3", []string{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"own", "other"}, postContents(retweets))
+	for _, post := range retweets {
+		assert.True(t, post.Is_Retweet)
+	}
+}
+
+func conformanceFeedMentions(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+
+	for i, author := range []string{"2", "3", "2", "3"} {
+		post := models.NewDBPost(author, fmt.Sprintf("mention %d", i), nil, i < 2, models.MediaInfo{}, []string{"1"})
+		post.Time = base.Add(time.Duration(i) * time.Second)
+		_, err := db.AddNewPost(ctx, post)
+		assert.Nil(t, err)
+
+		if i == 0 {
+			insertConformanceRetweet(t, ctx, db, post, "3", base.Add(time.Minute))
+		}
+		if i == 1 {
+			assert.Nil(t, db.BlockPost(ctx, post.Post_ID))
+		}
+	}
+	insertConformancePost(t, ctx, db, "2", "no mention", nil, true, base)
+
+	posts, hasMore, err := db.GetUserFeedMentions(ctx, []string{"3"}, "1", conformanceNow())
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"mention 3", "mention 0"}, postContents(posts))
+
+	posts, hasMore, err = db.GetUserFeedMentions(ctx, []string{"2", "3"}, "1", models.NewLimitConfig(time.Now().UTC().Format(time.RFC3339), "0", "1"))
+	assert.Nil(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"mention 3"}, postContents(posts))
+}
+
+func insertConformanceReply(t *testing.T, ctx context.Context, db database.Database, parent models.DBPost, authorID string, content string, public bool, postTime time.Time) models.DBPost {
+	reply := models.NewDBPost(authorID, content, nil, public, models.MediaInfo{}, nil)
+	reply.Time = postTime
+	reply.In_Reply_To_Post_ID = parent.Post_ID
+	reply.Conversation_ID = parent.Conversation_ID
+
+	_, err := db.AddNewPost(ctx, reply)
+	assert.Nil(t, err)
+
+	return reply
+}
+
+func conformanceConversations(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	root := insertConformancePost(t, ctx, db, "1", "root", nil, true, base)
+	first := insertConformanceReply(t, ctx, db, root, "2", "first reply", true, base.Add(time.Second))
+	insertConformanceReply(t, ctx, db, root, "4", "private reply", false, base.Add(2*time.Second))
+	nested := insertConformanceReply(t, ctx, db, first, "3", "nested reply", true, base.Add(3*time.Second))
+	insertConformanceRetweet(t, ctx, db, first, "3", base.Add(time.Minute))
+	insertConformancePost(t, ctx, db, "1", "another conversation", nil, true, base)
+
+	posts, err := db.GetConversation(ctx, root.Conversation_ID, []string{"1"}, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"root", "first reply", "", "nested reply"}, postContents(posts), "Retweets should not repeat a reply")
+	assert.Equal(t, []bool{false, false, true, false}, []bool{posts[0].Tombstone, posts[1].Tombstone, posts[2].Tombstone, posts[3].Tombstone})
+	assert.Equal(t, root.Post_ID, posts[2].In_Reply_To_Post_ID, "A tombstone should keep its place")
+	assert.Equal(t, 2, posts[0].Replies)
+	assert.Equal(t, 1, posts[1].Replies)
+
+	single, _, err := db.GetUserFeedRetweet(ctx, "3", conformanceNow(), "3", []string{"3"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, single[0].Replies, "Retweets should count the replies of their original")
+
+	assert.Nil(t, db.DeletePost(ctx, first.Post_ID))
+	assert.Nil(t, db.DeletePost(ctx, nested.Post_ID))
+	assert.Nil(t, db.BlockPost(ctx, root.Post_ID))
+
+	posts, err = db.GetConversation(ctx, root.Conversation_ID, []string{"1", "4"}, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"", "", "private reply"}, postContents(posts))
+	assert.Equal(t, []string{root.Post_ID, first.Post_ID}, postIDs(posts[:2]), "Deleting or blocking a parent should leave a tombstone")
+	assert.True(t, posts[0].Tombstone && posts[1].Tombstone)
+	assert.Equal(t, 2, posts[0].Replies, "The deleted reply left a tombstone, so it still counts")
+}
+
+func conformanceConversationThread(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	root := insertConformancePost(t, ctx, db, "1", "root", nil, true, base)
+	first := insertConformanceReply(t, ctx, db, root, "2", "first reply", true, base.Add(time.Second))
+	private := insertConformanceReply(t, ctx, db, root, "4", "private reply", false, base.Add(2*time.Second))
+	nested := insertConformanceReply(t, ctx, db, first, "3", "nested reply", true, base.Add(3*time.Second))
+	underPrivate := insertConformanceReply(t, ctx, db, private, "1", "under the private one", true, base.Add(4*time.Second))
+	insertConformanceRetweet(t, ctx, db, first, "3", base.Add(time.Minute))
+
+	assert.Nil(t, db.DeletePost(ctx, first.Post_ID))
+
+	expected := []string{root.Post_ID, first.Post_ID, nested.Post_ID, private.Post_ID, underPrivate.Post_ID}
+
+	thread, next, err := db.GetConversationThread(ctx, root.Conversation_ID, []string{"1"}, "1", models.NewLimitConfig("", "0", "10"), nil)
+	assert.Nil(t, err)
+	assert.Nil(t, next)
+	assert.Equal(t, expected, postIDs(thread), "Every post should be followed by its replies")
+	assert.Equal(t, []string{"root", "", "nested reply", "", "under the private one"}, postContents(thread), "Deleted and hidden posts should be tombstones")
+
+	read := []string{}
+	var after *models.ThreadCursor
+
+	for page := 0; page < 5; page++ {
+		thread, next, err := db.GetConversationThread(ctx, root.Conversation_ID, []string{"1"}, "1", models.LimitConfig{Limit: 2}, after)
+		assert.Nil(t, err)
+
+		read = append(read, postIDs(thread)...)
+
+		if next == nil {
+			break
+		}
+		after = next
+	}
+
+	assert.Equal(t, expected, read, "Following the cursor should read the thread once")
+
+	thread, next, err = db.GetConversationThread(ctx, root.Conversation_ID, []string{"1"}, "1", models.NewLimitConfig("", "1", "2"), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, expected[1:3], postIDs(thread))
+	assert.NotNil(t, next)
+}
+
+func conformanceQuotes(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	original := insertConformancePost(t, ctx, db, "1", "original", nil, true, base)
+	insertConformanceRetweet(t, ctx, db, original, "2", base.Add(time.Second))
+
+	quote := models.NewDBPost("3", "my take", nil, true, models.MediaInfo{}, nil)
+	quote.Time = base.Add(2 * time.Second)
+	quote.Quoted_Post_ID = original.Post_ID
+	_, err := db.AddNewPost(ctx, quote)
+	assert.Nil(t, err)
+
+	fetched, err := db.GetPost(ctx, original.Post_ID, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, fetched.Quotes)
+	assert.Equal(t, 1, fetched.Retweets, "Quotes should count apart from retweets")
+
+	fetched, err = db.GetPost(ctx, quote.Post_ID, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, original.Post_ID, fetched.Quoted_Post_ID)
+	assert.Equal(t, "original", fetched.Quoted_Post.Content)
+	assert.Equal(t, 1, fetched.Quoted_Post.Quotes)
+
+	single, _, err := db.GetUserFeedSingle(ctx, "3", conformanceNow(), "1", []string{"3"})
+	assert.Nil(t, err)
+	assert.Equal(t, "original", single[0].Quoted_Post.Content, "Listings should embed the quoted post")
+
+	retweets, _, err := db.GetUserFeedRetweet(ctx, "2", conformanceNow(), "2", []string{"2"})
+	assert.Nil(t, err)
+	assert.Nil(t, retweets[0].Quoted_Post, "Posts that quote nothing embed nothing")
+	assert.Equal(t, 1, retweets[0].Quotes)
+
+	assert.Nil(t, db.BlockPost(ctx, original.Post_ID))
+
+	fetched, err = db.GetPost(ctx, quote.Post_ID, "1")
+	assert.Nil(t, err)
+	assert.True(t, fetched.Quoted_Post.Tombstone, "A blocked quoted post should be a tombstone")
+	assert.Equal(t, "", fetched.Quoted_Post.Content)
+
+	assert.Nil(t, db.UnBlockPost(ctx, original.Post_ID))
+	assert.Nil(t, db.DeletePost(ctx, quote.Post_ID))
+
+	fetched, err = db.GetPost(ctx, original.Post_ID, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, fetched.Quotes)
+
+	quote = models.NewDBPost("3", "again", nil, true, models.MediaInfo{}, nil)
+	quote.Quoted_Post_ID = original.Post_ID
+	_, err = db.AddNewPost(ctx, quote)
+	assert.Nil(t, err)
+	assert.Nil(t, db.DeletePost(ctx, original.Post_ID))
+
+	fetched, err = db.GetPost(ctx, quote.Post_ID, "1")
+	assert.Nil(t, err)
+	assert.True(t, fetched.Quoted_Post.Tombstone, "A deleted quoted post should be a tombstone")
+	assert.Equal(t, original.Post_ID, fetched.Quoted_Post.Post_ID)
+}
+
+func conformanceSearches(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	insertConformancePost(t, ctx, db, "1", "Hello world #a #b", []string{"a", "b"}, true, base)
+	insertConformancePost(t, ctx, db, "4", "hello private #a", []string{"a"}, false, base.Add(time.Second))
+	insertConformancePost(t, ctx, db, "2", "goodbye #b", []string{"b"}, false, base.Add(2*time.Second))
+
+	words, _, err := db.SearchPosts(ctx, models.SearchQuery{Terms: []string{"hello", "goodbye"}, Sort: models.LATEST_SORT}, []string{"2"}, "1", conformanceNow())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"goodbye #b", "Hello world #a #b"}, postContents(words))
+
+	tags, _, err := db.SearchPosts(ctx, models.SearchQuery{Tags: []string{"a", "b"}}, []string{"2"}, "1", conformanceNow())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Hello world #a #b"}, postContents(tags))
+
+	tags, _, err = db.SearchPosts(ctx, models.SearchQuery{Tags: []string{"a"}}, []string{"4"}, "1", conformanceNow())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"hello private #a", "Hello world #a #b"}, postContents(tags))
+
+	tags, hasMore, err := db.SearchPosts(ctx, models.SearchQuery{Tags: []string{"c"}}, []string{}, "1", conformanceNow())
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Empty(t, tags)
+}
+
+func conformanceSearchRelevanceAndPhrases(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	insertConformancePost(t, ctx, db, "1", "go go go", nil, true, base)
+	insertConformancePost(t, ctx, db, "1", "learning go and rust together", nil, true, base.Add(time.Second))
+	insertConformancePost(t, ctx, db, "1", "Rust and Go", nil, true, base.Add(2*time.Second))
+	insertConformancePost(t, ctx, db, "1", "a.b and friends", nil, true, base.Add(3*time.Second))
+
+	latest := models.SearchQuery{Terms: []string{"go"}, Sort: models.LATEST_SORT}
+	posts, _, err := db.SearchPosts(ctx, latest, []string{}, "1", conformanceNow())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Rust and Go", "learning go and rust together", "go go go"}, postContents(posts))
+
+	relevance := models.SearchQuery{Terms: []string{"go", "rust"}, Sort: models.RELEVANCE_SORT}
+	posts, hasMore, err := db.SearchPosts(ctx, relevance, []string{}, "1", models.NewLimitConfig(time.Now().UTC().Format(time.RFC3339), "0", "2"))
+	assert.Nil(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"go go go", "Rust and Go"}, postContents(posts))
+
+	posts, hasMore, err = db.SearchPosts(ctx, relevance, []string{}, "1", models.NewLimitConfig(time.Now().UTC().Format(time.RFC3339), "2", "2"))
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"learning go and rust together"}, postContents(posts))
+
+	phrase := models.SearchQuery{Terms: []string{}, Phrases: []string{"RUST and go"}, Sort: models.LATEST_SORT}
+	posts, _, err = db.SearchPosts(ctx, phrase, []string{}, "1", conformanceNow())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Rust and Go"}, postContents(posts))
+
+	// Words are never patterns, so symbols cannot widen the search.
+	symbols := models.SearchQuery{Terms: models.SearchTokens(".* a.b"), Sort: models.LATEST_SORT}
+	posts, _, err = db.SearchPosts(ctx, symbols, []string{}, "1", conformanceNow())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a.b and friends"}, postContents(posts))
+}
+
+func conformanceSearchOperators(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+
+	media := models.NewDBPost("1", "coffee with a photo #morning", []string{"morning"}, true, models.MediaInfo{Media_URL: "url", Media_Type: "IMAGE"}, []string{"2"})
+	media.Time = base
+	_, err := db.AddNewPost(ctx, media)
+	assert.Nil(t, err)
+
+	plain := insertConformancePost(t, ctx, db, "2", "coffee without sugar #morning", []string{"morning"}, true, base.Add(time.Second))
+	insertConformancePost(t, ctx, db, "3", "tea time", nil, true, base.Add(2*time.Second))
+	insertConformanceRetweet(t, ctx, db, plain, "3", base.Add(3*time.Second))
+
+	search := func(query models.SearchQuery) []string {
+		query.Sort = models.LATEST_SORT
+		posts, _, err := db.SearchPosts(ctx, query, []string{}, "1", conformanceNow())
+		assert.Nil(t, err)
+		return postContents(posts)
+	}
+
+	assert.Equal(t, []string{"coffee with a photo #morning"}, search(models.SearchQuery{Terms: []string{"coffee"}, Excluded: []string{"sugar"}}))
+	assert.Equal(t, []string{"coffee without sugar #morning", "tea time"}, search(models.SearchQuery{Authors: []string{"3"}}), "A retweet is from whoever retweeted it")
+	assert.Equal(t, []string{"coffee with a photo #morning"}, search(models.SearchQuery{Tags: []string{"morning"}, Mentions: []string{"2"}}))
+	assert.Equal(t, []string{"coffee with a photo #morning"}, search(models.SearchQuery{HasMedia: true}))
+	assert.Equal(t, []string{"coffee without sugar #morning"}, search(models.SearchQuery{IsRetweet: true}))
+
+	since := base.Add(time.Second)
+	until := base.Add(3 * time.Second)
+	assert.Equal(t, []string{"tea time", "coffee without sugar #morning"}, search(models.SearchQuery{Since: &since, Until: &until}))
+
+	relevance := models.SearchQuery{Terms: []string{"coffee"}, Authors: []string{"2"}, Sort: models.RELEVANCE_SORT}
+	posts, _, err := db.SearchPosts(ctx, relevance, []string{}, "1", conformanceNow())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"coffee without sugar #morning"}, postContents(posts))
+}
+
+func conformanceTagSuggestions(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	accented := insertConformancePost(t, ctx, db, "1", "#Café #Café", []string{"Café", "Café"}, true, base)
+	insertConformancePost(t, ctx, db, "2", "#cafe", []string{"cafe"}, true, base.Add(time.Second))
+	latest := insertConformancePost(t, ctx, db, "3", "#cafe #cars", []string{"cafe", "cars"}, true, base.Add(2*time.Second))
+	insertConformancePost(t, ctx, db, "4", "#cafeteria", []string{"cafeteria"}, false, base)
+	blocked := insertConformancePost(t, ctx, db, "2", "#cafés", []string{"cafés"}, true, base)
+	assert.Nil(t, db.BlockPost(ctx, blocked.Post_ID))
+	insertConformanceRetweet(t, ctx, db, accented, "3", base.Add(3*time.Second))
+
+	now := base.Add(time.Hour)
+
+	suggestions, err := db.GetTagSuggestions(ctx, "cafe", []string{"1"}, now, 10)
+	assert.Nil(t, err)
+	assert.Len(t, suggestions, 1)
+	assert.Equal(t, "cafe", suggestions[0].Tag, "The spelling most posts use should be shown")
+	assert.Equal(t, 3, suggestions[0].Posts, "Every spelling should count, and retweets should not")
+	assert.True(t, latest.Time.Equal(suggestions[0].Last_Used))
+
+	suggestions, err = db.GetTagSuggestions(ctx, "cafe", []string{"4"}, now, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cafe", "cafeteria"}, tagNames(suggestions), "Followed authors should add their private tags")
+	assert.Greater(t, suggestions[0].Score, suggestions[1].Score)
+
+	suggestions, err = db.GetTagSuggestions(ctx, "ca", []string{}, now, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cafe"}, tagNames(suggestions))
+
+	content := "#Cafétéria"
+	_, err = db.EditPost(ctx, latest.Post_ID, models.EditPostExpectedFormat{Content: &content}, "3")
+	assert.Nil(t, err)
+
+	suggestions, err = db.GetTagSuggestions(ctx, "cafet", []string{}, now, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Cafétéria"}, tagNames(suggestions), "Edits should change the tags suggested")
+}
+
+func tagNames(suggestions []models.TagSuggestion) []string {
+	tags := []string{}
+	for _, suggestion := range suggestions {
+		tags = append(tags, suggestion.Tag)
+	}
+	return tags
+}
+
+func conformanceAllPosts(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	insertConformancePost(t, ctx, db, "1", "first", nil, false, base)
+	blocked := insertConformancePost(t, ctx, db, "2", "second", nil, true, base.Add(time.Second))
+	assert.Nil(t, db.LikeAPost(ctx, blocked.Post_ID, database.ADMIN))
+	assert.Nil(t, db.BlockPost(ctx, blocked.Post_ID))
+
+	posts, hasMore, err := db.GetAllPosts(ctx, models.NewLimitConfig(time.Now().UTC().Format(time.RFC3339), "0", "1"), database.ADMIN)
+	assert.Nil(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"second"}, postContents(posts))
+	assert.False(t, posts[0].User_Liked)
+
+	posts, hasMore, err = db.GetAllPosts(ctx, models.NewLimitConfig(time.Now().UTC().Format(time.RFC3339), "1", "1"), database.ADMIN)
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"first"}, postContents(posts))
+}
+
+func conformanceMetrics(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	first := insertConformancePost(t, ctx, db, "1", "first", nil, true, base)
+	insertConformancePost(t, ctx, db, "1", "second", nil, true, base.Add(time.Second))
+	insertConformancePost(t, ctx, db, "1", "too late", nil, true, base.Add(time.Hour/2))
+	insertConformancePost(t, ctx, db, "2", "other author", nil, true, base)
+	insertConformanceRetweet(t, ctx, db, first, "2", base.Add(2*time.Second))
+
+	assert.Nil(t, db.LikeAPost(ctx, first.Post_ID, "2"))
+	assert.Nil(t, db.LikeAPost(ctx, first.Post_ID, "3"))
+
+	limits := models.MetricLimits{FromTime: base.Add(-time.Second).Format(time.RFC3339), ToTime: base.Add(time.Minute).Format(time.RFC3339)}
+
+	metrics, err := db.GetUserMetrics(ctx, "1", limits)
+	assert.Nil(t, err)
+	assert.Equal(t, models.UserMetrics{Likes: 2, Retweets: 1, Posts: 2}, metrics)
+
+	metrics, err = db.GetUserMetrics(ctx, "3", limits)
+	assert.Nil(t, err)
+	assert.Equal(t, models.UserMetrics{Likes: 0, Retweets: 0, Posts: 0}, metrics)
+
+	_, err = db.GetUserMetrics(ctx, "1", models.MetricLimits{FromTime: "bad", ToTime: limits.ToTime})
+	assert.NotNil(t, err)
+}
+
+func metricsBuckets(buckets []models.UserMetricsBucket) []string {
+	summaries := []string{}
+	for _, bucket := range buckets {
+		summaries = append(summaries, fmt.Sprintf("%s %d/%d/%d", bucket.Time.Format(time.RFC3339), bucket.Likes, bucket.Retweets, bucket.Posts))
+	}
+	return summaries
+}
+
+func conformanceMetricsSeries(t *testing.T, ctx context.Context, db database.Database) {
+	sunday := insertConformancePost(t, ctx, db, "1", "sunday night", nil, true, time.Date(2024, 3, 4, 2, 30, 0, 0, time.UTC))
+	monday := insertConformancePost(t, ctx, db, "1", "monday", nil, true, time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC))
+	insertConformancePost(t, ctx, db, "1", "april", nil, true, time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC))
+	insertConformancePost(t, ctx, db, "2", "other author", nil, true, time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC))
+	insertConformanceRetweet(t, ctx, db, monday, "2", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, db.LikeAPost(ctx, sunday.Post_ID, "2"))
+
+	limits := models.MetricLimits{FromTime: "2024-02-01T00:00:00Z", ToTime: "2024-05-01T00:00:00Z", Interval: models.DAY_INTERVAL, Timezone: "UTC"}
+
+	buckets, err := db.GetUserMetricsSeries(ctx, "1", limits)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2024-03-04T00:00:00Z 1/1/2", "2024-04-01T00:00:00Z 0/0/1"}, metricsBuckets(buckets))
+
+	limits.Timezone = "America/Argentina/Buenos_Aires"
+
+	buckets, err = db.GetUserMetricsSeries(ctx, "1", limits)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2024-03-03T00:00:00-03:00 1/0/1", "2024-03-04T00:00:00-03:00 0/1/1", "2024-04-01T00:00:00-03:00 0/0/1"}, metricsBuckets(buckets))
+
+	limits.Interval = models.WEEK_INTERVAL
+
+	buckets, err = db.GetUserMetricsSeries(ctx, "1", limits)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2024-02-26T00:00:00-03:00 1/0/1", "2024-03-04T00:00:00-03:00 0/1/1", "2024-04-01T00:00:00-03:00 0/0/1"}, metricsBuckets(buckets))
+
+	limits.Interval = models.MONTH_INTERVAL
+
+	buckets, err = db.GetUserMetricsSeries(ctx, "1", limits)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2024-03-01T00:00:00-03:00 1/1/2", "2024-04-01T00:00:00-03:00 0/0/1"}, metricsBuckets(buckets))
+
+	limits.Interval = models.HOUR_INTERVAL
+	limits.Timezone = "Asia/Kolkata"
+	limits.ToTime = "2024-03-05T00:00:00Z"
+
+	buckets, err = db.GetUserMetricsSeries(ctx, "1", limits)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2024-03-04T08:00:00+05:30 1/0/1", "2024-03-04T17:00:00+05:30 0/1/1"}, metricsBuckets(buckets))
+
+	_, err = db.GetUserMetricsSeries(ctx, "1", models.MetricLimits{FromTime: limits.FromTime, ToTime: limits.ToTime, Interval: models.DAY_INTERVAL, Timezone: "Nowhere/Else"})
+	assert.NotNil(t, err)
+}
+
+func conformancePlatformMetricsSeries(t *testing.T, ctx context.Context, db database.Database) {
+	firstDay := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	secondDay := firstDay.AddDate(0, 0, 1)
+
+	withMedia := models.NewDBPost("1", "picture", nil, true, models.MediaInfo{Media_URL: "https://example.com/a.png", Media_Type: "image"}, []string{"2"})
+	withMedia.Time = firstDay
+	_, err := db.AddNewPost(ctx, withMedia)
+	assert.Nil(t, err)
+
+	private := insertConformancePost(t, ctx, db, "2", "private", nil, false, firstDay.Add(time.Hour))
+	blocked := insertConformancePost(t, ctx, db, "2", "blocked", nil, true, secondDay)
+	insertConformanceRetweet(t, ctx, db, withMedia, "3", firstDay.Add(2*time.Hour))
+
+	assert.Nil(t, db.LikeAPost(ctx, withMedia.Post_ID, "2"))
+	assert.Nil(t, db.LikeAPost(ctx, private.Post_ID, "1"))
+	assert.Nil(t, db.AddFavorite(ctx, withMedia.Post_ID, "2"))
+	assert.Nil(t, db.AddFavorite(ctx, withMedia.Post_ID, "3"))
+	assert.Nil(t, db.AddFavorite(ctx, blocked.Post_ID, "1"))
+	assert.Nil(t, db.BlockPost(ctx, blocked.Post_ID))
+
+	limits := models.MetricLimits{FromTime: "2024-03-01T00:00:00Z", ToTime: "2024-04-01T00:00:00Z", Interval: models.DAY_INTERVAL, Timezone: "UTC"}
+
+	buckets, err := db.GetPlatformMetricsSeries(ctx, limits)
+	assert.Nil(t, err)
+	assert.Equal(t, []models.PlatformMetricsBucket{
+		{Time: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Posts: 2, Retweets: 1, Likes: 2, Bookmarks: 2, Active_Authors: 3, Media_Posts: 1, Mention_Posts: 1},
+		{Time: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), Posts: 1, Bookmarks: 1, Blocked: 1, Active_Authors: 1},
+	}, buckets)
+
+	limits.Visibility = models.PRIVATE_VISIBILITY
+
+	buckets, err = db.GetPlatformMetricsSeries(ctx, limits)
+	assert.Nil(t, err)
+	assert.Equal(t, []models.PlatformMetricsBucket{
+		{Time: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Posts: 1, Likes: 1, Active_Authors: 1},
+	}, buckets)
+
+	limits.Visibility = models.PUBLIC_VISIBILITY
+	limits.Interval = models.MONTH_INTERVAL
+
+	buckets, err = db.GetPlatformMetricsSeries(ctx, limits)
+	assert.Nil(t, err)
+	assert.Equal(t, []models.PlatformMetricsBucket{
+		{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Posts: 2, Retweets: 1, Likes: 1, Bookmarks: 3, Blocked: 1, Active_Authors: 3, Media_Posts: 1, Mention_Posts: 1},
+	}, buckets)
+}
+
+func trendingTags(topics []models.TrendingTopic) []string {
+	tags := []string{}
+	for _, topic := range topics {
+		tags = append(tags, topic.Tag)
+	}
+	return tags
+}
+
+func conformanceTrendingTopics(t *testing.T, ctx context.Context, db database.Database) {
+	now := time.Now().UTC()
+	insertConformancePost(t, ctx, db, "1", "#old", []string{"old"}, true, now.Add(-48*time.Hour))
+	insertConformancePost(t, ctx, db, "1", "#popular #fresh", []string{"popular", "fresh"}, true, now.Add(-time.Minute))
+	insertConformancePost(t, ctx, db, "2", "#popular", []string{"popular"}, true, now.Add(-2*time.Minute))
+	insertConformancePost(t, ctx, db, "2", "#private", []string{"private"}, false, now.Add(-time.Minute))
+	blocked := insertConformancePost(t, ctx, db, "2", "#blocked", []string{"blocked"}, true, now.Add(-time.Minute))
+	assert.Nil(t, db.BlockPost(ctx, blocked.Post_ID))
+
+	topics, err := db.ComputeTrendingTopics(ctx, now.Add(-7*24*time.Hour), now, database.TRENDING_DECAY, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"popular", "fresh", "old"}, trendingTags(topics))
+	assert.Equal(t, 2, topics[0].Posts)
+	assert.InDelta(t, 2*math.Exp(-database.TRENDING_DECAY*1.5/60), topics[0].Score, 1e-3)
+
+	topics, err = db.ComputeTrendingTopics(ctx, now.Add(-24*time.Hour), now, database.TRENDING_DECAY, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"popular"}, trendingTags(topics))
+
+	snapshot, err := db.GetTrendingTopics(ctx, models.TRENDING_DAY_WINDOW)
+	assert.Nil(t, err)
+	assert.True(t, snapshot.Computed_At.IsZero())
+	assert.Equal(t, 0, len(snapshot.Topics))
+
+	saved := models.TrendingSnapshot{Window: models.TRENDING_DAY_WINDOW, Computed_At: now.Truncate(time.Millisecond), Topics: topics}
+	assert.Nil(t, db.SaveTrendingTopics(ctx, saved))
+	assert.Nil(t, db.SaveTrendingTopics(ctx, models.TrendingSnapshot{Window: models.TRENDING_HOUR_WINDOW, Computed_At: now, Topics: []models.TrendingTopic{}}))
+
+	snapshot, err = db.GetTrendingTopics(ctx, models.TRENDING_DAY_WINDOW)
+	assert.Nil(t, err)
+	assert.True(t, now.Truncate(time.Millisecond).Equal(snapshot.Computed_At))
+	assert.Equal(t, topics, snapshot.Topics)
+}
+
+func conformanceTrendingPosts(t *testing.T, ctx context.Context, db database.Database) {
+	now := time.Now().UTC()
+	insertConformancePost(t, ctx, db, "1", "quiet", nil, true, now.Add(-time.Minute))
+	liked := insertConformancePost(t, ctx, db, "1", "liked", nil, true, now.Add(-2*time.Hour))
+	retweeted := insertConformancePost(t, ctx, db, "2", "retweeted", nil, true, now.Add(-3*time.Hour))
+	private := insertConformancePost(t, ctx, db, "3", "private", nil, false, now.Add(-time.Hour))
+	blocked := insertConformancePost(t, ctx, db, "2", "blocked", nil, true, now.Add(-time.Hour))
+	old := insertConformancePost(t, ctx, db, "2", "old", nil, true, now.Add(-48*time.Hour))
+
+	for _, liker := range []string{"2", "3", "4"} {
+		assert.Nil(t, db.LikeAPost(ctx, liked.Post_ID, liker))
+		assert.Nil(t, db.LikeAPost(ctx, private.Post_ID, liker))
+		assert.Nil(t, db.LikeAPost(ctx, blocked.Post_ID, liker))
+		assert.Nil(t, db.LikeAPost(ctx, old.Post_ID, liker))
+	}
+	insertConformanceRetweet(t, ctx, db, retweeted, "3", now.Add(-time.Minute))
+	insertConformanceRetweet(t, ctx, db, retweeted, "4", now.Add(-time.Minute))
+	assert.Nil(t, db.BlockPost(ctx, blocked.Post_ID))
+
+	page := models.LimitConfig{Limit: 10}
+
+	posts, hasMore, err := db.GetTrendingPosts(ctx, []string{"1"}, "1", now.Add(-24*time.Hour), now, page)
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"liked", "retweeted", "quiet"}, postContents(posts), "Retweets should count in their original only")
+
+	posts, _, err = db.GetTrendingPosts(ctx, []string{"1", "3"}, "1", now.Add(-24*time.Hour), now, page)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"private", "liked", "retweeted", "quiet"}, postContents(posts), "Followers should see private posts")
+
+	posts, hasMore, err = db.GetTrendingPosts(ctx, []string{"1"}, "1", now.Add(-24*time.Hour), now, models.LimitConfig{Skip: 1, Limit: 1})
+	assert.Nil(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"retweeted"}, postContents(posts))
+}
+
+func conformanceTagMetrics(t *testing.T, ctx context.Context, db database.Database) {
+	now := time.Now().UTC()
+	day := now.Truncate(24 * time.Hour).Add(-48 * time.Hour)
+	firstDay := day.Format(time.DateOnly)
+	secondDay := day.Add(24 * time.Hour).Format(time.DateOnly)
+
+	first := insertConformancePost(t, ctx, db, "1", "#go #rust", []string{"go", "rust"}, true, day.Add(10*time.Hour+30*time.Minute))
+	second := insertConformancePost(t, ctx, db, "1", "#go", []string{"go"}, true, day.Add(11*time.Hour))
+	third := insertConformancePost(t, ctx, db, "2", "#go", []string{"go"}, true, day.Add(29*time.Hour))
+	insertConformanceRetweet(t, ctx, db, first, "2", now)
+
+	content := "now #rust"
+	_, err := db.EditPost(ctx, second.Post_ID, models.EditPostExpectedFormat{Content: &content}, "1")
+	assert.Nil(t, err)
+
+	assert.Nil(t, db.DeletePost(ctx, third.Post_ID))
+
+	usage, err := db.GetTagUsage(ctx, "go", day, day.Add(48*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, []models.TagUsage{
+		{Tag: "go", Day: firstDay, Hourly_Frecuency: map[string]int{"10": 1, "11": 0}, Total_Tweets: 1},
+		{Tag: "go", Day: secondDay, Hourly_Frecuency: map[string]int{"05": 0}, Total_Tweets: 0},
+	}, usage)
+
+	usage, err = db.GetTagUsage(ctx, "rust", day.Add(time.Hour), day.Add(2*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, []models.TagUsage{
+		{Tag: "rust", Day: firstDay, Hourly_Frecuency: map[string]int{"10": 1, "11": 1}, Total_Tweets: 2},
+	}, usage)
+
+	weight := func(postTime time.Time) float64 {
+		return math.Exp(-database.TRENDING_DECAY * time.Since(postTime).Hours())
+	}
+
+	summary, err := db.GetTagSummary(ctx, "go")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, summary.Total_Tweets)
+	assert.InDelta(t, weight(first.Time), summary.Trend, 1e-4)
+
+	summary, err = db.GetTagSummary(ctx, "rust")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, summary.Total_Tweets)
+	assert.InDelta(t, weight(first.Time)+weight(second.Time), summary.Trend, 1e-4)
+
+	summary, err = db.GetTagSummary(ctx, "missing")
+	assert.Nil(t, err)
+	assert.Equal(t, models.TagSummary{Tag: "missing"}, summary)
+
+	usage, err = db.GetTagUsage(ctx, "missing", day, now)
+	assert.Nil(t, err)
+	assert.Equal(t, []models.TagUsage{}, usage)
+}
+
+func conformanceCancelledContext(t *testing.T, ctx context.Context, db database.Database) {
+	post := insertConformancePost(t, ctx, db, "1", "cancelled", nil, true, conformanceBaseTime())
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, _, err := db.GetAllPosts(cancelled, conformanceNow(), database.ADMIN)
+	assert.NotNil(t, err)
+
+	_, err = db.GetPost(cancelled, post.Post_ID, "1")
+	assert.NotNil(t, err)
+
+	err = db.LikeAPost(cancelled, post.Post_ID, "2")
+	assert.NotNil(t, err)
+
+	fetched, err := db.GetPost(ctx, post.Post_ID, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, fetched.Likes)
+	assert.False(t, fetched.User_Liked)
+}
+
+func conformanceCountersFollowMembership(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+	post := insertConformancePost(t, ctx, db, "1", "counted", nil, true, base)
+	insertConformanceRetweet(t, ctx, db, post, "2", base.Add(time.Second))
+	assert.Nil(t, db.LikeAPost(ctx, post.Post_ID, "2"))
+
+	assert.Nil(t, db.UnLikeAPost(ctx, post.Post_ID, "3"))
+	assert.Nil(t, db.DeleteRetweet(ctx, post.Post_ID, "3"))
+
+	fetched, err := db.GetPost(ctx, post.Post_ID, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, fetched.Likes)
+	assert.Equal(t, 1, fetched.Retweets)
+	assert.True(t, fetched.User_Liked)
+	assert.True(t, fetched.User_Retweet)
+}
+
+func conformanceFailedWritesLeaveNothingPartial(t *testing.T, ctx context.Context, db database.Database) {
+	hooker, ok := db.(database.WriteStepHooker)
+	if !ok {
+		t.Skip("database does not support write step hooks")
+	}
+	defer hooker.SetWriteStepHook(nil)
+
+	base := conformanceBaseTime()
+	post := insertConformancePost(t, ctx, db, "1", "atomic", nil, true, base)
+	insertConformanceRetweet(t, ctx, db, post, "2", base.Add(time.Second))
+	assert.Nil(t, db.LikeAPost(ctx, post.Post_ID, "3"))
+
+	front, err := db.GetPost(ctx, post.Post_ID, "4")
+	assert.Nil(t, err)
+
+	writes := []struct {
+		operation string
+		steps     []string
+		write     func() error
+	}{
+		{"LikeAPost", []string{database.STEP_LIKERS, database.STEP_LIKES_COUNTER, database.STEP_OUTBOX}, func() error {
+			return db.LikeAPost(ctx, post.Post_ID, "4")
+		}},
+		{"UnLikeAPost", []string{database.STEP_LIKERS, database.STEP_LIKES_COUNTER, database.STEP_OUTBOX}, func() error {
+			return db.UnLikeAPost(ctx, post.Post_ID, "3")
+		}},
+		{"AddNewRetweet", []string{database.STEP_RETWEETERS, database.STEP_POST, database.STEP_RETWEETS_COUNTER, database.STEP_OUTBOX}, func() error {
+			retweet := models.NewRetweetDBPost(front, "4")
+			retweet.Time = base.Add(2 * time.Second)
+			_, err := db.AddNewRetweet(ctx, retweet)
+			return err
+		}},
+		{"DeleteRetweet", []string{database.STEP_RETWEETERS, database.STEP_RETWEETS_COUNTER, database.STEP_RETWEET_POSTS, database.STEP_OUTBOX}, func() error {
+			return db.DeleteRetweet(ctx, post.Post_ID, "2")
+		}},
+		{"DeletePost", []string{database.STEP_POST, database.STEP_TAG_METRICS, database.STEP_RETWEET_POSTS, database.STEP_LIKERS, database.STEP_RETWEETERS, database.STEP_TOMBSTONE, database.STEP_QUOTES_COUNTER, database.STEP_OUTBOX}, func() error {
+			return db.DeletePost(ctx, post.Post_ID)
+		}},
+		{"BlockPost", []string{database.STEP_POST, database.STEP_OUTBOX}, func() error {
+			return db.BlockPost(ctx, post.Post_ID)
+		}},
+		{"AddNewPostWithOutbox", []string{database.STEP_POST, database.STEP_OUTBOX}, func() error {
+			mentioning := models.NewDBPost("1", "mention", nil, true, models.MediaInfo{}, []string{"2"})
+			mentioning.Time = base.Add(3 * time.Second)
+			mention := models.NewMentionOutboxMessage(models.MentionNotificationRequest{UserId: "2", TaggerId: "1", PostId: mentioning.Post_ID})
+			_, err := db.AddNewPostWithOutbox(ctx, mentioning, []models.OutboxMessage{mention})
+			return err
+		}},
+		{"EditPost", []string{database.STEP_POST, database.STEP_TAG_METRICS, database.STEP_REVISION, database.STEP_OUTBOX}, func() error {
+			content := "atomic #edited"
+			_, err := db.EditPost(ctx, post.Post_ID, models.EditPostExpectedFormat{Content: &content}, "1")
+			return err
+		}},
+	}
+
+	injected := errors.New("injected failure")
+	before := conformanceInteractionState(t, ctx, db)
+
+	pendingBefore, err := db.CountOutboxMessages(ctx, models.OUTBOX_PENDING)
+	assert.Nil(t, err)
+
+	for _, w := range writes {
+		for _, step := range w.steps {
+			hooker.SetWriteStepHook(func(operation string, current string) error {
+				if operation == w.operation && current == step {
+					return injected
+				}
+				return nil
+			})
+
+			err := w.write()
+			assert.ErrorIs(t, err, injected, "%s failing at %s", w.operation, step)
+
+			hooker.SetWriteStepHook(nil)
+
+			assert.Equal(t, before, conformanceInteractionState(t, ctx, db), "%s failing at %s", w.operation, step)
+
+			pending, err := db.CountOutboxMessages(ctx, models.OUTBOX_PENDING)
+			assert.Nil(t, err)
+			assert.Equal(t, pendingBefore, pending, "%s failing at %s", w.operation, step)
+		}
+	}
+
+	revisions, _, err := db.GetRevisions(ctx, post.Post_ID, models.NewLimitConfig("", "0", "10"))
+	assert.Nil(t, err)
+	assert.Empty(t, revisions, "Failed edits should leave no revision")
+
+	assert.Nil(t, db.LikeAPost(ctx, post.Post_ID, "4"))
+
+	fetched, err := db.GetPost(ctx, post.Post_ID, "4")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, fetched.Likes)
+	assert.True(t, fetched.User_Liked)
+}
+
+// conformanceInteractionState lists every post as seen by each of the users
+// taking part in the interactions, counters and liked/retweeted flags included.
+func conformanceInteractionState(t *testing.T, ctx context.Context, db database.Database) [][]models.FrontPost {
+	state := [][]models.FrontPost{}
+
+	for _, viewer := range []string{"2", "3", "4"} {
+		posts, _, err := db.GetAllPosts(ctx, conformanceNow(), viewer)
+		assert.Nil(t, err)
+		state = append(state, posts)
+	}
+
+	return state
+}
+
+func conformanceCursorPagination(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+
+	// The first three posts share their timestamp, so only the post ID can
+	// tell where a page ends.
+	for _, content := range []string{"first", "second", "third"} {
+		insertConformancePost(t, ctx, db, "1", content, nil, true, base)
+	}
+	insertConformancePost(t, ctx, db, "1", "fourth", nil, true, base.Add(time.Second))
+	insertConformancePost(t, ctx, db, "2", "fifth", nil, true, base.Add(2*time.Second))
+
+	expected := []string{"fifth", "fourth", "third", "second", "first"}
+
+	all, hasMore, err := db.GetAllPosts(ctx, conformanceNow(), database.ADMIN)
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, expected, postContents(all))
+
+	assert.Equal(t, expected, collectCursorPages(t, func(limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+		return db.GetAllPosts(ctx, limitConfig, database.ADMIN)
+	}))
+
+	assert.Equal(t, []string{"fourth", "third", "second", "first"}, collectCursorPages(t, func(limitConfig models.LimitConfig) ([]models.FrontPost, bool, error) {
+		return db.GetUserFeedFollowing(ctx, []string{"1"}, "3", limitConfig)
+	}))
+}
+
+// collectCursorPages reads a listing two posts at a time, starting with an
+// offset page and following the cursor of the last post of every page.
+func collectCursorPages(t *testing.T, fetch func(limitConfig models.LimitConfig) ([]models.FrontPost, bool, error)) []string {
+	contents := []string{}
+	limitConfig := models.NewLimitConfig(time.Now().UTC().Add(time.Minute).Format(time.RFC3339), "0", "2")
+
+	for page := 0; page < 10; page++ {
+		posts, hasMore, err := fetch(limitConfig)
+		assert.Nil(t, err)
+
+		contents = append(contents, postContents(posts)...)
+
+		if !hasMore {
+			return contents
+		}
+
+		cursor, err := models.NewPostCursor(posts[len(posts)-1])
+		assert.Nil(t, err)
+
+		limitConfig = models.LimitConfig{Limit: 2, Cursor: &cursor}
+	}
+
+	t.Fatal("cursor pagination did not end")
+	return nil
+}
+
+func conformancePageViewerStateMatchesSinglePost(t *testing.T, ctx context.Context, db database.Database) {
+	base := conformanceBaseTime()
+
+	for i := 0; i < 6; i++ {
+		post := insertConformancePost(t, ctx, db, "1", "post", []string{"tag"}, true, base.Add(time.Duration(i)*time.Second))
+
+		if i%2 == 0 {
+			assert.Nil(t, db.LikeAPost(ctx, post.Post_ID, "2"))
+		}
+		if i%3 == 0 {
+			insertConformanceRetweet(t, ctx, db, post, "2", base.Add(time.Duration(i)*time.Second+time.Millisecond))
+		}
+		if i < 3 {
+			assert.Nil(t, db.AddFavorite(ctx, post.Post_ID, "2"))
+		}
+	}
+
+	for _, askerID := range []string{"2", "3", database.ADMIN} {
+		page, _, err := db.GetAllPosts(ctx, conformanceNow(), askerID)
+		assert.Nil(t, err)
+		assert.Equal(t, 8, len(page))
+
+		for _, post := range page {
+			single, err := db.GetPost(ctx, post.Post_ID, askerID)
+			assert.Nil(t, err)
+			assert.Equal(t, single, post, "post %s seen by %s", post.Post_ID, askerID)
+		}
+	}
+}
+
+func conformanceOutbox(t *testing.T, ctx context.Context, db database.Database) {
+	post := models.NewDBPost("1", "hi", nil, true, models.MediaInfo{}, []string{"2", "3"})
+
+	first := models.NewMentionOutboxMessage(models.MentionNotificationRequest{UserId: "2", TaggerId: "1", PostId: post.Post_ID})
+	second := models.NewMentionOutboxMessage(models.MentionNotificationRequest{UserId: "3", TaggerId: "1", PostId: post.Post_ID})
+	first.Next_Attempt = first.Next_Attempt.Add(-time.Millisecond)
+
+	_, err := db.AddNewPostWithOutbox(ctx, post, []models.OutboxMessage{first, second})
+	assert.Nil(t, err)
+
+	_, err = db.GetPost(ctx, post.Post_ID, "2")
+	assert.Nil(t, err)
+
+	pending, err := db.CountOutboxMessages(ctx, models.OUTBOX_PENDING)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, pending)
+
+	// Claimed messages are leased, so they are not handed out twice.
+	claimed, err := db.ClaimOutboxMessages(ctx, "a", time.Now(), 1, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(claimed))
+	assert.Equal(t, first.Message_ID, claimed[0].Message_ID)
+	assert.Equal(t, first.Mention, claimed[0].Mention)
+	assert.Equal(t, "a", claimed[0].Claimed_By)
+	assert.True(t, claimed[0].Next_Attempt.After(time.Now()))
+	claimedFirst := claimed[0]
+
+	claimed, err = db.ClaimOutboxMessages(ctx, "a", time.Now(), 10, 100*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(claimed))
+	assert.Equal(t, second.Message_ID, claimed[0].Message_ID)
+	claimedSecond := claimed[0]
+
+	claimed, err = db.ClaimOutboxMessages(ctx, "b", time.Now(), 10, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(claimed))
+
+	// Once a lease runs out the message is claimed again, and the dispatcher
+	// that held it can no longer update it.
+	time.Sleep(150 * time.Millisecond)
+
+	claimed, err = db.ClaimOutboxMessages(ctx, "b", time.Now(), 10, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(claimed))
+	assert.Equal(t, second.Message_ID, claimed[0].Message_ID)
+
+	claimedSecond.Attempts = 1
+	assert.ErrorIs(t, db.UpdateOutboxMessage(ctx, claimedSecond), postErrors.ErrOutboxLeaseLost)
+
+	failed := claimedFirst
+	failed.Status = models.OUTBOX_FAILED
+	failed.Attempts = 3
+	failed.Last_Error = "down"
+
+	stolen := failed
+	stolen.Claimed_By = "b"
+	assert.ErrorIs(t, db.UpdateOutboxMessage(ctx, stolen), postErrors.ErrOutboxLeaseLost)
+
+	assert.Nil(t, db.UpdateOutboxMessage(ctx, failed))
+	assert.ErrorIs(t, db.UpdateOutboxMessage(ctx, failed), postErrors.ErrOutboxLeaseLost, "An update should release the lease")
+
+	listed, err := db.GetOutboxMessages(ctx, models.OUTBOX_FAILED, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(listed))
+	assert.Equal(t, first.Message_ID, listed[0].Message_ID)
+	assert.Equal(t, 3, listed[0].Attempts)
+	assert.Equal(t, "down", listed[0].Last_Error)
+
+	assert.Nil(t, db.DeleteOutboxMessage(ctx, second.Message_ID))
+
+	pending, err = db.CountOutboxMessages(ctx, models.OUTBOX_PENDING)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, pending)
+
+	count, err := db.CountOutboxMessages(ctx, models.OUTBOX_FAILED)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -5,7 +5,6 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"server/src/auth"
-	"server/src/router"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,7 +16,7 @@ func TestDeletePost(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	token, err := auth.GenerateToken("1", "username", false)
 
@@ -47,7 +46,7 @@ func TestDeleteUnexistentPost(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	token, err := auth.GenerateToken("1", "username", false)
 
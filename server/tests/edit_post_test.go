@@ -13,7 +13,6 @@ import (
 
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 )
 
@@ -23,7 +22,7 @@ func TestEditPostContent(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 
@@ -70,7 +69,7 @@ func TestEditPostTags(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1"
 
@@ -120,7 +119,7 @@ func TestEditPostMediaURL(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1"
 
@@ -169,7 +168,7 @@ func TestEditPostPublicToPrivate(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1"
 
@@ -218,7 +217,7 @@ func TestEditPostPrivateToPublic(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1"
 
@@ -265,7 +264,7 @@ func TestEditMentions(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 
@@ -315,7 +314,7 @@ func TestEditPostTagsAndMentions(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 
@@ -367,7 +366,7 @@ func TestEditPostTagsAndMentions(t *testing.T) {
 func TestEditPost(t *testing.T) {
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1"
 	token, err := auth.GenerateToken(author_id, "username", false)
@@ -0,0 +1,223 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/auth"
+	"server/src/database"
+	"server/src/models"
+	"server/src/service"
+	"server/src/standin"
+)
+
+const TEST_EVENTS_EXCHANGE = "test.events"
+
+func newBrokerDispatcher(db database.Database, broker *standin.Broker) *service.OutboxDispatcher {
+	publisher := service.NewBrokerPublisher(broker, TEST_EVENTS_EXCHANGE)
+	return service.NewOutboxDispatcher(db, service.NewFakeNotificationClient(), publisher, testOutboxConfig())
+}
+
+func serveAs(t *testing.T, r *gin.Engine, userID string, admin bool, method string, url string, body interface{}) *httptest.ResponseRecorder {
+	token, err := auth.GenerateToken(userID, "username", admin)
+	assert.Equal(t, nil, err, "Error should be nil")
+
+	marshalledData, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, url, bytes.NewReader(marshalledData))
+	req.Header.Add("content-type", "application/json")
+	addAuthorization(req, token)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func routingKeys(messages []standin.BrokerMessage) []string {
+	keys := []string{}
+	for _, message := range messages {
+		keys = append(keys, message.RoutingKey)
+	}
+	return keys
+}
+
+func TestEventsPublishedForEveryChange(t *testing.T) {
+	log.Println("TestEventsPublishedForEveryChange")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+	broker := standin.NewBroker()
+	dispatcher := newBrokerDispatcher(db, broker)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "hello #go", []string{"go"}, []string{}, true, "", r, t)
+	id := post.Post_ID
+	content := "bye #rust"
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/like/"+id, nil).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "DELETE", "/twitsnap/like/"+id, nil).Code)
+	assert.Equal(t, http.StatusCreated, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/retweet/"+id, nil).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "DELETE", "/twitsnap/retweet/"+id, nil).Code)
+	assert.Equal(t, http.StatusOK, serveAs(t, r, service.TEST_USER_ONE, false, "PUT", "/twitsnap/edit/"+id, models.EditPostExpectedFormat{Content: &content}).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, true, "POST", "/twitsnap/block/"+id, nil).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, true, "DELETE", "/twitsnap/block/"+id, nil).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, false, "DELETE", "/twitsnap/"+id, nil).Code)
+
+	assert.Equal(t, 0, len(broker.Messages()), "Events should wait for the dispatcher")
+
+	assert.Equal(t, 9, dispatchOutbox(t, dispatcher))
+
+	messages := broker.Messages()
+
+	assert.ElementsMatch(t, []string{
+		models.NEW_CONTENT, models.NEW_LIKE, models.REMOVED_LIKE, models.NEW_RETWEET, models.REMOVED_RETWEET,
+		models.EDITED_CONTENT, models.BLOCKED_CONTENT, models.UNBLOCKED_CONTENT, models.DELETED_CONTENT,
+	}, routingKeys(messages))
+
+	for _, message := range messages {
+		assert.Equal(t, TEST_EVENTS_EXCHANGE, message.Exchange)
+
+		event := struct {
+			MessageID   string          `json:"message_id"`
+			MessageType string          `json:"message_type"`
+			Message     json.RawMessage `json:"message"`
+		}{}
+		assert.Equal(t, nil, json.Unmarshal(message.Body, &event), "Error should be nil")
+		assert.Equal(t, message.MessageID, event.MessageID)
+		assert.Equal(t, message.RoutingKey, event.MessageType)
+
+		switch event.MessageType {
+		case models.NEW_CONTENT, models.EDITED_CONTENT:
+			newContent := models.New_Content{}
+			assert.Equal(t, nil, json.Unmarshal(event.Message, &newContent), "Error should be nil")
+			assert.Equal(t, id, newContent.Post_ID)
+			assert.Equal(t, service.TEST_USER_ONE, newContent.Author_ID)
+			assert.NotEqual(t, "", newContent.Timestamp)
+			if event.MessageType == models.NEW_CONTENT {
+				assert.Equal(t, []string{"go"}, newContent.Hashtags)
+			} else {
+				assert.Equal(t, []string{"rust"}, newContent.Hashtags)
+			}
+		case models.NEW_LIKE, models.REMOVED_LIKE, models.NEW_RETWEET, models.REMOVED_RETWEET:
+			engagement := models.Engagement{}
+			assert.Equal(t, nil, json.Unmarshal(event.Message, &engagement), "Error should be nil")
+			assert.Equal(t, id, engagement.Post_ID)
+			assert.Equal(t, service.TEST_USER_TWO, engagement.User_ID)
+		default:
+			change := models.Content_Change{}
+			assert.Equal(t, nil, json.Unmarshal(event.Message, &change), "Error should be nil")
+			assert.Equal(t, id, change.Post_ID)
+		}
+	}
+}
+
+func TestFailedWritesPublishNothing(t *testing.T) {
+	log.Println("TestFailedWritesPublishNothing")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+	broker := standin.NewBroker()
+	dispatcher := newBrokerDispatcher(db, broker)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "hello", []string{}, []string{}, true, "", r, t)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/like/"+post.Post_ID, nil).Code)
+	assert.NotEqual(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/like/"+post.Post_ID, nil).Code)
+
+	assert.Equal(t, 2, dispatchOutbox(t, dispatcher))
+	assert.ElementsMatch(t, []string{models.NEW_CONTENT, models.NEW_LIKE}, routingKeys(broker.Messages()))
+}
+
+func TestEventsRedeliveredWhileBrokerDown(t *testing.T) {
+	log.Println("TestEventsRedeliveredWhileBrokerDown")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+	broker := standin.NewBroker()
+	broker.Fail(errors.New("broker down"))
+	dispatcher := newBrokerDispatcher(db, broker)
+
+	makeAndAssertPost(service.TEST_USER_ONE, "hello", []string{}, []string{}, true, "", r, t)
+
+	assert.Equal(t, 0, dispatchOutbox(t, dispatcher))
+
+	_, summary := getOutbox(t, r, true, models.OUTBOX_PENDING)
+	events := outboxMessagesOfKind(summary, models.EVENT)
+
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, "broker down", events[0].Last_Error)
+
+	queued, err := models.DecodeQueueMessage(events[0].Event)
+	assert.Equal(t, nil, err, "Error should be nil")
+
+	broker.Fail(nil)
+
+	assert.Equal(t, 1, dispatchOutbox(t, dispatcher))
+
+	messages := broker.Messages()
+
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, models.NEW_CONTENT, messages[0].RoutingKey)
+	assert.Equal(t, queued.MessageID, messages[0].MessageID, "A retried event should keep its message id")
+}
+
+func TestInProcessPublisherRetriesFailedSubscribers(t *testing.T) {
+	log.Println("TestInProcessPublisherRetriesFailedSubscribers")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	received := []models.QueueMessage{}
+	failing := true
+
+	publisher := service.NewInProcessPublisher()
+	publisher.Subscribe(func(ctx context.Context, event models.QueueMessage) error {
+		if failing {
+			return errors.New("subscriber down")
+		}
+		received = append(received, event)
+		return nil
+	})
+
+	dispatcher := service.NewOutboxDispatcher(db, service.NewFakeNotificationClient(), publisher, testOutboxConfig())
+
+	makeAndAssertPost(service.TEST_USER_ONE, "hello", []string{}, []string{}, true, "", r, t)
+
+	assert.Equal(t, 0, dispatchOutbox(t, dispatcher))
+
+	failing = false
+
+	assert.Equal(t, 1, dispatchOutbox(t, dispatcher))
+	assert.Equal(t, 1, len(received))
+	assert.Equal(t, models.NEW_CONTENT, received[0].MessageType)
+}
+
+func TestRemovalsThatChangeNothingPublishNothing(t *testing.T) {
+	log.Println("TestRemovalsThatChangeNothingPublishNothing")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+	broker := standin.NewBroker()
+	dispatcher := newBrokerDispatcher(db, broker)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "hello", []string{}, []string{}, true, "", r, t)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "DELETE", "/twitsnap/like/"+post.Post_ID, nil).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "DELETE", "/twitsnap/retweet/"+post.Post_ID, nil).Code)
+
+	assert.Equal(t, 1, dispatchOutbox(t, dispatcher))
+	assert.Equal(t, []string{models.NEW_CONTENT}, routingKeys(broker.Messages()))
+
+	unchanged := getPostAs(t, r, service.TEST_USER_ONE, post.Post_ID)
+
+	assert.Equal(t, 0, unchanged.Likes)
+	assert.Equal(t, 0, unchanged.Retweets)
+}
@@ -14,7 +14,6 @@ import (
 
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 
 	postErrors "server/src/all_errors"
@@ -29,7 +28,7 @@ func TestFeedFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{"tag1", "tag2"}
 
@@ -84,7 +83,7 @@ func TestFeedFollowingNextOffset(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{"tag1", "tag2"}
 
@@ -159,7 +158,7 @@ func TestFeedBadRequestFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	token, err := auth.GenerateToken(service.TEST_USER_ONE, "username", false)
 
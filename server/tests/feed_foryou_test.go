@@ -14,7 +14,6 @@ import (
 
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 
 	postErrors "server/src/all_errors"
@@ -30,7 +29,7 @@ func TestFeedForYou(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag5"}
 
@@ -89,7 +88,7 @@ func TestFeedForyouNotFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag5"}
 
@@ -149,7 +148,7 @@ func TestFeedForyouNextOffset(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag5"}
 
@@ -224,7 +223,7 @@ func TestFeedForYouNoTagFound(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag5"}
 
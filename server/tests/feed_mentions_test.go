@@ -0,0 +1,46 @@
+package test
+
+import (
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"server/src/router"
+	"server/src/service"
+)
+
+const (
+	FEED_TYPE_M = "mentions"
+)
+
+func TestGetFeedMentions(t *testing.T) {
+	log.Println("TestGetFeedMentions")
+
+	db := connectToDatabase()
+	users := service.NewFakeUsersClient()
+	users.SetFollowing(service.TEST_USER_THREE, []string{service.TEST_USER_ONE})
+	r := router.CreateRouter(db, users, service.NewFakeNotificationClient())
+
+	mentioned := []string{service.TEST_USER_THREE}
+
+	public := makeAndAssertPost(service.TEST_USER_TWO, "public mention", []string{}, mentioned, true, "", r, t)
+	followed := makeAndAssertPost(service.TEST_USER_ONE, "private mention by a followed user", []string{}, mentioned, false, "", r, t)
+	makeAndAssertPost(service.TEST_USER_TWO, "private mention by someone else", []string{}, mentioned, false, "", r, t)
+	makeAndAssertPost(service.TEST_USER_ONE, "mentions somebody else", []string{}, []string{service.TEST_USER_TWO}, true, "", r, t)
+	blocked := makeAndAssertPost(service.TEST_USER_ONE, "blocked mention", []string{}, mentioned, true, "", r, t)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, true, "POST", "/twitsnap/block/"+blocked.Post_ID, nil).Code)
+	assert.Equal(t, http.StatusCreated, serveAs(t, r, service.TEST_USER_ONE, false, "POST", "/twitsnap/retweet/"+public.Post_ID, nil).Code)
+
+	result := getFeedAs(t, r, service.TEST_USER_THREE, FEED_TYPE_M)
+
+	assert.Equal(t, []string{followed.Post_ID, public.Post_ID}, postIDs(result.Data), "Retweets should not repeat a mention")
+	assert.False(t, result.Data[0].Is_Retweet)
+
+	result = getFeedAs(t, r, service.TEST_USER_TWO, FEED_TYPE_M)
+
+	assert.Len(t, result.Data, 1)
+	assert.Equal(t, "mentions somebody else", result.Data[0].Content)
+}
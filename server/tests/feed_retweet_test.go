@@ -14,7 +14,6 @@ import (
 
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 
 )
@@ -28,7 +27,7 @@ func TestGetFeedRetweet(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{"tag1", "tag2"}
 
@@ -93,7 +92,7 @@ func TestGetFeedRetweetNotFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{"tag1", "tag2"}
 
@@ -158,7 +157,7 @@ func TestFeedRetweetNextOffset(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag5"}
 
@@ -14,7 +14,6 @@ import (
 
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 
 )
@@ -28,7 +27,7 @@ func TestGetFeedSingle(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{"tag1", "tag2"}
 
@@ -83,7 +82,7 @@ func TestGetFeedSingleNotFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	authorId := service.TEST_NOT_FOLLOWING_ID
 
@@ -141,7 +140,7 @@ func TestFeedSingleWithFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	authorId := service.TEST_USER_ONE
 
@@ -198,7 +197,7 @@ func TestFeedSingleNextOffset(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag5"}
 
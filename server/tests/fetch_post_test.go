@@ -12,7 +12,6 @@ import (
 
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 )
 
 func TestGetPostWithValidID(t *testing.T) {
@@ -21,7 +20,7 @@ func TestGetPostWithValidID(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1"
 
@@ -56,7 +55,7 @@ func TestGetPostWithInvalidID(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "54"
 	token, err := auth.GenerateToken(author_id, "username", true)
@@ -0,0 +1,173 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/auth"
+	"server/src/models"
+	"server/src/service"
+	"server/src/standin"
+)
+
+func TestFollowingWalksEveryPage(t *testing.T) {
+	log.Println("TestFollowingWalksEveryPage")
+
+	followed := []string{}
+	for i := 0; i < 2*service.FOLLOWING_PAGE_SIZE+50; i++ {
+		followed = append(followed, "f"+strconv.Itoa(i))
+	}
+
+	users := &countingUsersServer{users: standin.NewUsersService(standin.User{ID: "a", Following: followed}), hits: map[string]int{}}
+	server := httptest.NewServer(users.count(users.users.Handler()))
+	defer server.Close()
+
+	client := newUsersClient(server.URL, testOutboundConfig())
+
+	following, err := client.GetFollowing(context.Background(), "a", "token")
+
+	assert.Equal(t, nil, err, "Error should be nil")
+	assert.Equal(t, followed, following)
+	assert.Equal(t, 3, users.hitsFor("a/following"))
+}
+
+func TestFollowingStopsOnStuckOffset(t *testing.T) {
+	log.Println("TestFollowingStopsOnStuckOffset")
+
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"data": [{"profile": {"id": "b"}}], "pagination": {"next_offset": 1, "limit": 1}}`))
+	}))
+	defer server.Close()
+
+	client := newUsersClient(server.URL, testOutboundConfig())
+
+	_, err := client.GetFollowing(context.Background(), "a", "token")
+
+	assert.NotEqual(t, nil, err, "Error should not be nil")
+	assert.Equal(t, 2, requests)
+}
+
+func getCacheMetrics(t *testing.T, r *gin.Engine, admin bool) (int, models.ServiceCacheMetrics) {
+	token, err := auth.GenerateToken("1", "username", admin)
+	assert.Equal(t, nil, err, "Error should be nil")
+
+	req, _ := http.NewRequest("GET", "/twitsnap/cache-metrics", nil)
+	addAuthorization(req, token)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	metrics := models.ServiceCacheMetrics{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &metrics)
+
+	return recorder.Code, metrics
+}
+
+func TestFollowingCachedUntilInvalidated(t *testing.T) {
+	log.Println("TestFollowingCachedUntilInvalidated")
+
+	db := connectToDatabase()
+	users, r := newCountingUsersServer(t, db, 0, "b", "c")
+	users.users.AddUser(standin.User{ID: "a", Username: "usera", Following: []string{"b"}})
+
+	seedPosts(t, db, []string{"b", "c"})
+
+	first := getFeedAs(t, r, "a", FEED_TYPE_F)
+	second := getFeedAs(t, r, "a", FEED_TYPE_F)
+
+	assert.Equal(t, 1, users.hitsFor("a/following"))
+	assert.Equal(t, first.Data, second.Data)
+	assert.Equal(t, "b", second.Data[0].Author_Info.Author_ID)
+
+	users.users.AddUser(standin.User{ID: "a", Username: "usera", Following: []string{"c"}})
+
+	assertDenied(t, serveAs(t, r, "a", false, "DELETE", "/twitsnap/following-cache/a", nil), service.INVALIDATE_ACTION)
+	assertDenied(t, serveAs(t, r, "b", false, "DELETE", "/twitsnap/following-cache/a", nil), service.INVALIDATE_ACTION)
+	assert.Equal(t, "b", getFeedAs(t, r, "a", FEED_TYPE_F).Data[0].Author_Info.Author_ID, "A denied invalidation should keep the cached list")
+	assert.Equal(t, 1, users.hitsFor("a/following"))
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, "users-service", true, "DELETE", "/twitsnap/following-cache/a", nil).Code)
+
+	third := getFeedAs(t, r, "a", FEED_TYPE_F)
+
+	assert.Equal(t, 2, users.hitsFor("a/following"))
+	assert.Equal(t, 1, len(third.Data))
+	assert.Equal(t, "c", third.Data[0].Author_Info.Author_ID)
+
+	code, metrics := getCacheMetrics(t, r, true)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.CacheMetrics{Hits: 2, Misses: 2, Hit_Rate: 0.5, Entries: 1}, metrics.Following)
+}
+
+// stalledFollowing reads the following list and then waits for release
+// before answering, like a users service that is slow to reply.
+type stalledFollowing struct {
+	*service.FakeUsersClient
+	read    chan struct{}
+	release chan struct{}
+}
+
+func (s stalledFollowing) GetFollowing(ctx context.Context, userID string, token string) ([]string, error) {
+	following, err := s.FakeUsersClient.GetFollowing(ctx, userID, token)
+
+	select {
+	case s.read <- struct{}{}:
+	default:
+	}
+	<-s.release
+
+	return following, err
+}
+
+func TestInvalidationDuringFetchIsKept(t *testing.T) {
+	log.Println("TestInvalidationDuringFetchIsKept")
+
+	db := connectToDatabase()
+	users := stalledFollowing{service.NewFakeUsersClient(), make(chan struct{}, 1), make(chan struct{})}
+	r := createRouterWithUsersClient(db, users)
+
+	users.SetFollowing("a", []string{"b"})
+	seedPosts(t, db, []string{"b", "c"})
+
+	stale := make(chan models.ReturnPaginatedPosts)
+	go func() {
+		stale <- getFeedAs(t, r, "a", FEED_TYPE_F)
+	}()
+
+	// The list is read before the user follows someone else, and comes back
+	// after the invalidation.
+	<-users.read
+	users.SetFollowing("a", []string{"c"})
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, "users-service", true, "DELETE", "/twitsnap/following-cache/a", nil).Code)
+	close(users.release)
+
+	assert.Equal(t, "b", (<-stale).Data[0].Author_Info.Author_ID)
+
+	fresh := getFeedAs(t, r, "a", FEED_TYPE_F)
+
+	assert.Equal(t, 1, len(fresh.Data))
+	assert.Equal(t, "c", fresh.Data[0].Author_Info.Author_ID, "The stale list should not be cached")
+}
+
+func TestCacheMetricsAdminOnly(t *testing.T) {
+	log.Println("TestCacheMetricsAdminOnly")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	code, _ := getCacheMetrics(t, r, false)
+
+	assert.Equal(t, http.StatusForbidden, code)
+}
@@ -14,7 +14,6 @@ import (
 
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 )
 
@@ -23,7 +22,7 @@ func TestGetAll(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag5"}
 
@@ -82,7 +81,7 @@ func TestGetAllDeniedAccess(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	token, err := auth.GenerateToken("1", "username", false)
 
@@ -115,7 +114,7 @@ func TestGetAllNextOffset(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag5"}
 
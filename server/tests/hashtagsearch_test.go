@@ -14,7 +14,6 @@ import (
 
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 )
 
@@ -24,7 +23,7 @@ func TestHashagSearch(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags_wanted := []string{"tag5", "tag6"}
 
@@ -87,7 +86,7 @@ func TestHashagSearchNotFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags_wanted := []string{"tag5", "tag6"}
 
@@ -148,7 +147,7 @@ func TestHashtagSearchFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags_wanted := []string{"tag5", "tag6"}
 
@@ -208,7 +207,7 @@ func TestHashtagSearchNextOffset(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags_wanted := []string{"tag5", "tag6"}
 
@@ -7,7 +7,6 @@ import (
 	"net/http/httptest"
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 	"testing"
 	"time"
@@ -20,7 +19,7 @@ func TestLikingAPost(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 	liker_id := service.TEST_USER_TWO
@@ -61,7 +60,7 @@ func TestLikingAPost(t *testing.T) {
 func TestUnlikingAPost(t *testing.T) {
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 	liker_id := service.TEST_USER_TWO
@@ -126,7 +125,7 @@ func TestUnlikingAPost(t *testing.T) {
 func TestSeeLikedTweetInFeedFollowing(t *testing.T) {
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 	liker_id := service.TEST_USER_TWO
@@ -182,7 +181,7 @@ func TestUserCanNotLikeTwice(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 	liker_id := service.TEST_USER_TWO
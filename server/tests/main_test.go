@@ -0,0 +1,21 @@
+package test
+
+import (
+	"os"
+	"testing"
+)
+
+var testEnvDefaults = map[string]string{
+	"JWT_SECRET":         "test-secret",
+	"JWT_DURATION_HOURS": "1",
+}
+
+func TestMain(m *testing.M) {
+	for key, value := range testEnvDefaults {
+		if os.Getenv(key) == "" {
+			os.Setenv(key, value)
+		}
+	}
+
+	os.Exit(m.Run())
+}
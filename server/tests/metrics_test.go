@@ -5,13 +5,14 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,7 +21,7 @@ func TestMetricsLikes(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	time_init := time.Now().Format(time.RFC3339)
 
@@ -105,7 +106,7 @@ func TestMetricsRetweets(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	time_init := time.Now().Format(time.RFC3339)
 
@@ -170,7 +171,7 @@ func TestLikesAndRetweets(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	time_init := time.Now().Format(time.RFC3339)
 
@@ -257,3 +258,113 @@ func TestLikesAndRetweets(t *testing.T) {
 	assert.Equal(t, result_post.Retweets, 2, "Post should have 2 retweets")
 	assert.Equal(t, result_post.Posts, 1, "There should be 1 post")
 }
+
+func getMetricsSeries(t *testing.T, r *gin.Engine, userID string, query url.Values) (int, models.UserMetricsSeries) {
+	recorder := serveAs(t, r, userID, false, "GET", "/twitsnap/metrics?"+query.Encode(), nil)
+
+	series := models.UserMetricsSeries{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &series)
+
+	return recorder.Code, series
+}
+
+func TestMetricsSeries(t *testing.T) {
+	log.Println("TestMetricsSeries")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	location, err := time.LoadLocation("Asia/Kolkata")
+	assert.Equal(t, nil, err, "Error should be nil")
+
+	now := time.Now().In(location)
+	hour := models.TruncateToInterval(now, models.HOUR_INTERVAL, location)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "charted", []string{}, []string{}, true, "", r, t)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/like/"+post.Post_ID, nil).Code)
+
+	query := url.Values{}
+	query.Set("time", hour.Add(-2*time.Hour).Format(time.RFC3339))
+	query.Set("end_time", hour.Add(time.Hour).Format(time.RFC3339))
+	query.Set("interval", models.HOUR_INTERVAL)
+	query.Set("timezone", "Asia/Kolkata")
+
+	code, series := getMetricsSeries(t, r, service.TEST_USER_ONE, query)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.HOUR_INTERVAL, series.Interval)
+	assert.Equal(t, "Asia/Kolkata", series.Timezone)
+	assert.Equal(t, models.UserMetrics{Likes: 1, Retweets: 0, Posts: 1}, series.Totals)
+	assert.Equal(t, 3, len(series.Series), "Empty hours should be filled in")
+
+	for i, bucket := range series.Series {
+		assert.True(t, hour.Add(time.Duration(i-2)*time.Hour).Equal(bucket.Time))
+		_, offset := bucket.Time.Zone()
+		assert.Equal(t, 5*60*60+30*60, offset, "Buckets should be in the timezone asked for")
+	}
+
+	assert.Equal(t, 1, series.Series[2].Posts)
+	assert.Equal(t, 1, series.Series[2].Likes)
+	assert.Equal(t, 0, series.Series[0].Posts)
+}
+
+func TestMetricsSeriesDefaultsToDays(t *testing.T) {
+	log.Println("TestMetricsSeriesDefaultsToDays")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	makeAndAssertPost(service.TEST_USER_ONE, "charted", []string{}, []string{}, true, "", r, t)
+
+	now := time.Now().UTC()
+
+	query := url.Values{}
+	query.Set("time", now.AddDate(0, 0, -2).Format(time.RFC3339))
+	query.Set("end_time", now.Add(time.Minute).Format(time.RFC3339))
+	query.Set("timezone", "UTC")
+
+	code, series := getMetricsSeries(t, r, service.TEST_USER_ONE, query)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.DAY_INTERVAL, series.Interval)
+	assert.Equal(t, 3, len(series.Series))
+	assert.Equal(t, 1, series.Totals.Posts)
+
+	query.Set("interval", models.MONTH_INTERVAL)
+
+	code, series = getMetricsSeries(t, r, service.TEST_USER_ONE, query)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, series.Totals.Posts)
+	assert.Equal(t, 1, series.Series[len(series.Series)-1].Posts)
+}
+
+func TestMetricsSeriesBadRequest(t *testing.T) {
+	log.Println("TestMetricsSeriesBadRequest")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	now := time.Now().UTC()
+
+	query := url.Values{}
+	query.Set("time", now.Add(-time.Hour).Format(time.RFC3339))
+	query.Set("end_time", now.Format(time.RFC3339))
+	query.Set("interval", models.WEEK_INTERVAL)
+	query.Set("timezone", "Nowhere/Else")
+
+	code, _ := getMetricsSeries(t, r, service.TEST_USER_ONE, query)
+	assert.Equal(t, http.StatusBadRequest, code, "Unknown timezones should be rejected")
+
+	query.Set("timezone", "UTC")
+	query.Set("interval", "decade")
+
+	code, _ = getMetricsSeries(t, r, service.TEST_USER_ONE, query)
+	assert.Equal(t, http.StatusBadRequest, code, "Unknown intervals should be rejected")
+
+	query.Set("interval", models.HOUR_INTERVAL)
+	query.Set("time", now.AddDate(-1, 0, 0).Format(time.RFC3339))
+
+	code, _ = getMetricsSeries(t, r, service.TEST_USER_ONE, query)
+	assert.Equal(t, http.StatusBadRequest, code, "Too many buckets should be rejected")
+}
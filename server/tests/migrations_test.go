@@ -0,0 +1,234 @@
+package test
+
+import (
+	"context"
+	"os"
+	"server/src/database"
+	"server/src/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The migrations only exist for MongoDB, so these tests need MONGO_URI.
+
+func connectToMigrationsDatabase(t *testing.T) (*mongo.Client, *mongo.Database) {
+	if os.Getenv("MONGO_URI") == "" {
+		t.Skip("MONGO_URI is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv("MONGO_URI")))
+	assert.Nil(t, err)
+
+	t.Cleanup(func() { _ = client.Disconnect(context.Background()) })
+
+	assert.Nil(t, database.NewAppDatabase(client).ClearDB(context.Background()))
+
+	return client, client.Database(database.DATABASE_NAME)
+}
+
+func appliedMigrationVersions(t *testing.T, db *mongo.Database) []int {
+	cursor, err := db.Collection(database.MIGRATIONS_COLLECTION).Find(context.Background(),
+		bson.M{"version": bson.M{"$exists": true}}, options.Find().SetSort(bson.M{"version": 1}))
+	assert.Nil(t, err)
+
+	var records []struct {
+		Version int `bson:"version"`
+	}
+	assert.Nil(t, cursor.All(context.Background(), &records))
+
+	versions := []int{}
+	for _, record := range records {
+		versions = append(versions, record.Version)
+	}
+	return versions
+}
+
+func expectedMigrationVersions() []int {
+	versions := []int{}
+	for _, migration := range database.Migrations() {
+		versions = append(versions, migration.Version)
+	}
+	return versions
+}
+
+func TestMigrationsAreIdempotent(t *testing.T) {
+	client, db := connectToMigrationsDatabase(t)
+	ctx := context.Background()
+
+	assert.Nil(t, database.RunMigrations(ctx, client))
+	assert.Nil(t, database.RunMigrations(ctx, client))
+
+	assert.Equal(t, expectedMigrationVersions(), appliedMigrationVersions(t, db))
+
+	count, err := db.Collection(database.MIGRATIONS_COLLECTION).CountDocuments(ctx, bson.M{"_id": database.MIGRATION_LOCK_ID})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count, "The lock should be released")
+}
+
+func TestMigrationsRunFromSeveralReplicas(t *testing.T) {
+	client, db := connectToMigrationsDatabase(t)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = database.RunMigrations(context.Background(), client)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, expectedMigrationVersions(), appliedMigrationVersions(t, db))
+}
+
+func TestMigrationsCreateFeedIndexes(t *testing.T) {
+	client, db := connectToMigrationsDatabase(t)
+	ctx := context.Background()
+
+	assert.Nil(t, database.RunMigrations(ctx, client))
+
+	cursor, err := db.Collection(database.FEED_COLLECTION).Indexes().List(ctx)
+	assert.Nil(t, err)
+
+	var indexes []struct {
+		Key    bson.D `bson:"key"`
+		Unique bool   `bson:"unique"`
+	}
+	assert.Nil(t, cursor.All(ctx, &indexes))
+
+	keys := map[string]bool{}
+	for _, index := range indexes {
+		name := ""
+		for _, key := range index.Key {
+			name += key.Key + ","
+		}
+		keys[name] = index.Unique
+	}
+
+	assert.True(t, keys["post_id,"], "post_id should have a unique index")
+	assert.Contains(t, keys, "author_id,time,")
+	assert.Contains(t, keys, "retweet_author,time,")
+	assert.Contains(t, keys, "tags,time,")
+	assert.Contains(t, keys, "blocked,public,time,")
+	assert.Contains(t, keys, "time,post_id,")
+}
+
+func TestMigrationsBackfillBlocked(t *testing.T) {
+	client, db := connectToMigrationsDatabase(t)
+	ctx := context.Background()
+
+	posts := db.Collection(database.FEED_COLLECTION)
+
+	_, err := posts.InsertOne(ctx, bson.M{
+		"post_id":          "old-post",
+		"original_post_id": "old-post",
+		"author_id":        "1",
+		"content":          "written before blocking existed",
+		"time":             time.Now().UTC(),
+		"public":           true,
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, database.RunMigrations(ctx, client))
+
+	var post bson.M
+	assert.Nil(t, posts.FindOne(ctx, bson.M{"post_id": "old-post"}).Decode(&post))
+	assert.Equal(t, false, post["blocked"])
+
+	fetched, err := database.NewAppDatabase(client).GetPost(ctx, "old-post", "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "written before blocking existed", fetched.Content)
+}
+
+func TestMigrationsRejectInvalidPosts(t *testing.T) {
+	client, db := connectToMigrationsDatabase(t)
+	ctx := context.Background()
+
+	assert.Nil(t, database.RunMigrations(ctx, client))
+
+	_, err := db.Collection(database.FEED_COLLECTION).InsertOne(ctx, bson.M{"post_id": "no-author", "content": 42})
+	assert.NotNil(t, err)
+}
+
+func TestMigrationsBackfillConversations(t *testing.T) {
+	client, db := connectToMigrationsDatabase(t)
+	ctx := context.Background()
+
+	posts := db.Collection(database.FEED_COLLECTION)
+
+	_, err := posts.InsertMany(ctx, []interface{}{
+		bson.M{"post_id": "old-post", "original_post_id": "old-post", "author_id": "1", "content": "written before replies existed", "time": time.Now().UTC(), "public": true, "is_retweet": false},
+		bson.M{"post_id": "old-retweet", "original_post_id": "old-post", "author_id": "1", "content": "written before replies existed", "time": time.Now().UTC(), "public": true, "is_retweet": true},
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, database.RunMigrations(ctx, client))
+
+	for _, postID := range []string{"old-post", "old-retweet"} {
+		var post bson.M
+		assert.Nil(t, posts.FindOne(ctx, bson.M{"post_id": postID}).Decode(&post))
+		assert.Equal(t, "old-post", post["conversation_id"])
+		assert.EqualValues(t, 0, post["replies"])
+	}
+
+	conversation, err := database.NewAppDatabase(client).GetConversation(ctx, "old-post", []string{}, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"old-post"}, postIDs(conversation))
+}
+
+func TestMigrationsBackfillEdits(t *testing.T) {
+	client, db := connectToMigrationsDatabase(t)
+	ctx := context.Background()
+
+	_, err := db.Collection(database.FEED_COLLECTION).InsertOne(ctx, bson.M{"post_id": "old-post", "original_post_id": "old-post", "author_id": "1", "content": "written before revisions existed", "time": time.Now().UTC(), "public": true, "is_retweet": false, "blocked": false})
+	assert.Nil(t, err)
+
+	assert.Nil(t, database.RunMigrations(ctx, client))
+
+	content := "edited after them"
+	edited, err := database.NewAppDatabase(client).EditPost(ctx, "old-post", models.EditPostExpectedFormat{Content: &content}, "1")
+
+	assert.Nil(t, err, "Posts from before revisions should be editable")
+	assert.Equal(t, content, edited.Content)
+	assert.Equal(t, 1, edited.Edits)
+}
+
+func TestMigrationsBackfillThreadPaths(t *testing.T) {
+	client, db := connectToMigrationsDatabase(t)
+	ctx := context.Background()
+
+	base := time.Now().UTC().Truncate(time.Millisecond)
+
+	_, err := db.Collection(database.FEED_COLLECTION).InsertMany(ctx, []interface{}{
+		bson.M{"post_id": "old-root", "original_post_id": "old-root", "author_id": "1", "content": "root", "time": base, "public": true, "is_retweet": false, "blocked": false, "conversation_id": "old-root", "in_reply_to_post_id": ""},
+		bson.M{"post_id": "old-second", "original_post_id": "old-second", "author_id": "1", "content": "second", "time": base.Add(2 * time.Second), "public": true, "is_retweet": false, "blocked": false, "conversation_id": "old-root", "in_reply_to_post_id": "old-root"},
+		bson.M{"post_id": "old-nested", "original_post_id": "old-nested", "author_id": "1", "content": "nested", "time": base.Add(3 * time.Second), "public": true, "is_retweet": false, "blocked": false, "conversation_id": "old-root", "in_reply_to_post_id": "old-first"},
+	})
+	assert.Nil(t, err)
+
+	_, err = db.Collection(database.TOMBSTONE_COLLECTION).InsertOne(ctx, bson.M{"post_id": "old-first", "in_reply_to_post_id": "old-root", "conversation_id": "old-root", "time": base.Add(time.Second), "replies": 1})
+	assert.Nil(t, err)
+
+	assert.Nil(t, database.RunMigrations(ctx, client))
+
+	thread, next, err := database.NewAppDatabase(client).GetConversationThread(ctx, "old-root", []string{"1"}, "1", models.NewLimitConfig("", "0", "10"), nil)
+
+	assert.Nil(t, err)
+	assert.Nil(t, next)
+	assert.Equal(t, []string{"old-root", "old-first", "old-nested", "old-second"}, postIDs(thread), "Replies of a tombstone should follow it")
+}
@@ -0,0 +1,177 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"server/src/models"
+	"server/src/service"
+	"server/src/standin"
+)
+
+// flakyUsersServer fails the first failures requests with status and then
+// answers like the stand-in users service.
+func flakyUsersServer(t *testing.T, failures int64, status int, requests *atomic.Int64) *httptest.Server {
+	users := standin.NewUsersService(standin.User{ID: "a", Username: "usera"})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		users.Handler().ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestOutboundRetriesFailedGets(t *testing.T) {
+	log.Println("TestOutboundRetriesFailedGets")
+
+	var requests atomic.Int64
+	server := flakyUsersServer(t, 2, http.StatusServiceUnavailable, &requests)
+
+	author, err := newUsersClient(server.URL, testOutboundConfig()).GetAuthorInfo(context.Background(), "a", "token")
+
+	assert.Equal(t, nil, err, "Error should be nil")
+	assert.Equal(t, "usera", author.Username)
+	assert.Equal(t, int64(3), requests.Load())
+}
+
+func TestOutboundGivesUpAfterRetries(t *testing.T) {
+	log.Println("TestOutboundGivesUpAfterRetries")
+
+	var requests atomic.Int64
+	server := flakyUsersServer(t, 10, http.StatusBadGateway, &requests)
+
+	_, err := newUsersClient(server.URL, testOutboundConfig()).GetAuthorInfo(context.Background(), "a", "token")
+
+	statusErr := &service.StatusError{}
+	assert.True(t, errors.As(err, &statusErr), "Error should carry the status")
+	assert.Equal(t, http.StatusBadGateway, statusErr.Status)
+	assert.Equal(t, int64(3), requests.Load())
+}
+
+func TestOutboundDoesNotRetryClientErrors(t *testing.T) {
+	log.Println("TestOutboundDoesNotRetryClientErrors")
+
+	var requests atomic.Int64
+	server := flakyUsersServer(t, 0, http.StatusOK, &requests)
+
+	_, err := newUsersClient(server.URL, testOutboundConfig()).GetAuthorInfo(context.Background(), "missing", "token")
+
+	statusErr := &service.StatusError{}
+	assert.True(t, errors.As(err, &statusErr), "A 404 should not be decoded as a user")
+	assert.Equal(t, http.StatusNotFound, statusErr.Status)
+	assert.Equal(t, int64(1), requests.Load())
+}
+
+func TestOutboundDoesNotRetryPosts(t *testing.T) {
+	log.Println("TestOutboundDoesNotRetryPosts")
+
+	var requests atomic.Int64
+	server := flakyUsersServer(t, 10, http.StatusServiceUnavailable, &requests)
+
+	notifications := service.NewHTTPNotificationClient(strings.TrimPrefix(server.URL, "http://"), testOutboundConfig())
+
+	err := notifications.SendMention(context.Background(), models.MentionNotificationRequest{UserId: "a"}, "token")
+
+	assert.NotEqual(t, nil, err, "Error should not be nil")
+	assert.Equal(t, int64(1), requests.Load())
+}
+
+func TestOutboundTimesOutEachAttempt(t *testing.T) {
+	log.Println("TestOutboundTimesOutEachAttempt")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	config := testOutboundConfig()
+	config.Timeout = 20 * time.Millisecond
+
+	start := time.Now()
+	_, err := newUsersClient(server.URL, config).GetAuthorInfo(context.Background(), "a", "token")
+
+	assert.NotEqual(t, nil, err, "Error should not be nil")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	log.Println("TestCircuitBreakerOpensAndRecovers")
+
+	var requests atomic.Int64
+	server := flakyUsersServer(t, 2, http.StatusInternalServerError, &requests)
+
+	config := testOutboundConfig()
+	config.Retries = 0
+	config.BreakerFailures = 2
+	config.BreakerCooldown = 50 * time.Millisecond
+
+	client := newUsersClient(server.URL, config)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetAuthorInfo(context.Background(), "a", "token")
+		assert.NotEqual(t, nil, err, "Error should not be nil")
+	}
+
+	_, err := client.GetAuthorInfo(context.Background(), "a", "token")
+
+	assert.True(t, errors.Is(err, service.ErrCircuitOpen), "Breaker should be open")
+	assert.Equal(t, int64(2), requests.Load(), "An open breaker should not call the users service")
+
+	time.Sleep(100 * time.Millisecond)
+
+	author, err := client.GetAuthorInfo(context.Background(), "a", "token")
+
+	assert.Equal(t, nil, err, "Error should be nil")
+	assert.Equal(t, "usera", author.Username)
+}
+
+func TestDegradedAuthorsWhileBreakerOpen(t *testing.T) {
+	log.Println("TestDegradedAuthorsWhileBreakerOpen")
+
+	var requests atomic.Int64
+	server := flakyUsersServer(t, 1, http.StatusInternalServerError, &requests)
+
+	config := testOutboundConfig()
+	config.Retries = 0
+	config.BreakerFailures = 1
+
+	db := connectToDatabase()
+	r := createRouterWithUsersClient(db, newUsersClient(server.URL, config))
+
+	seedPosts(t, db, []string{"a"})
+
+	token := adminToken(t)
+
+	failed := httptest.NewRecorder()
+	r.ServeHTTP(failed, getAllPostsRequest(token))
+
+	assert.Equal(t, http.StatusInternalServerError, failed.Code)
+
+	result := getAllPosts(t, r)
+
+	assert.Equal(t, 1, len(result.Data))
+	assert.Equal(t, models.AuthorInfo{Author_ID: "a", Degraded: true}, result.Data[0].Author_Info)
+	assert.Equal(t, int64(1), requests.Load())
+
+	feed := httptest.NewRecorder()
+	r.ServeHTTP(feed, getFeedRequest(token, FEED_TYPE_F))
+
+	assert.Equal(t, http.StatusServiceUnavailable, feed.Code)
+}
@@ -0,0 +1,276 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/auth"
+	"server/src/database"
+	"server/src/models"
+	"server/src/router"
+	"server/src/service"
+)
+
+// testOutboxConfig retries failed deliveries on the next pass.
+func testOutboxConfig() service.OutboxConfig {
+	return service.OutboxConfig{
+		PollInterval: 10 * time.Millisecond,
+		BatchSize:    50,
+		Lease:        time.Minute,
+		MaxAttempts:  3,
+	}
+}
+
+func dispatchOutbox(t *testing.T, dispatcher *service.OutboxDispatcher) int {
+	delivered, err := dispatcher.DispatchOnce(context.Background())
+	assert.Equal(t, nil, err, "Error should be nil")
+	return delivered
+}
+
+func getOutbox(t *testing.T, r *gin.Engine, admin bool, status string) (int, models.OutboxSummary) {
+	token, err := auth.GenerateToken("1", "username", admin)
+	assert.Equal(t, nil, err, "Error should be nil")
+
+	req, _ := http.NewRequest("GET", "/twitsnap/outbox?status="+status, nil)
+	addAuthorization(req, token)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	summary := models.OutboxSummary{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &summary)
+
+	return recorder.Code, summary
+}
+
+func outboxMessagesOfKind(summary models.OutboxSummary, kind string) []models.OutboxMessage {
+	messages := []models.OutboxMessage{}
+	for _, message := range summary.Messages {
+		if message.Kind == kind {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+func newOutboxRouter(db database.Database, notifications *service.FakeNotificationClient) *gin.Engine {
+	return router.CreateRouter(db, service.NewFakeUsersClient(), notifications)
+}
+
+func TestMentionNotificationsSent(t *testing.T) {
+	log.Println("TestMentionNotificationsSent")
+
+	db := connectToDatabase()
+	notifications := service.NewFakeNotificationClient()
+	r := newOutboxRouter(db, notifications)
+	dispatcher := service.NewOutboxDispatcher(db, notifications, service.NewInProcessPublisher(), testOutboxConfig())
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "hi", []string{}, []string{service.TEST_USER_TWO, service.TEST_USER_THREE}, true, "", r, t)
+
+	assert.Equal(t, 0, len(notifications.Sent()), "Mentions should wait for the dispatcher")
+	assert.Equal(t, 3, dispatchOutbox(t, dispatcher), "Both mentions and the NEW_CONTENT event should be delivered")
+
+	assert.ElementsMatch(t, []models.MentionNotificationRequest{
+		{UserId: service.TEST_USER_TWO, TaggerId: service.TEST_USER_ONE, PostId: post.Original_Post_ID},
+		{UserId: service.TEST_USER_THREE, TaggerId: service.TEST_USER_ONE, PostId: post.Original_Post_ID},
+	}, notifications.Sent())
+
+	assert.Equal(t, 0, dispatchOutbox(t, dispatcher), "Delivered mentions should leave the outbox")
+}
+
+func TestNotificationServiceFailure(t *testing.T) {
+	log.Println("TestNotificationServiceFailure")
+
+	db := connectToDatabase()
+	notifications := service.NewFakeNotificationClient()
+	notifications.Fail(errors.New("notifications down"))
+	r := newOutboxRouter(db, notifications)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "hi", []string{}, []string{service.TEST_USER_TWO}, true, "", r, t)
+
+	code, summary := getOutbox(t, r, true, models.OUTBOX_PENDING)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, summary.Pending, "The mention and the NEW_CONTENT event should wait in the outbox")
+
+	mentions := outboxMessagesOfKind(summary, models.MENTION_NOTIFICATION)
+
+	assert.Equal(t, 1, len(mentions))
+	assert.Equal(t, post.Original_Post_ID, mentions[0].Mention.PostId)
+}
+
+func TestOutboxRetriesFailedDeliveries(t *testing.T) {
+	log.Println("TestOutboxRetriesFailedDeliveries")
+
+	db := connectToDatabase()
+	notifications := service.NewFakeNotificationClient()
+	notifications.Fail(errors.New("notifications down"))
+	r := newOutboxRouter(db, notifications)
+	dispatcher := service.NewOutboxDispatcher(db, notifications, service.NewInProcessPublisher(), testOutboxConfig())
+
+	makeAndAssertPost(service.TEST_USER_ONE, "hi", []string{}, []string{service.TEST_USER_TWO}, true, "", r, t)
+
+	assert.Equal(t, 1, dispatchOutbox(t, dispatcher), "Only the NEW_CONTENT event should be delivered")
+
+	_, summary := getOutbox(t, r, true, models.OUTBOX_PENDING)
+
+	assert.Equal(t, 1, summary.Messages[0].Attempts)
+	assert.Equal(t, "notifications down", summary.Messages[0].Last_Error)
+
+	notifications.Fail(nil)
+
+	assert.Equal(t, 1, dispatchOutbox(t, dispatcher))
+	assert.Equal(t, 1, len(notifications.Sent()))
+
+	_, summary = getOutbox(t, r, true, models.OUTBOX_PENDING)
+
+	assert.Equal(t, 0, summary.Pending)
+}
+
+func TestOutboxBacksOffAfterFailure(t *testing.T) {
+	log.Println("TestOutboxBacksOffAfterFailure")
+
+	db := connectToDatabase()
+	notifications := service.NewFakeNotificationClient()
+	notifications.Fail(errors.New("notifications down"))
+	r := newOutboxRouter(db, notifications)
+
+	config := testOutboxConfig()
+	config.BackoffBase = time.Hour
+	config.BackoffMax = time.Hour
+	dispatcher := service.NewOutboxDispatcher(db, notifications, service.NewInProcessPublisher(), config)
+
+	makeAndAssertPost(service.TEST_USER_ONE, "hi", []string{}, []string{service.TEST_USER_TWO}, true, "", r, t)
+
+	dispatchOutbox(t, dispatcher)
+	dispatchOutbox(t, dispatcher)
+
+	_, summary := getOutbox(t, r, true, models.OUTBOX_PENDING)
+
+	assert.Equal(t, 1, summary.Messages[0].Attempts, "The message should wait for its backoff")
+	assert.True(t, summary.Messages[0].Next_Attempt.After(time.Now()))
+}
+
+func TestOutboxDeadLetters(t *testing.T) {
+	log.Println("TestOutboxDeadLetters")
+
+	db := connectToDatabase()
+	notifications := service.NewFakeNotificationClient()
+	notifications.Fail(errors.New("notifications down"))
+	r := newOutboxRouter(db, notifications)
+	dispatcher := service.NewOutboxDispatcher(db, notifications, service.NewInProcessPublisher(), testOutboxConfig())
+
+	makeAndAssertPost(service.TEST_USER_ONE, "hi", []string{}, []string{service.TEST_USER_TWO}, true, "", r, t)
+
+	for i := 0; i < testOutboxConfig().MaxAttempts; i++ {
+		dispatchOutbox(t, dispatcher)
+	}
+
+	code, summary := getOutbox(t, r, true, models.OUTBOX_FAILED)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 0, summary.Pending)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, testOutboxConfig().MaxAttempts, summary.Messages[0].Attempts)
+
+	notifications.Fail(nil)
+
+	assert.Equal(t, 0, dispatchOutbox(t, dispatcher), "Failed messages should not be retried")
+	assert.Equal(t, 0, len(notifications.Sent()))
+}
+
+func TestOutboxDispatcherRuns(t *testing.T) {
+	log.Println("TestOutboxDispatcherRuns")
+
+	db := connectToDatabase()
+	notifications := service.NewFakeNotificationClient()
+	r := newOutboxRouter(db, notifications)
+	dispatcher := service.NewOutboxDispatcher(db, notifications, service.NewInProcessPublisher(), testOutboxConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(done)
+	}()
+
+	makeAndAssertPost(service.TEST_USER_ONE, "hi", []string{}, []string{service.TEST_USER_TWO}, true, "", r, t)
+
+	assert.Eventually(t, func() bool { return len(notifications.Sent()) == 1 }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+// slowNotifications takes a while to deliver each mention.
+type slowNotifications struct {
+	*service.FakeNotificationClient
+	delay time.Duration
+}
+
+func (s slowNotifications) SendMention(ctx context.Context, notification models.MentionNotificationRequest, token string) error {
+	time.Sleep(s.delay)
+	return s.FakeNotificationClient.SendMention(ctx, notification, token)
+}
+
+func TestOutboxLeaseCoversOneDelivery(t *testing.T) {
+	log.Println("TestOutboxLeaseCoversOneDelivery")
+
+	db := connectToDatabase()
+	notifications := service.NewFakeNotificationClient()
+	r := newOutboxRouter(db, notifications)
+
+	// A batch takes longer than the lease, a single delivery does not.
+	slow := slowNotifications{notifications, 50 * time.Millisecond}
+	config := testOutboxConfig()
+	config.Lease = 150 * time.Millisecond
+
+	for range 2 {
+		makeAndAssertPost(service.TEST_USER_ONE, "hi", []string{}, []string{service.TEST_USER_TWO, service.TEST_USER_THREE}, true, "", r, t)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{}, 2)
+	for range 2 {
+		dispatcher := service.NewOutboxDispatcher(db, slow, service.NewInProcessPublisher(), config)
+		go func() {
+			dispatcher.Run(ctx)
+			done <- struct{}{}
+		}()
+	}
+
+	assert.Eventually(t, func() bool { return len(notifications.Sent()) >= 4 }, time.Second, 10*time.Millisecond)
+	time.Sleep(2 * config.Lease)
+
+	cancel()
+	<-done
+	<-done
+
+	assert.Equal(t, 4, len(notifications.Sent()), "No mention should be sent twice")
+
+	pending, err := db.CountOutboxMessages(context.Background(), models.OUTBOX_PENDING)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, pending)
+}
+
+func TestOutboxAdminOnly(t *testing.T) {
+	log.Println("TestOutboxAdminOnly")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	code, _ := getOutbox(t, r, false, models.OUTBOX_PENDING)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = getOutbox(t, r, true, "lost")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
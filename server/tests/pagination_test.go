@@ -0,0 +1,136 @@
+package test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"server/src/auth"
+	"server/src/models"
+	"server/src/service"
+)
+
+func TestGetAllWithCursor(t *testing.T) {
+	log.Println("TestGetAllWithCursor")
+
+	db := connectToDatabase()
+
+	r := createRouter(db)
+
+	expectedPosts := []models.FrontPost{}
+
+	for _, content := range []string{"one", "two", "three", "four", "five"} {
+		post := makeAndAssertPost(service.TEST_USER_ONE, content, []string{}, []string{}, true, "", r, t)
+		expectedPosts = append([]models.FrontPost{post}, expectedPosts...)
+	}
+
+	token, err := auth.GenerateToken("1", "username", true)
+
+	assert.Equal(t, err, nil, "Error should be nil")
+
+	time.Sleep(1 * time.Second)
+	time := time.Now().Format(time.RFC3339)
+
+	query := "time=" + time + "&skip=0&limit=2"
+	posts := []models.FrontPost{}
+
+	for page := 0; page < 3; page++ {
+		getAll, _ := http.NewRequest("GET", "/twitsnap/all?"+query, nil)
+		addAuthorization(getAll, token)
+
+		feedRecorder := httptest.NewRecorder()
+		r.ServeHTTP(feedRecorder, getAll)
+
+		result := models.ReturnPaginatedPosts{}
+		err_2 := json.Unmarshal(feedRecorder.Body.Bytes(), &result)
+
+		assert.Equal(t, err_2, nil, "Error should be nil")
+		assert.Equal(t, http.StatusOK, feedRecorder.Code, "Status should be 200")
+		assert.Equal(t, 2, result.Pagination.Limit)
+
+		posts = append(posts, result.Data...)
+
+		if page == 2 {
+			assert.Equal(t, "", result.Pagination.Next_Cursor)
+			break
+		}
+
+		assert.NotEqual(t, "", result.Pagination.Next_Cursor)
+
+		if page > 0 {
+			assert.Equal(t, 0, result.Pagination.Next_Offset, "Cursor pages should not return an offset")
+		}
+
+		query = "limit=2&cursor=" + url.QueryEscape(result.Pagination.Next_Cursor)
+	}
+
+	compareOrderAsExpected(expectedPosts, posts, t)
+}
+
+func TestInvalidCursor(t *testing.T) {
+	log.Println("TestInvalidCursor")
+
+	db := connectToDatabase()
+
+	r := createRouter(db)
+
+	token, err := auth.GenerateToken(service.TEST_USER_ONE, service.TEST_USER_ONE_USERNAME, false)
+
+	assert.Equal(t, err, nil, "Error should be nil")
+
+	getFeed, _ := http.NewRequest("GET", "/twitsnap/feed?limit=2&feed_type="+FEED_TYPE_F+"&cursor=not-a-cursor", nil)
+	addAuthorization(getFeed, token)
+
+	feedRecorder := httptest.NewRecorder()
+	r.ServeHTTP(feedRecorder, getFeed)
+
+	assert.Equal(t, http.StatusBadRequest, feedRecorder.Code, "Status should be 400")
+}
+
+func TestBookmarksWithCursor(t *testing.T) {
+	log.Println("TestBookmarksWithCursor")
+
+	db := connectToDatabase()
+
+	r := createRouter(db)
+
+	expectedPosts := []models.FrontPost{}
+
+	for _, content := range []string{"one", "two", "three"} {
+		post := makeAndAssertPost(service.TEST_USER_ONE, content, []string{}, []string{}, true, "", r, t)
+		assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/bookmark/"+post.Post_ID, nil).Code)
+		expectedPosts = append([]models.FrontPost{post}, expectedPosts...)
+	}
+
+	query := "time=" + url.QueryEscape(time.Now().Add(time.Minute).Format(time.RFC3339)) + "&limit=2"
+	posts := []models.FrontPost{}
+
+	for page := 0; page < 2; page++ {
+		recorder := serveAs(t, r, service.TEST_USER_TWO, false, "GET", "/twitsnap/bookmarks?"+query, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code, "Status should be 200")
+
+		result := models.ReturnPaginatedPosts{}
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+
+		posts = append(posts, result.Data...)
+
+		if page == 1 {
+			assert.Equal(t, 1, len(result.Data))
+			assert.Equal(t, "", result.Pagination.Next_Cursor)
+			break
+		}
+
+		assert.Equal(t, 2, len(result.Data), "A page should end at the limit")
+		assert.NotEqual(t, "", result.Pagination.Next_Cursor)
+
+		query = "limit=2&cursor=" + url.QueryEscape(result.Pagination.Next_Cursor)
+	}
+
+	compareOrderAsExpected(expectedPosts, posts, t)
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/models"
+	"server/src/service"
+)
+
+func getPlatformMetrics(t *testing.T, r *gin.Engine, admin bool, query url.Values) (int, models.PlatformMetrics) {
+	recorder := serveAs(t, r, service.TEST_USER_ONE, admin, "GET", "/twitsnap/platform-metrics?"+query.Encode(), nil)
+
+	metrics := models.PlatformMetrics{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &metrics)
+
+	return recorder.Code, metrics
+}
+
+func platformMetricsQuery(from time.Time, to time.Time, interval string) url.Values {
+	query := url.Values{}
+	query.Set("time", from.Format(time.RFC3339))
+	query.Set("end_time", to.Format(time.RFC3339))
+	query.Set("interval", interval)
+	return query
+}
+
+func TestPlatformMetrics(t *testing.T) {
+	log.Println("TestPlatformMetrics")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	now := time.Now().UTC()
+	hour := now.Truncate(time.Hour)
+
+	mentioning := makeAndAssertPost(service.TEST_USER_ONE, "hi", []string{}, []string{service.TEST_USER_TWO}, true, "", r, t)
+	makeAndAssertPost(service.TEST_USER_TWO, "hidden", []string{}, []string{}, false, "", r, t)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/like/"+mentioning.Post_ID, nil).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/bookmark/"+mentioning.Post_ID, nil).Code)
+	assert.Equal(t, http.StatusCreated, serveAs(t, r, service.TEST_USER_THREE, false, "POST", "/twitsnap/retweet/"+mentioning.Post_ID, nil).Code)
+
+	code, metrics := getPlatformMetrics(t, r, true, platformMetricsQuery(hour.Add(-time.Hour), hour.Add(time.Hour), models.HOUR_INTERVAL))
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.ALL_VISIBILITY, metrics.Visibility)
+	assert.Equal(t, 2, len(metrics.Series), "Empty hours should be filled in")
+	assert.Equal(t, 0, metrics.Series[0].Posts)
+
+	current := metrics.Series[1]
+
+	assert.Equal(t, 2, current.Posts)
+	assert.Equal(t, 1, current.Retweets)
+	assert.Equal(t, 1, current.Likes)
+	assert.Equal(t, 1, current.Bookmarks)
+	assert.Equal(t, 3, current.Active_Authors)
+	assert.Equal(t, 1, current.Mention_Posts)
+	assert.InDelta(t, 0.5, current.Mention_Share, 1e-9)
+	assert.InDelta(t, 0, current.Media_Share, 1e-9)
+
+	query := platformMetricsQuery(hour.Add(-time.Hour), hour.Add(time.Hour), models.HOUR_INTERVAL)
+	query.Set("visibility", models.PUBLIC_VISIBILITY)
+
+	code, metrics = getPlatformMetrics(t, r, true, query)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, metrics.Series[1].Posts, "Private posts should be left out")
+	assert.InDelta(t, 1, metrics.Series[1].Mention_Share, 1e-9)
+}
+
+func TestPlatformMetricsAdminOnly(t *testing.T) {
+	log.Println("TestPlatformMetricsAdminOnly")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	now := time.Now().UTC()
+	query := platformMetricsQuery(now.Add(-time.Hour), now, models.DAY_INTERVAL)
+
+	code, _ := getPlatformMetrics(t, r, false, query)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	query.Set("visibility", "friends")
+
+	code, _ = getPlatformMetrics(t, r, true, query)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
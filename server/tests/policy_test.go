@@ -0,0 +1,257 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	postErrors "server/src/all_errors"
+	"server/src/database"
+	"server/src/models"
+	"server/src/service"
+)
+
+func assertDenied(t *testing.T, recorder *httptest.ResponseRecorder, action string) {
+	assert.Equal(t, http.StatusForbidden, recorder.Code, "%s should be denied", action)
+
+	denial := postErrors.TwitSnapError{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &denial))
+	assert.Equal(t, postErrors.ActionDenied(action), denial)
+}
+
+func TestAuthorizeRules(t *testing.T) {
+	log.Println("TestAuthorizeRules")
+
+	author := service.Session{UserID: service.TEST_USER_ONE}
+	other := service.Session{UserID: service.TEST_USER_TWO}
+	admin := service.Session{UserID: service.TEST_USER_THREE, Admin: true}
+
+	rules := []struct {
+		action  string
+		allowed []service.Session
+		denied  []service.Session
+	}{
+		{service.EDIT_ACTION, []service.Session{author}, []service.Session{other, admin}},
+		{service.DELETE_ACTION, []service.Session{author, admin}, []service.Session{other}},
+		{service.VIEW_BOOKMARKS_ACTION, []service.Session{author, admin}, []service.Session{other}},
+		{service.BLOCK_ACTION, []service.Session{admin}, []service.Session{author, other}},
+		{service.REVERT_ACTION, []service.Session{admin}, []service.Session{author, other}},
+		{service.ADMIN_LISTING_ACTION, []service.Session{admin}, []service.Session{author, other}},
+		{service.INVALIDATE_ACTION, []service.Session{admin}, []service.Session{author, other}},
+	}
+
+	for _, rule := range rules {
+		for _, session := range rule.allowed {
+			assert.Nil(t, service.Authorize(session, rule.action, service.TEST_USER_ONE), "%+v should be allowed to %s", session, rule.action)
+		}
+		for _, session := range rule.denied {
+			assert.Equal(t, postErrors.ActionDenied(rule.action), service.Authorize(session, rule.action, service.TEST_USER_ONE), "%+v should not be allowed to %s", session, rule.action)
+		}
+	}
+
+	assert.NotNil(t, service.Authorize(admin, "unknown", ""), "Unknown actions should be denied")
+
+	for _, action := range []string{service.EDIT_ACTION, service.DELETE_ACTION, service.VIEW_BOOKMARKS_ACTION} {
+		assert.Equal(t, postErrors.ActionDenied(action), service.Authorize(service.Session{}, action, ""), "A session without a user should not own anything")
+	}
+}
+
+func TestRetweetsAreNotEditedOrDeletedAsPosts(t *testing.T) {
+	log.Println("TestRetweetsAreNotEditedOrDeletedAsPosts")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "words of the author", []string{}, []string{}, true, "", r, t)
+	assert.Equal(t, http.StatusCreated, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/retweet/"+post.Post_ID, nil).Code)
+	retweet := getRetweetsOf(t, r, service.TEST_USER_TWO).Data[0]
+
+	content := "words the author never wrote"
+	edit := models.EditPostExpectedFormat{Content: &content}
+
+	assertDenied(t, serveAs(t, r, service.TEST_USER_TWO, false, "PUT", "/twitsnap/edit/"+retweet.Post_ID, edit), service.EDIT_ACTION)
+	assertDenied(t, serveAs(t, r, service.TEST_USER_ONE, false, "PUT", "/twitsnap/edit/"+retweet.Post_ID, edit), service.EDIT_ACTION)
+	assertDenied(t, serveAs(t, r, service.TEST_USER_TWO, false, "DELETE", "/twitsnap/"+retweet.Post_ID, nil), service.DELETE_ACTION)
+	assertDenied(t, serveAs(t, r, service.TEST_USER_TWO, true, "DELETE", "/twitsnap/"+retweet.Post_ID, nil), service.DELETE_ACTION)
+
+	assert.Equal(t, "words of the author", getPostAs(t, r, service.TEST_USER_TWO, retweet.Post_ID).Content)
+
+	original := getPostAs(t, r, service.TEST_USER_TWO, post.Post_ID)
+
+	assert.Equal(t, 1, original.Retweets)
+	assert.True(t, original.User_Retweet)
+}
+
+// failingPostsDatabase cannot read posts, as if the database were down.
+type failingPostsDatabase struct {
+	database.Database
+}
+
+func (f failingPostsDatabase) GetPost(ctx context.Context, postID string, askerID string) (models.FrontPost, error) {
+	return models.FrontPost{}, errors.New("database unreachable")
+}
+
+func TestPolicyKeepsDatabaseErrors(t *testing.T) {
+	log.Println("TestPolicyKeepsDatabaseErrors")
+
+	r := createRouter(failingPostsDatabase{connectToDatabase()})
+
+	content := "edit"
+
+	assert.Equal(t, http.StatusInternalServerError, serveAs(t, r, service.TEST_USER_ONE, false, "DELETE", "/twitsnap/some-post", nil).Code, "A database failure is not a missing post")
+	assert.Equal(t, http.StatusInternalServerError, serveAs(t, r, service.TEST_USER_ONE, false, "PUT", "/twitsnap/edit/some-post", models.EditPostExpectedFormat{Content: &content}).Code)
+}
+
+// failingWritesDatabase reads posts but fails every delete and block, as if
+// the writes were aborted.
+type failingWritesDatabase struct {
+	database.Database
+}
+
+var errWriteAborted = errors.New("write aborted")
+
+func (f failingWritesDatabase) DeletePost(ctx context.Context, postID string) error {
+	return errWriteAborted
+}
+
+func (f failingWritesDatabase) BlockPost(ctx context.Context, postID string) error {
+	return errWriteAborted
+}
+
+func (f failingWritesDatabase) UnBlockPost(ctx context.Context, postID string) error {
+	return errWriteAborted
+}
+
+func (f failingWritesDatabase) DeleteRetweet(ctx context.Context, postID string, userID string) error {
+	return errWriteAborted
+}
+
+func TestFailedWritesAreNotMissingPosts(t *testing.T) {
+	log.Println("TestFailedWritesAreNotMissingPosts")
+
+	db := connectToDatabase()
+	post := makeAndAssertPost(service.TEST_USER_ONE, "still here", []string{}, []string{}, true, "", createRouter(db), t)
+	r := createRouter(failingWritesDatabase{db})
+
+	for _, request := range []struct {
+		method string
+		url    string
+	}{
+		{"DELETE", "/twitsnap/" + post.Post_ID},
+		{"POST", "/twitsnap/block/" + post.Post_ID},
+		{"DELETE", "/twitsnap/block/" + post.Post_ID},
+		{"DELETE", "/twitsnap/retweet/" + post.Post_ID},
+	} {
+		assert.Equal(t, http.StatusInternalServerError, serveAs(t, r, service.TEST_USER_ONE, true, request.method, request.url, nil).Code, "%s %s", request.method, request.url)
+	}
+
+	r = createRouter(db)
+
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_ONE, true, "POST", "/twitsnap/block/missing", nil).Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_ONE, true, "DELETE", "/twitsnap/block/missing", nil).Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_ONE, true, "DELETE", "/twitsnap/missing", nil).Code)
+}
+
+func TestOnlyTheAuthorEditsAPost(t *testing.T) {
+	log.Println("TestOnlyTheAuthorEditsAPost")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "mine", []string{}, []string{}, true, "", r, t)
+	content := "not yours"
+	edit := models.EditPostExpectedFormat{Content: &content}
+
+	assertDenied(t, serveAs(t, r, service.TEST_USER_TWO, false, "PUT", "/twitsnap/edit/"+post.Post_ID, edit), service.EDIT_ACTION)
+	assertDenied(t, serveAs(t, r, service.TEST_USER_TWO, true, "PUT", "/twitsnap/edit/"+post.Post_ID, edit), service.EDIT_ACTION)
+	assert.Equal(t, "mine", getPostAs(t, r, service.TEST_USER_ONE, post.Post_ID).Content)
+
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_ONE, false, "PUT", "/twitsnap/edit/missing", edit).Code)
+	assert.Equal(t, content, editAs(t, r, service.TEST_USER_ONE, post.Post_ID, edit).Content)
+}
+
+func TestAuthorOrAdminDeletesAPost(t *testing.T) {
+	log.Println("TestAuthorOrAdminDeletesAPost")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	byAuthor := makeAndAssertPost(service.TEST_USER_ONE, "the author deletes this", []string{}, []string{}, true, "", r, t)
+	byAdmin := makeAndAssertPost(service.TEST_USER_ONE, "an admin deletes this", []string{}, []string{}, true, "", r, t)
+
+	assertDenied(t, serveAs(t, r, service.TEST_USER_TWO, false, "DELETE", "/twitsnap/"+byAuthor.Post_ID, nil), service.DELETE_ACTION)
+	assert.Equal(t, "the author deletes this", getPostAs(t, r, service.TEST_USER_ONE, byAuthor.Post_ID).Content)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, false, "DELETE", "/twitsnap/"+byAuthor.Post_ID, nil).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, true, "DELETE", "/twitsnap/"+byAdmin.Post_ID, nil).Code)
+
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/"+byAdmin.Post_ID, nil).Code)
+}
+
+func TestOnlyAdminsBlockPosts(t *testing.T) {
+	log.Println("TestOnlyAdminsBlockPosts")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "to be blocked", []string{}, []string{}, true, "", r, t)
+
+	assertDenied(t, serveAs(t, r, service.TEST_USER_ONE, false, "POST", "/twitsnap/block/"+post.Post_ID, nil), service.BLOCK_ACTION)
+	assert.Equal(t, http.StatusOK, serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/"+post.Post_ID, nil).Code)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, true, "POST", "/twitsnap/block/"+post.Post_ID, nil).Code)
+
+	assertDenied(t, serveAs(t, r, service.TEST_USER_ONE, false, "DELETE", "/twitsnap/block/"+post.Post_ID, nil), service.BLOCK_ACTION)
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/"+post.Post_ID, nil).Code)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, true, "DELETE", "/twitsnap/block/"+post.Post_ID, nil).Code)
+	assert.Equal(t, http.StatusOK, serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/"+post.Post_ID, nil).Code)
+}
+
+func TestBookmarksAreSeenByTheirOwnerOrAnAdmin(t *testing.T) {
+	log.Println("TestBookmarksAreSeenByTheirOwnerOrAnAdmin")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "worth keeping", []string{}, []string{}, true, "", r, t)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/bookmark/"+post.Post_ID, nil).Code)
+
+	bookmarksOf := func(viewer string, admin bool, wanted string) *httptest.ResponseRecorder {
+		url := "/twitsnap/bookmarks?time=" + time.Now().Add(time.Minute).Format(time.RFC3339) + "&limit=5&wanted_user_id=" + wanted
+		return serveAs(t, r, viewer, admin, "GET", url, nil)
+	}
+
+	assertDenied(t, bookmarksOf(service.TEST_USER_ONE, false, service.TEST_USER_TWO), service.VIEW_BOOKMARKS_ACTION)
+
+	for _, recorder := range []*httptest.ResponseRecorder{
+		bookmarksOf(service.TEST_USER_TWO, false, service.TEST_USER_TWO),
+		bookmarksOf(service.TEST_USER_TWO, false, ""),
+		bookmarksOf(service.TEST_USER_THREE, true, service.TEST_USER_TWO),
+	} {
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		bookmarks := models.ReturnPaginatedPosts{}
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &bookmarks))
+		assert.Equal(t, []string{post.Post_ID}, postIDs(bookmarks.Data))
+	}
+}
+
+func TestAdminListingsNeedAnAdmin(t *testing.T) {
+	log.Println("TestAdminListingsNeedAnAdmin")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	for _, url := range []string{"/twitsnap/all", "/twitsnap/platform-metrics", "/twitsnap/cache-metrics", "/twitsnap/outbox"} {
+		assertDenied(t, serveAs(t, r, service.TEST_USER_ONE, false, "GET", url, nil), service.ADMIN_LISTING_ACTION)
+		assert.NotEqual(t, http.StatusForbidden, serveAs(t, r, service.TEST_USER_ONE, true, "GET", url, nil).Code, url)
+	}
+}
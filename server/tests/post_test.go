@@ -12,7 +12,6 @@ import (
 	postErrors "server/src/all_errors"
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 
 	validator "github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
@@ -24,7 +23,7 @@ func TestNewPost(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1234"
 	postBody := PostBody{Content: "content #tag1 #tag2", Tags: []string{"tag1", "tag2"}, Mentions: []string{}, Public: true}
@@ -56,7 +55,7 @@ func TestNewPostWithMissInfo(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1234"
 	postBody := PostBody{Content: "", Tags: []string{"tag1", "tag2"}, Public: true, Mentions: []string{}}
@@ -97,7 +96,7 @@ func TestNewPostTooLong(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1234"
 	postBody := PostBody{Content: "Psyduck, the perpetually confused Pokémon with its iconic yellow body and headache-induced psychic powers, waddles through life clutching its head, unintentionally unleashing bursts of immense energy, making it both endearingly clumsy and surprisingly powerful, a true enigma in the Pokémon world.", Tags: []string{}, Mentions: []string{}, Public: true}
@@ -130,7 +129,7 @@ func TestNewPostUnexpectedFormat(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1234"
 	post := struct {
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/models"
+	"server/src/router"
+	"server/src/service"
+)
+
+func quoteAs(t *testing.T, r *gin.Engine, userID string, postID string, body PostBody) models.FrontPost {
+	recorder := serveAs(t, r, userID, false, "POST", "/twitsnap/quote/"+postID, body)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	quote := models.FrontPost{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &quote))
+
+	return quote
+}
+
+func getPostAs(t *testing.T, r *gin.Engine, userID string, postID string) models.FrontPost {
+	recorder := serveAs(t, r, userID, false, "GET", "/twitsnap/"+postID, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	post := models.FrontPost{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &post))
+
+	return post
+}
+
+func TestQuotePost(t *testing.T) {
+	log.Println("TestQuotePost")
+
+	db := connectToDatabase()
+	users := service.NewFakeUsersClient()
+	users.SetFollowing(service.TEST_USER_THREE, []string{})
+	r := router.CreateRouter(db, users, service.NewFakeNotificationClient())
+
+	original := makeAndAssertPost(service.TEST_USER_ONE, "original #go", []string{"go"}, []string{}, true, "", r, t)
+	quote := quoteAs(t, r, service.TEST_USER_TWO, original.Post_ID, PostBody{Content: "so true #rust", Public: true, Mentions: []string{service.TEST_USER_THREE}})
+
+	assert.Equal(t, "so true #rust", quote.Content)
+	assert.Equal(t, []string{"rust"}, quote.Tags, "A quote should have its own tags")
+	assert.Equal(t, []string{service.TEST_USER_THREE}, quote.Mentions)
+	assert.False(t, quote.Is_Retweet)
+	assert.Equal(t, original.Post_ID, quote.Quoted_Post_ID)
+	assert.Equal(t, "original #go", quote.Quoted_Post.Content)
+	assert.Equal(t, service.TEST_USER_ONE_USERNAME, quote.Quoted_Post.Author_Info.Username)
+	assert.Equal(t, service.TEST_USER_TWO_USERNAME, quote.Author_Info.Username)
+
+	assert.Equal(t, http.StatusCreated, serveAs(t, r, service.TEST_USER_THREE, false, "POST", "/twitsnap/retweet/"+original.Post_ID, nil).Code)
+
+	fetched := getPostAs(t, r, service.TEST_USER_ONE, original.Post_ID)
+
+	assert.Equal(t, 1, fetched.Quotes)
+	assert.Equal(t, 1, fetched.Retweets, "Quotes should count apart from retweets")
+
+	retweet := getRetweetsOf(t, r, service.TEST_USER_THREE).Data[0]
+	quoteOfRetweet := quoteAs(t, r, service.TEST_USER_ONE, retweet.Post_ID, PostBody{Content: "again", Public: true})
+
+	assert.Equal(t, original.Post_ID, quoteOfRetweet.Quoted_Post_ID, "Quoting a retweet should quote its original")
+	assert.Equal(t, 2, getPostAs(t, r, service.TEST_USER_ONE, original.Post_ID).Quotes)
+
+	private := makeAndAssertPost(service.TEST_USER_ONE, "for my followers", []string{}, []string{}, false, "", r, t)
+
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_THREE, false, "POST", "/twitsnap/quote/"+private.Post_ID, PostBody{Content: "hi"}).Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/quote/missing", PostBody{Content: "hi"}).Code)
+	assert.Equal(t, http.StatusBadRequest, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/quote/"+original.Post_ID, PostBody{}).Code)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "DELETE", "/twitsnap/"+quote.Post_ID, nil).Code)
+	assert.Equal(t, 1, getPostAs(t, r, service.TEST_USER_ONE, original.Post_ID).Quotes)
+}
+
+func TestQuotedPostVisibility(t *testing.T) {
+	log.Println("TestQuotedPostVisibility")
+
+	db := connectToDatabase()
+	users := service.NewFakeUsersClient()
+	users.SetFollowing(service.TEST_USER_THREE, []string{service.TEST_USER_TWO})
+	r := router.CreateRouter(db, users, service.NewFakeNotificationClient())
+
+	private := makeAndAssertPost(service.TEST_USER_ONE, "for my followers", []string{}, []string{}, false, "", r, t)
+	public := makeAndAssertPost(service.TEST_USER_ONE, "for everyone", []string{}, []string{}, true, "", r, t)
+	quotesPrivate := quoteAs(t, r, service.TEST_USER_TWO, private.Post_ID, PostBody{Content: "look at this", Public: true})
+	quotesPublic := quoteAs(t, r, service.TEST_USER_TWO, public.Post_ID, PostBody{Content: "and this", Public: true})
+
+	assert.Equal(t, "for my followers", getPostAs(t, r, service.TEST_USER_ONE, quotesPrivate.Post_ID).Quoted_Post.Content)
+
+	seen := getPostAs(t, r, service.TEST_USER_THREE, quotesPrivate.Post_ID)
+
+	assert.True(t, seen.Quoted_Post.Tombstone, "A private quoted post should be hidden from who does not follow its author")
+	assert.Equal(t, "", seen.Quoted_Post.Content)
+	assert.Equal(t, "", seen.Quoted_Post.Author_Info.Author_ID)
+
+	feed := getFeedAs(t, r, service.TEST_USER_THREE, FEED_TYPE_F)
+
+	assert.Equal(t, []string{quotesPublic.Post_ID, quotesPrivate.Post_ID}, postIDs(feed.Data))
+	assert.False(t, feed.Data[0].Quoted_Post.Tombstone)
+	assert.True(t, feed.Data[1].Quoted_Post.Tombstone, "Feeds should hide private quoted posts too")
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, true, "POST", "/twitsnap/block/"+public.Post_ID, nil).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, false, "DELETE", "/twitsnap/"+private.Post_ID, nil).Code)
+
+	for _, quote := range []models.FrontPost{quotesPublic, quotesPrivate} {
+		fetched := getPostAs(t, r, service.TEST_USER_ONE, quote.Post_ID)
+
+		assert.True(t, fetched.Quoted_Post.Tombstone, "Blocked and deleted quoted posts should be tombstones")
+		assert.Equal(t, quote.Quoted_Post_ID, fetched.Quoted_Post.Post_ID)
+	}
+}
@@ -0,0 +1,246 @@
+package test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/models"
+	"server/src/router"
+	"server/src/service"
+)
+
+func replyAs(t *testing.T, r *gin.Engine, userID string, postID string, content string, public bool) models.FrontPost {
+	recorder := serveAs(t, r, userID, false, "POST", "/twitsnap/reply/"+postID, PostBody{Content: content, Public: public})
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	reply := models.FrontPost{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &reply))
+
+	return reply
+}
+
+func getConversationTree(t *testing.T, r *gin.Engine, userID string, postID string) models.ConversationNode {
+	recorder := serveAs(t, r, userID, false, "GET", "/twitsnap/conversation/"+postID, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	tree := models.ConversationNode{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &tree))
+
+	return tree
+}
+
+func getConversationThread(t *testing.T, r *gin.Engine, userID string, postID string, query string) models.ReturnPaginatedPosts {
+	recorder := serveAs(t, r, userID, false, "GET", "/twitsnap/conversation/"+postID+"?mode=thread&"+query, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	result := models.ReturnPaginatedPosts{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+
+	return result
+}
+
+func getRetweetsOf(t *testing.T, r *gin.Engine, userID string) models.ReturnPaginatedPosts {
+	from := time.Now().Add(time.Minute).Format(time.RFC3339)
+	recorder := serveAs(t, r, userID, false, "GET", "/twitsnap/feed?time="+from+"&skip=0&limit=6&feed_type="+FEED_TYPE_R+"&wanted_user_id="+userID, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	result := models.ReturnPaginatedPosts{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+
+	return result
+}
+
+func replyIDs(node models.ConversationNode) []string {
+	ids := []string{}
+	for _, reply := range node.Replies {
+		ids = append(ids, reply.Post.Post_ID)
+	}
+	return ids
+}
+
+func TestReplyToPost(t *testing.T) {
+	log.Println("TestReplyToPost")
+
+	db := connectToDatabase()
+	users := service.NewFakeUsersClient()
+	users.SetFollowing(service.TEST_USER_THREE, []string{})
+	r := router.CreateRouter(db, users, service.NewFakeNotificationClient())
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "what do you think?", []string{}, []string{}, true, "", r, t)
+	reply := replyAs(t, r, service.TEST_USER_TWO, post.Post_ID, "I agree #yes", true)
+
+	assert.Equal(t, post.Post_ID, reply.In_Reply_To_Post_ID)
+	assert.Equal(t, post.Post_ID, reply.Conversation_ID)
+	assert.Equal(t, []string{"yes"}, reply.Tags)
+	assert.Equal(t, service.TEST_USER_TWO_USERNAME, reply.Author_Info.Username)
+
+	nested := replyAs(t, r, service.TEST_USER_ONE, reply.Post_ID, "thanks", true)
+
+	assert.Equal(t, reply.Post_ID, nested.In_Reply_To_Post_ID)
+	assert.Equal(t, post.Post_ID, nested.Conversation_ID, "Nested replies should stay in the conversation")
+
+	fetched := serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/"+post.Post_ID, nil)
+	_ = json.Unmarshal(fetched.Body.Bytes(), &post)
+
+	assert.Equal(t, 1, post.Replies, "Only direct replies should count")
+
+	private := makeAndAssertPost(service.TEST_USER_ONE, "for my followers", []string{}, []string{}, false, "", r, t)
+
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_THREE, false, "POST", "/twitsnap/reply/"+private.Post_ID, PostBody{Content: "hi"}).Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/reply/missing", PostBody{Content: "hi"}).Code)
+	assert.Equal(t, http.StatusBadRequest, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/reply/"+post.Post_ID, PostBody{}).Code)
+}
+
+func TestReplyOfARetweetRepliesToTheOriginal(t *testing.T) {
+	log.Println("TestReplyOfARetweetRepliesToTheOriginal")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "worth sharing", []string{}, []string{}, true, "", r, t)
+	assert.Equal(t, http.StatusCreated, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/retweet/"+post.Post_ID, nil).Code)
+
+	feed := getRetweetsOf(t, r, service.TEST_USER_TWO)
+	assert.Len(t, feed.Data, 1)
+
+	reply := replyAs(t, r, service.TEST_USER_THREE, feed.Data[0].Post_ID, "indeed", true)
+
+	assert.Equal(t, post.Post_ID, reply.In_Reply_To_Post_ID)
+
+	feed = getRetweetsOf(t, r, service.TEST_USER_TWO)
+	assert.Equal(t, 1, feed.Data[0].Replies, "The retweet should count the replies of its original")
+}
+
+func TestConversationTree(t *testing.T) {
+	log.Println("TestConversationTree")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	root := makeAndAssertPost(service.TEST_USER_ONE, "root", []string{}, []string{}, true, "", r, t)
+	first := replyAs(t, r, service.TEST_USER_TWO, root.Post_ID, "first", true)
+	second := replyAs(t, r, service.TEST_USER_THREE, root.Post_ID, "second", true)
+	nested := replyAs(t, r, service.TEST_USER_ONE, first.Post_ID, "nested", true)
+
+	for _, postID := range []string{root.Post_ID, nested.Post_ID} {
+		tree := getConversationTree(t, r, service.TEST_USER_ONE, postID)
+
+		assert.Equal(t, root.Post_ID, tree.Post.Post_ID, "Any post should show the whole conversation")
+		assert.Equal(t, 2, tree.Post.Replies)
+		assert.Equal(t, []string{first.Post_ID, second.Post_ID}, replyIDs(tree))
+		assert.Equal(t, []string{nested.Post_ID}, replyIDs(tree.Replies[0]))
+		assert.Equal(t, []string{}, replyIDs(tree.Replies[1]))
+		assert.Equal(t, service.TEST_USER_TWO_USERNAME, tree.Replies[0].Post.Author_Info.Username)
+	}
+
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/conversation/missing", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/conversation/"+root.Post_ID+"?mode=list", nil).Code)
+}
+
+func TestConversationThreadPagination(t *testing.T) {
+	log.Println("TestConversationThreadPagination")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	root := makeAndAssertPost(service.TEST_USER_ONE, "root", []string{}, []string{}, true, "", r, t)
+	first := replyAs(t, r, service.TEST_USER_TWO, root.Post_ID, "first", true)
+	second := replyAs(t, r, service.TEST_USER_THREE, root.Post_ID, "second", true)
+	nested := replyAs(t, r, service.TEST_USER_ONE, first.Post_ID, "nested", true)
+
+	page := getConversationThread(t, r, service.TEST_USER_ONE, root.Post_ID, "limit=3")
+
+	assert.Equal(t, []string{root.Post_ID, first.Post_ID, nested.Post_ID}, postIDs(page.Data), "Replies should follow the post they reply to")
+	assert.Equal(t, 3, page.Pagination.Next_Offset)
+
+	page = getConversationThread(t, r, service.TEST_USER_ONE, root.Post_ID, "limit=3&skip=3")
+
+	assert.Equal(t, []string{second.Post_ID}, postIDs(page.Data))
+	assert.Equal(t, 0, page.Pagination.Next_Offset)
+	assert.Equal(t, "", page.Pagination.Next_Cursor)
+
+	page = getConversationThread(t, r, service.TEST_USER_ONE, root.Post_ID, "limit=2")
+
+	assert.Equal(t, []string{root.Post_ID, first.Post_ID}, postIDs(page.Data))
+	assert.NotEqual(t, "", page.Pagination.Next_Cursor)
+
+	page = getConversationThread(t, r, service.TEST_USER_ONE, root.Post_ID, "limit=2&cursor="+page.Pagination.Next_Cursor)
+
+	assert.Equal(t, []string{nested.Post_ID, second.Post_ID}, postIDs(page.Data))
+	assert.Equal(t, 0, page.Pagination.Next_Offset, "Cursor pages should not return an offset")
+	assert.Equal(t, "", page.Pagination.Next_Cursor)
+
+	assert.Equal(t, http.StatusBadRequest, serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/conversation/"+root.Post_ID+"?mode=thread&cursor=not-a-cursor", nil).Code)
+}
+
+func TestConversationTombstones(t *testing.T) {
+	log.Println("TestConversationTombstones")
+
+	db := connectToDatabase()
+	users := service.NewFakeUsersClient()
+	users.SetFollowing(service.TEST_USER_THREE, []string{})
+	r := router.CreateRouter(db, users, service.NewFakeNotificationClient())
+
+	root := makeAndAssertPost(service.TEST_USER_ONE, "root", []string{}, []string{}, true, "", r, t)
+	deleted := replyAs(t, r, service.TEST_USER_TWO, root.Post_ID, "soon gone", true)
+	blocked := replyAs(t, r, service.TEST_USER_TWO, root.Post_ID, "soon blocked", true)
+	private := replyAs(t, r, service.TEST_USER_TWO, root.Post_ID, "followers only", false)
+	underDeleted := replyAs(t, r, service.TEST_USER_ONE, deleted.Post_ID, "under the deleted one", true)
+	underBlocked := replyAs(t, r, service.TEST_USER_ONE, blocked.Post_ID, "under the blocked one", true)
+	leaf := replyAs(t, r, service.TEST_USER_ONE, private.Post_ID, "a leaf", true)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "DELETE", "/twitsnap/"+deleted.Post_ID, nil).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, true, "POST", "/twitsnap/block/"+blocked.Post_ID, nil).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, false, "DELETE", "/twitsnap/"+leaf.Post_ID, nil).Code)
+
+	tree := getConversationTree(t, r, service.TEST_USER_THREE, root.Post_ID)
+
+	assert.Equal(t, []string{deleted.Post_ID, blocked.Post_ID, private.Post_ID}, replyIDs(tree))
+	assert.Equal(t, 3, tree.Post.Replies)
+
+	for _, node := range tree.Replies {
+		assert.True(t, node.Post.Tombstone, "Deleted, blocked and hidden replies should be tombstones")
+		assert.Equal(t, "", node.Post.Content)
+		assert.Equal(t, "", node.Post.Author_Info.Author_ID)
+	}
+
+	assert.Equal(t, []string{underDeleted.Post_ID}, replyIDs(tree.Replies[0]), "Replies of a deleted post should not be orphaned")
+	assert.Equal(t, []string{underBlocked.Post_ID}, replyIDs(tree.Replies[1]))
+	assert.Equal(t, []string{}, replyIDs(tree.Replies[2]), "A deleted reply without replies should leave nothing")
+	assert.Equal(t, 0, tree.Replies[2].Post.Replies)
+	assert.Equal(t, "under the deleted one", tree.Replies[0].Replies[0].Post.Content)
+
+	tree = getConversationTree(t, r, service.TEST_USER_ONE, root.Post_ID)
+
+	assert.False(t, tree.Replies[2].Post.Tombstone, "Followers should see the private reply")
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_ONE, false, "POST", "/twitsnap/reply/"+blocked.Post_ID, PostBody{Content: "hi"}).Code)
+}
+
+func TestReplyNotificationsSent(t *testing.T) {
+	log.Println("TestReplyNotificationsSent")
+
+	db := connectToDatabase()
+	notifications := service.NewFakeNotificationClient()
+	r := newOutboxRouter(db, notifications)
+	dispatcher := service.NewOutboxDispatcher(db, notifications, service.NewInProcessPublisher(), testOutboxConfig())
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "hi", []string{}, []string{}, true, "", r, t)
+	reply := replyAs(t, r, service.TEST_USER_TWO, post.Post_ID, "hello", true)
+	replyAs(t, r, service.TEST_USER_ONE, reply.Post_ID, "hello to you", true)
+	replyAs(t, r, service.TEST_USER_ONE, post.Post_ID, "talking to myself", true)
+
+	dispatchOutbox(t, dispatcher)
+
+	assert.Equal(t, []models.ReplyNotificationRequest{
+		{UserId: service.TEST_USER_ONE, ReplierId: service.TEST_USER_TWO, PostId: reply.Post_ID, InReplyToPostId: post.Post_ID},
+	}, notifications.Replies()[:1])
+	assert.Len(t, notifications.Replies(), 2, "Replying to yourself should not notify")
+	assert.Equal(t, service.TEST_USER_TWO, notifications.Replies()[1].UserId)
+	assert.Equal(t, 0, len(notifications.Sent()), "Replies are not mentions")
+}
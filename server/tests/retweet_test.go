@@ -5,9 +5,9 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	postErrors "server/src/all_errors"
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 	"testing"
 	"time"
@@ -20,7 +20,7 @@ func TestRetweetAPost(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 	retweeter_id := service.TEST_USER_TWO
@@ -89,7 +89,7 @@ func TestRetweetInFeedFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{"tag1", "tag2"}
 
@@ -166,7 +166,7 @@ func TestUnRetweetAPost(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := "1"
 	retweeter_id := service.TEST_USER_TWO
@@ -231,7 +231,7 @@ func TestRetweetInFeedForyou(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag5"}
 
@@ -297,7 +297,7 @@ func TestRetweetInSingleRetweeterFeed(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{service.TEST_TAG_ONE, "tag2"}
 
@@ -359,7 +359,7 @@ func TestRetweetAndLikeOriginal(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 	retweeter_id := service.TEST_USER_TWO
@@ -407,7 +407,7 @@ func TestRetweetAndLikeOriginalAndRetweet(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 	retweeter_id := service.TEST_USER_TWO
@@ -468,7 +468,7 @@ func TestRetwetSingleAuthorField(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	tags := []string{"tag1", "tag2"}
 
@@ -522,4 +522,49 @@ func TestRetwetSingleAuthorField(t *testing.T) {
 	compareOrderAsExpected(expectedPosts, result.Data, t)
 	assert.Equal(t, 6, result.Pagination.Limit)
 	assert.Equal(t, 0, result.Pagination.Next_Offset)
-}
\ No newline at end of file
+}
+
+func TestUnretweetRemovesTheRetweetFromFeeds(t *testing.T) {
+	log.Println("TestUnretweetRemovesTheRetweetFromFeeds")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "worth sharing", []string{}, []string{}, true, "", r, t)
+	assert.Equal(t, http.StatusCreated, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/retweet/"+post.Post_ID, nil).Code)
+
+	retweets := getRetweetsOf(t, r, service.TEST_USER_TWO).Data
+	assert.Equal(t, 1, len(retweets))
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "DELETE", "/twitsnap/retweet/"+post.Post_ID, nil).Code)
+
+	assert.Empty(t, getRetweetsOf(t, r, service.TEST_USER_TWO).Data)
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_TWO, false, "GET", "/twitsnap/"+retweets[0].Post_ID, nil).Code)
+	assert.NotContains(t, postIDs(getFeedAs(t, r, service.TEST_USER_ONE, FEED_TYPE_F).Data), retweets[0].Post_ID)
+	assert.Equal(t, 0, getPostAs(t, r, service.TEST_USER_ONE, post.Post_ID).Retweets)
+}
+
+func TestRetweetTwice(t *testing.T) {
+	log.Println("TestRetweetTwice")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "worth sharing once", []string{}, []string{}, true, "", r, t)
+
+	assert.Equal(t, http.StatusCreated, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/retweet/"+post.Post_ID, nil).Code)
+
+	recorder := serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/retweet/"+post.Post_ID, nil)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	denial := postErrors.TwitSnapError{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &denial))
+	assert.Equal(t, postErrors.AlreadyRetweeted(post.Post_ID), denial)
+
+	assert.Equal(t, 1, getPostAs(t, r, service.TEST_USER_ONE, post.Post_ID).Retweets)
+	assert.Equal(t, 1, len(getRetweetsOf(t, r, service.TEST_USER_TWO).Data))
+
+	// With the one retweet undone the counter is back to zero, not below.
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "DELETE", "/twitsnap/retweet/"+post.Post_ID, nil).Code)
+	assert.Equal(t, 0, getPostAs(t, r, service.TEST_USER_ONE, post.Post_ID).Retweets)
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/models"
+	"server/src/service"
+)
+
+func editAs(t *testing.T, r *gin.Engine, userID string, postID string, editInfo models.EditPostExpectedFormat) models.FrontPost {
+	recorder := serveAs(t, r, userID, false, "PUT", "/twitsnap/edit/"+postID, editInfo)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	post := models.FrontPost{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &post))
+
+	return post
+}
+
+func getRevisions(t *testing.T, r *gin.Engine, postID string, query string) models.ReturnPaginatedRevisions {
+	recorder := serveAs(t, r, service.TEST_USER_TWO, false, "GET", "/twitsnap/revisions/"+postID+query, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	revisions := models.ReturnPaginatedRevisions{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &revisions))
+
+	return revisions
+}
+
+func TestEditHistory(t *testing.T) {
+	log.Println("TestEditHistory")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "first draft", []string{}, []string{}, true, "", r, t)
+
+	assert.False(t, post.Edited)
+	assert.Equal(t, 0, post.Edits)
+
+	second, third := "second draft #go", "third draft #go"
+	media := models.MediaInfo{Media_URL: "https://example.com/cat.png", Media_Type: "image"}
+
+	editAs(t, r, service.TEST_USER_ONE, post.Post_ID, models.EditPostExpectedFormat{Content: &second})
+	edited := editAs(t, r, service.TEST_USER_ONE, post.Post_ID, models.EditPostExpectedFormat{Content: &third, MediaInfo: &media})
+
+	assert.True(t, edited.Edited)
+	assert.Equal(t, 2, edited.Edits)
+	assert.Equal(t, 2, getPostAs(t, r, service.TEST_USER_TWO, post.Post_ID).Edits)
+
+	revisions := getRevisions(t, r, post.Post_ID, "")
+
+	assert.Equal(t, post.Post_ID, revisions.Post_ID)
+	assert.Equal(t, []int{2, 1}, revisionNumbers(revisions.Data), "Revisions should be listed newest first")
+	assert.Equal(t, "second draft #go", *revisions.Data[0].Before.Content)
+	assert.Equal(t, media, *revisions.Data[0].After.Media_Info)
+	assert.Equal(t, post.Media_Info, *revisions.Data[0].Before.Media_Info)
+	assert.Nil(t, revisions.Data[1].After.Media_Info)
+	assert.Equal(t, "first draft", *revisions.Data[1].Before.Content)
+	assert.Equal(t, service.TEST_USER_ONE, revisions.Data[1].Editor_ID)
+	assert.False(t, revisions.Data[1].Edited_At.IsZero())
+
+	page := getRevisions(t, r, post.Post_ID, "?limit=1")
+
+	assert.Equal(t, []int{2}, revisionNumbers(page.Data))
+	assert.Equal(t, 1, page.Pagination.Next_Offset)
+	assert.Equal(t, []int{1}, revisionNumbers(getRevisions(t, r, post.Post_ID, "?limit=1&skip=1").Data))
+
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_TWO, false, "GET", "/twitsnap/revisions/missing", nil).Code)
+}
+
+func TestRevertPost(t *testing.T) {
+	log.Println("TestRevertPost")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	post := makeAndAssertPost(service.TEST_USER_ONE, "what I meant", []string{}, []string{}, true, "", r, t)
+
+	content := "what I regret"
+	editAs(t, r, service.TEST_USER_ONE, post.Post_ID, models.EditPostExpectedFormat{Content: &content})
+
+	revert := models.RevertExpectedFormat{Revision: new(int)}
+
+	assert.Equal(t, http.StatusForbidden, serveAs(t, r, service.TEST_USER_ONE, false, "POST", "/twitsnap/revert/"+post.Post_ID, revert).Code, "Only admins should revert posts")
+
+	recorder := serveAs(t, r, service.TEST_USER_TWO, true, "POST", "/twitsnap/revert/"+post.Post_ID, revert)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	reverted := models.FrontPost{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &reverted))
+
+	assert.Equal(t, "what I meant", reverted.Content)
+	assert.Equal(t, 2, reverted.Edits, "A revert should be a revision of its own")
+	assert.Equal(t, service.TEST_USER_ONE_USERNAME, reverted.Author_Info.Username)
+
+	revisions := getRevisions(t, r, post.Post_ID, "")
+
+	assert.Equal(t, 0, *revisions.Data[0].Reverted_To)
+	assert.Equal(t, service.TEST_USER_TWO, revisions.Data[0].Editor_ID)
+	assert.Nil(t, revisions.Data[1].Reverted_To)
+
+	missing := 7
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_TWO, true, "POST", "/twitsnap/revert/"+post.Post_ID, models.RevertExpectedFormat{Revision: &missing}).Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(t, r, service.TEST_USER_TWO, true, "POST", "/twitsnap/revert/missing", revert).Code)
+	assert.Equal(t, http.StatusBadRequest, serveAs(t, r, service.TEST_USER_TWO, true, "POST", "/twitsnap/revert/"+post.Post_ID, models.RevertExpectedFormat{}).Code)
+}
@@ -8,7 +8,6 @@ import (
 
 	postErrors "server/src/all_errors"
 	"server/src/auth"
-	"server/src/router"
 	"server/src/service"
 
 	"testing"
@@ -22,7 +21,7 @@ func TestBadRouterRoute(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 	token, err := auth.GenerateToken(service.TEST_USER_INFO_ERROR, "username", true)
 
 	getPost, _ := http.NewRequest("POST", "/twitsnap/likers/"+"bad", nil)
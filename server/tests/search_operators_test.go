@@ -0,0 +1,105 @@
+package test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	postErrors "server/src/all_errors"
+	"server/src/models"
+	"server/src/service"
+)
+
+func TestSearchOperators(t *testing.T) {
+	log.Println("TestSearchOperators")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	photo := makeAndAssertPost(service.TEST_USER_ONE, "coffee with a photo #morning", []string{"morning"}, []string{service.TEST_USER_TWO}, true, "url", r, t)
+	sugar := makeAndAssertPost(service.TEST_USER_TWO, "coffee without sugar", []string{}, []string{}, true, "", r, t)
+	tea := makeAndAssertPost(service.TEST_USER_THREE, "tea time #morning", []string{"morning"}, []string{}, true, "", r, t)
+
+	recorder := serveAs(t, r, service.TEST_USER_THREE, false, "POST", "/twitsnap/retweet/"+sugar.Post_ID, nil)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(service.SEARCH_DATE_FORMAT)
+
+	searches := map[string][]string{
+		"coffee -sugar":                               {photo.Post_ID},
+		"#morning":                                    {tea.Post_ID, photo.Post_ID},
+		"from:" + service.TEST_USER_ONE_USERNAME:      {photo.Post_ID},
+		"@" + service.TEST_USER_TWO_USERNAME:          {photo.Post_ID},
+		"coffee has:media":                            {photo.Post_ID},
+		"#morning since:2000-01-01 until:" + tomorrow: {tea.Post_ID, photo.Post_ID},
+		"from:nobody":                                 {},
+	}
+
+	for query, expected := range searches {
+		code, result := searchWords(t, r, query, "&limit=10")
+
+		assert.Equal(t, http.StatusOK, code, query)
+		assert.Equal(t, expected, postIDs(result.Data), query)
+	}
+
+	code, result := searchWords(t, r, "is:retweet", "&limit=10")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, result.Data, 1)
+	assert.True(t, result.Data[0].Is_Retweet)
+	assert.Equal(t, sugar.Post_ID, result.Data[0].Original_Post_ID)
+}
+
+func TestSearchOperatorErrors(t *testing.T) {
+	log.Println("TestSearchOperatorErrors")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	recorder := serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/word-search?limit=10&words="+url.QueryEscape(`from: since:yesterday has:video -#tag "open`), nil)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	searchErr := postErrors.TwitSnapError{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &searchErr))
+	assert.Equal(t, http.StatusBadRequest, searchErr.ErrorStatus)
+	assert.Contains(t, searchErr.Detail, "missing its closing quote")
+	assert.Contains(t, searchErr.Detail, "from: needs a value")
+	assert.Contains(t, searchErr.Detail, "since:yesterday: dates look like")
+	assert.Contains(t, searchErr.Detail, "only has:media")
+	assert.Contains(t, searchErr.Detail, "only words can be excluded")
+
+	for _, query := range []string{"since:2024-02-01 until:2024-01-01", "is:retweet", "from:@"} {
+		code, _ := searchWords(t, r, query, "&sort=relevance&limit=10")
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
+
+func TestHashtagSearchTakesAQuery(t *testing.T) {
+	log.Println("TestHashtagSearchTakesAQuery")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	own := makeAndAssertPost(service.TEST_USER_ONE, "mine #go", []string{"go"}, []string{}, true, "", r, t)
+	makeAndAssertPost(service.TEST_USER_TWO, "theirs #go", []string{"go"}, []string{}, true, "", r, t)
+
+	from := url.QueryEscape(time.Now().Add(time.Minute).Format(time.RFC3339))
+	query := url.QueryEscape("from:" + service.TEST_USER_ONE_USERNAME)
+
+	recorder := serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/hashtag-search?tags=go&q="+query+"&limit=10&time="+from, nil)
+
+	result := models.ReturnPaginatedPosts{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &result)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, []string{own.Post_ID}, postIDs(result.Data))
+
+	recorder = serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/hashtag-search?q="+url.QueryEscape("#go has:"), nil)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
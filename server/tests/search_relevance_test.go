@@ -0,0 +1,97 @@
+package test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/models"
+	"server/src/service"
+)
+
+func searchWords(t *testing.T, r *gin.Engine, words string, query string) (int, models.ReturnPaginatedPosts) {
+	from := url.QueryEscape(time.Now().Add(time.Minute).Format(time.RFC3339))
+	recorder := serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/word-search?time="+from+"&words="+url.QueryEscape(words)+query, nil)
+
+	result := models.ReturnPaginatedPosts{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &result)
+
+	return recorder.Code, result
+}
+
+func TestWordSearchByRelevance(t *testing.T) {
+	log.Println("TestWordSearchByRelevance")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	once := makeAndAssertPost(service.TEST_USER_ONE, "a long post that talks about coffee once", []string{}, []string{}, true, "", r, t)
+	twice := makeAndAssertPost(service.TEST_USER_TWO, "coffee and more coffee", []string{}, []string{}, true, "", r, t)
+	both := makeAndAssertPost(service.TEST_USER_THREE, "coffee with cake", []string{}, []string{}, true, "", r, t)
+
+	code, latest := searchWords(t, r, "coffee cake", "&limit=10")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{both.Post_ID, twice.Post_ID, once.Post_ID}, postIDs(latest.Data))
+
+	code, first := searchWords(t, r, "coffee cake", "&sort=relevance&limit=2")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{both.Post_ID, twice.Post_ID}, postIDs(first.Data))
+	assert.Equal(t, 2, first.Pagination.Next_Offset)
+	assert.Equal(t, "", first.Pagination.Next_Cursor, "Relevance is paged by offset only")
+
+	_, second := searchWords(t, r, "coffee cake", "&sort=relevance&limit=2&skip=2")
+
+	assert.Equal(t, []string{once.Post_ID}, postIDs(second.Data))
+	assert.Equal(t, 0, second.Pagination.Next_Offset)
+
+	_, newest := searchWords(t, r, "coffee", "&limit=1")
+
+	code, _ = searchWords(t, r, "coffee", "&sort=relevance&limit=1&cursor="+newest.Pagination.Next_Cursor)
+	assert.Equal(t, http.StatusBadRequest, code, "Relevance cannot be paged by cursor")
+}
+
+func TestWordSearchPhrases(t *testing.T) {
+	log.Println("TestWordSearchPhrases")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	phrase := makeAndAssertPost(service.TEST_USER_ONE, "The Quick brown fox", []string{}, []string{}, true, "", r, t)
+	makeAndAssertPost(service.TEST_USER_ONE, "brown and quick", []string{}, []string{}, true, "", r, t)
+
+	code, result := searchWords(t, r, `"quick  brown"`, "&limit=10")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{phrase.Post_ID}, postIDs(result.Data))
+
+	code, _ = searchWords(t, r, `"quick brown`, "")
+	assert.Equal(t, http.StatusBadRequest, code, "An unclosed quote should be rejected")
+}
+
+func TestWordSearchTreatsSymbolsAsText(t *testing.T) {
+	log.Println("TestWordSearchTreatsSymbolsAsText")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	makeAndAssertPost(service.TEST_USER_ONE, "nothing to see", []string{}, []string{}, true, "", r, t)
+
+	code, result := searchWords(t, r, ".*", "")
+	assert.Equal(t, http.StatusBadRequest, code, "A search without words should be rejected")
+	assert.Empty(t, result.Data)
+
+	code, result = searchWords(t, r, "(see", "&limit=10")
+	assert.Equal(t, http.StatusOK, code, "Symbols should not be read as a pattern")
+	assert.Len(t, result.Data, 1)
+
+	code, _ = searchWords(t, r, "see", "&sort=oldest")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
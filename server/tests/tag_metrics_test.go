@@ -0,0 +1,121 @@
+package test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/models"
+	"server/src/service"
+)
+
+func getTagMetrics(t *testing.T, r *gin.Engine, tag string, from time.Time, to time.Time, interval string) (int, models.TagMetrics) {
+	query := url.Values{}
+	query.Set("time", from.Format(time.RFC3339))
+	query.Set("end_time", to.Format(time.RFC3339))
+	if interval != "" {
+		query.Set("interval", interval)
+	}
+
+	recorder := serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/tag-metrics/"+tag+"?"+query.Encode(), nil)
+
+	metrics := models.TagMetrics{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &metrics)
+
+	return recorder.Code, metrics
+}
+
+func TestTagMetricsSeries(t *testing.T) {
+	log.Println("TestTagMetricsSeries")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	now := time.Now().UTC()
+	hour := now.Truncate(time.Hour)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	makeAndAssertPost(service.TEST_USER_ONE, "first #metrics", []string{"metrics"}, []string{}, true, "", r, t)
+	makeAndAssertPost(service.TEST_USER_TWO, "second #metrics #other", []string{"metrics", "other"}, []string{}, true, "", r, t)
+
+	code, metrics := getTagMetrics(t, r, "metrics", hour.Add(-2*time.Hour), hour.Add(time.Hour), models.HOUR_INTERVAL)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "metrics", metrics.Tag)
+	assert.Equal(t, 2, metrics.Total_Tweets)
+	assert.InDelta(t, 2, metrics.Trend, 0.01)
+	assert.Equal(t, models.HOUR_INTERVAL, metrics.Interval)
+	assert.Equal(t, []models.MetricBucket{
+		{Time: hour.Add(-2 * time.Hour).Format(time.RFC3339), Count: 0},
+		{Time: hour.Add(-time.Hour).Format(time.RFC3339), Count: 0},
+		{Time: hour.Format(time.RFC3339), Count: 2},
+	}, metrics.Series, "Empty hours should be filled in")
+
+	code, metrics = getTagMetrics(t, r, "metrics", now.AddDate(0, 0, -1), day.AddDate(0, 0, 1), "")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.DAY_INTERVAL, metrics.Interval, "Days should be the default interval")
+	assert.Equal(t, []models.MetricBucket{
+		{Time: day.AddDate(0, 0, -1).Format(time.RFC3339), Count: 0},
+		{Time: day.Format(time.RFC3339), Count: 2},
+	}, metrics.Series)
+}
+
+func TestTagMetricsFollowEditsAndDeletes(t *testing.T) {
+	log.Println("TestTagMetricsFollowEditsAndDeletes")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	edited := makeAndAssertPost(service.TEST_USER_ONE, "first #metrics", []string{"metrics"}, []string{}, true, "", r, t)
+	deleted := makeAndAssertPost(service.TEST_USER_ONE, "second #metrics", []string{"metrics"}, []string{}, true, "", r, t)
+	makeAndAssertPost(service.TEST_USER_TWO, "third #metrics", []string{"metrics"}, []string{}, true, "", r, t)
+
+	content := "first #changed"
+
+	assert.Equal(t, http.StatusOK, serveAs(t, r, service.TEST_USER_ONE, false, "PUT", "/twitsnap/edit/"+edited.Post_ID, models.EditPostExpectedFormat{Content: &content}).Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, false, "DELETE", "/twitsnap/"+deleted.Post_ID, nil).Code)
+
+	now := time.Now().UTC()
+
+	_, metrics := getTagMetrics(t, r, "metrics", now.Add(-time.Minute), now.Add(time.Second), models.HOUR_INTERVAL)
+
+	assert.Equal(t, 1, metrics.Total_Tweets)
+
+	total := 0
+	for _, bucket := range metrics.Series {
+		total += bucket.Count
+	}
+	assert.Equal(t, 1, total)
+
+	_, metrics = getTagMetrics(t, r, "changed", now.Add(-time.Minute), now.Add(time.Second), models.HOUR_INTERVAL)
+
+	assert.Equal(t, 1, metrics.Total_Tweets)
+}
+
+func TestTagMetricsBadRange(t *testing.T) {
+	log.Println("TestTagMetricsBadRange")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	now := time.Now().UTC()
+
+	code, _ := getTagMetrics(t, r, "metrics", now, now.Add(-time.Hour), models.DAY_INTERVAL)
+	assert.Equal(t, http.StatusBadRequest, code, "The range should not end before it starts")
+
+	code, _ = getTagMetrics(t, r, "metrics", now.Add(-time.Hour), now, "minute")
+	assert.Equal(t, http.StatusBadRequest, code, "Unknown intervals should be rejected")
+
+	code, _ = getTagMetrics(t, r, "metrics", now.AddDate(-1, 0, 0), now, models.HOUR_INTERVAL)
+	assert.Equal(t, http.StatusBadRequest, code, "Too many buckets should be rejected")
+
+	recorder := serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/tag-metrics/metrics?time=yesterday", nil)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
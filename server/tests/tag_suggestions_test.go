@@ -0,0 +1,85 @@
+package test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/models"
+	"server/src/router"
+	"server/src/service"
+)
+
+func getTagSuggestions(t *testing.T, r *gin.Engine, userID string, query string) (int, models.ReturnTagSuggestions) {
+	recorder := serveAs(t, r, userID, false, "GET", "/twitsnap/tag-suggestions"+query, nil)
+
+	result := models.ReturnTagSuggestions{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &result)
+
+	return recorder.Code, result
+}
+
+func suggestedTags(result models.ReturnTagSuggestions) []string {
+	tags := []string{}
+	for _, suggestion := range result.Data {
+		tags = append(tags, suggestion.Tag)
+	}
+	return tags
+}
+
+func TestTagSuggestionsCompleteThePrefix(t *testing.T) {
+	log.Println("TestTagSuggestionsCompleteThePrefix")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	makeAndAssertPost(service.TEST_USER_ONE, "first #Música", []string{"Música"}, []string{}, true, "", r, t)
+	makeAndAssertPost(service.TEST_USER_TWO, "second #musica #museo", []string{"musica", "museo"}, []string{}, true, "", r, t)
+	makeAndAssertPost(service.TEST_USER_THREE, "third #musica", []string{"musica"}, []string{}, true, "", r, t)
+	makeAndAssertPost(service.TEST_USER_ONE, "other #cine", []string{"cine"}, []string{}, true, "", r, t)
+
+	code, result := getTagSuggestions(t, r, service.TEST_USER_ONE, "?prefix="+url.QueryEscape("#MÚSI"))
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "#MÚSI", result.Prefix)
+	assert.Equal(t, []string{"musica"}, suggestedTags(result))
+	assert.Equal(t, 3, result.Data[0].Posts)
+
+	code, result = getTagSuggestions(t, r, service.TEST_USER_ONE, "?prefix=mu&limit=1")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"musica"}, suggestedTags(result), "The most used tag should come first")
+
+	_, result = getTagSuggestions(t, r, service.TEST_USER_ONE, "?prefix=mu")
+	assert.Equal(t, []string{"musica", "museo"}, suggestedTags(result))
+
+	for _, prefix := range []string{"", "%23", "."} {
+		code, _ = getTagSuggestions(t, r, service.TEST_USER_ONE, "?prefix="+prefix)
+		assert.Equal(t, http.StatusBadRequest, code, prefix)
+	}
+}
+
+func TestTagSuggestionsOnlyFromVisiblePosts(t *testing.T) {
+	log.Println("TestTagSuggestionsOnlyFromVisiblePosts")
+
+	db := connectToDatabase()
+	users := service.NewFakeUsersClient()
+	users.SetFollowing(service.TEST_USER_THREE, []string{})
+	r := router.CreateRouter(db, users, service.NewFakeNotificationClient())
+
+	makeAndAssertPost(service.TEST_USER_ONE, "#secretplan", []string{"secretplan"}, []string{}, false, "", r, t)
+	blocked := makeAndAssertPost(service.TEST_USER_TWO, "#secretcode", []string{"secretcode"}, []string{}, true, "", r, t)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, true, "POST", "/twitsnap/block/"+blocked.Post_ID, nil).Code)
+
+	_, result := getTagSuggestions(t, r, service.TEST_USER_TWO, "?prefix=secret")
+	assert.Equal(t, []string{"secretplan"}, suggestedTags(result), "Followers should see private tags but not blocked ones")
+
+	_, result = getTagSuggestions(t, r, service.TEST_USER_THREE, "?prefix=secret")
+	assert.Empty(t, result.Data)
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	postErrors "server/src/all_errors"
+	"server/src/auth"
+	"server/src/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedTimeout(t *testing.T) {
+	log.Println("TestFeedTimeout")
+
+	t.Setenv("TIMEOUT_FEED", "1ns")
+
+	db := connectToDatabase()
+
+	r := createRouter(db)
+
+	token, err := auth.GenerateToken(service.TEST_USER_ONE, "username", false)
+	assert.Equal(t, err, nil, "Error should be nil")
+
+	time := time.Now().Format(time.RFC3339)
+
+	getFeed, _ := http.NewRequest("GET", "/twitsnap/feed?time="+time+"&skip=0&limit=6&feed_type="+FEED_TYPE_F+"&wanted_user_id=", nil)
+	addAuthorization(getFeed, token)
+
+	feedRecorder := httptest.NewRecorder()
+	r.ServeHTTP(feedRecorder, getFeed)
+
+	result := postErrors.TwitSnapError{}
+	err = json.Unmarshal(feedRecorder.Body.Bytes(), &result)
+
+	assert.Equal(t, err, nil, "Error should be nil")
+	assert.Equal(t, http.StatusGatewayTimeout, feedRecorder.Code, "Status should be 504")
+	assert.Equal(t, "Request Timeout", result.Title)
+}
+
+func TestPostWithinDeadline(t *testing.T) {
+	log.Println("TestPostWithinDeadline")
+
+	t.Setenv("TIMEOUT_WRITE", "30s")
+
+	db := connectToDatabase()
+
+	r := createRouter(db)
+
+	makeAndAssertPost(service.TEST_USER_ONE, "content #tag1", []string{"tag1"}, []string{}, true, "", r, t)
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"server/src/models"
+	"server/src/router"
+	"server/src/service"
+)
+
+func getTrendingPosts(t *testing.T, r *gin.Engine, userID string, query string) (int, models.ReturnPaginatedPosts) {
+	recorder := serveAs(t, r, userID, false, "GET", "/twitsnap/trending-posts"+query, nil)
+
+	result := models.ReturnPaginatedPosts{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &result)
+
+	return recorder.Code, result
+}
+
+func postIDs(posts []models.FrontPost) []string {
+	ids := []string{}
+	for _, post := range posts {
+		ids = append(ids, post.Post_ID)
+	}
+	return ids
+}
+
+func TestTrendingPostsRankedByEngagement(t *testing.T) {
+	log.Println("TestTrendingPostsRankedByEngagement")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	quiet := makeAndAssertPost(service.TEST_USER_ONE, "quiet", []string{}, []string{}, true, "", r, t)
+	liked := makeAndAssertPost(service.TEST_USER_ONE, "liked", []string{}, []string{}, true, "", r, t)
+	retweeted := makeAndAssertPost(service.TEST_USER_TWO, "retweeted", []string{}, []string{}, true, "", r, t)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_TWO, false, "POST", "/twitsnap/like/"+liked.Post_ID, nil).Code)
+	assert.Equal(t, http.StatusCreated, serveAs(t, r, service.TEST_USER_THREE, false, "POST", "/twitsnap/retweet/"+retweeted.Post_ID, nil).Code)
+
+	code, result := getTrendingPosts(t, r, service.TEST_USER_ONE, "?window=1h")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{retweeted.Post_ID, liked.Post_ID, quiet.Post_ID}, postIDs(result.Data), "The retweet should only count for its original")
+	assert.Equal(t, 1, result.Data[0].Retweets)
+
+	code, first := getTrendingPosts(t, r, service.TEST_USER_ONE, "?limit=2")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{retweeted.Post_ID, liked.Post_ID}, postIDs(first.Data))
+	assert.Equal(t, 2, first.Pagination.Next_Offset)
+	assert.NotEqual(t, "", first.Pagination.Next_Cursor)
+
+	_, second := getTrendingPosts(t, r, service.TEST_USER_ONE, "?limit=2&skip=2")
+
+	assert.Equal(t, []string{quiet.Post_ID}, postIDs(second.Data))
+	assert.Equal(t, 0, second.Pagination.Next_Offset)
+
+	code, _ = getTrendingPosts(t, r, service.TEST_USER_ONE, "?window=30d")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestTrendingPostsPagedByCursor(t *testing.T) {
+	log.Println("TestTrendingPostsPagedByCursor")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	likeAs := func(post models.FrontPost, likers ...string) {
+		for _, liker := range likers {
+			assert.Equal(t, http.StatusNoContent, serveAs(t, r, liker, false, "POST", "/twitsnap/like/"+post.Post_ID, nil).Code)
+		}
+	}
+
+	top := makeAndAssertPost(service.TEST_USER_ONE, "top", []string{}, []string{}, true, "", r, t)
+	middle := makeAndAssertPost(service.TEST_USER_ONE, "middle", []string{}, []string{}, true, "", r, t)
+	bottom := makeAndAssertPost(service.TEST_USER_ONE, "bottom", []string{}, []string{}, true, "", r, t)
+	likeAs(top, service.TEST_USER_TWO, service.TEST_USER_THREE)
+	likeAs(middle, service.TEST_USER_TWO)
+
+	code, first := getTrendingPosts(t, r, service.TEST_USER_ONE, "?limit=2")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{top.Post_ID, middle.Post_ID}, postIDs(first.Data))
+
+	// A post that climbs to the top between two pages would push the last
+	// post of the first page onto the second one if it were ranked again.
+	// Post times are kept to the millisecond, so it is posted a bit later.
+	time.Sleep(10 * time.Millisecond)
+	climber := makeAndAssertPost(service.TEST_USER_TWO, "climber", []string{}, []string{}, true, "", r, t)
+	likeAs(climber, service.TEST_USER_ONE, service.TEST_USER_TWO, service.TEST_USER_THREE)
+
+	code, second := getTrendingPosts(t, r, service.TEST_USER_ONE, "?limit=2&cursor="+first.Pagination.Next_Cursor)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{bottom.Post_ID}, postIDs(second.Data))
+	assert.Equal(t, "", second.Pagination.Next_Cursor)
+
+	_, again := getTrendingPosts(t, r, service.TEST_USER_ONE, "?limit=2")
+	assert.Equal(t, climber.Post_ID, again.Data[0].Post_ID, "A new ranking should see the new post")
+
+	code, _ = getTrendingPosts(t, r, service.TEST_USER_ONE, "?cursor=nonsense")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestTrendingPostsRespectPrivacyAndBlocks(t *testing.T) {
+	log.Println("TestTrendingPostsRespectPrivacyAndBlocks")
+
+	db := connectToDatabase()
+	users := service.NewFakeUsersClient()
+	users.SetFollowing(service.TEST_USER_THREE, []string{})
+	r := router.CreateRouter(db, users, service.NewFakeNotificationClient())
+
+	public := makeAndAssertPost(service.TEST_USER_ONE, "public", []string{}, []string{}, true, "", r, t)
+	private := makeAndAssertPost(service.TEST_USER_ONE, "private", []string{}, []string{}, false, "", r, t)
+	blocked := makeAndAssertPost(service.TEST_USER_TWO, "blocked", []string{}, []string{}, true, "", r, t)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, true, "POST", "/twitsnap/block/"+blocked.Post_ID, nil).Code)
+
+	_, result := getTrendingPosts(t, r, service.TEST_USER_TWO, "")
+	assert.ElementsMatch(t, []string{public.Post_ID, private.Post_ID}, postIDs(result.Data), "Followers should see private posts")
+
+	_, result = getTrendingPosts(t, r, service.TEST_USER_ONE, "")
+	assert.ElementsMatch(t, []string{public.Post_ID, private.Post_ID}, postIDs(result.Data), "Authors should see their private posts")
+
+	_, result = getTrendingPosts(t, r, service.TEST_USER_THREE, "")
+	assert.Equal(t, []string{public.Post_ID}, postIDs(result.Data))
+}
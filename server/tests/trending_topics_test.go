@@ -1,25 +1,44 @@
 package test
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"server/src/auth"
-	"server/src/router"
+	"server/src/models"
 	"server/src/service"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
+func getTrending(t *testing.T, r *gin.Engine, query string) (int, models.ReturnPaginatesdTrendingTopics) {
+	recorder := serveAs(t, r, service.TEST_USER_ONE, false, "GET", "/twitsnap/trending"+query, nil)
+
+	result := models.ReturnPaginatesdTrendingTopics{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &result)
+
+	return recorder.Code, result
+}
+
+func topicTags(topics []models.TrendingTopic) []string {
+	tags := []string{}
+	for _, topic := range topics {
+		tags = append(tags, topic.Tag)
+	}
+	return tags
+}
+
 func TestGetTrending(t *testing.T) {
 	log.Println("TestGetTrending")
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	author_id := service.TEST_USER_ONE
 
@@ -43,16 +62,89 @@ func TestGetTrending(t *testing.T) {
 	second := httptest.NewRecorder()
 	r.ServeHTTP(second, getPostLiked)
 
-	result_post := []string{}
+	result := models.ReturnPaginatesdTrendingTopics{}
 
-	err = json.Unmarshal(second.Body.Bytes(), &result_post)
+	err = json.Unmarshal(second.Body.Bytes(), &result)
 
 	assert.Equal(t, err, nil, "Error should be nil")
 	
 	assert.Equal(t, http.StatusOK, second.Code, "Status should be 200")
-	assert.Equal(t, len(result_post), 2, "Should have 2 trending topics")
+	assert.Equal(t, models.TRENDING_DAY_WINDOW, result.Window, "The window should default to a day")
+	assert.Equal(t, len(result.Topics), 2, "Should have 2 trending topics")
 	
 	for i := 0; i < len(tags); i++ {
-		assert.Equal(t, result_post[i], tags[i], "Trending topic should be " + tags[i])
+		assert.Equal(t, result.Topics[i].Tag, tags[i], "Trending topic should be " + tags[i])
+	}
+
+	assert.Equal(t, 2, result.Topics[0].Posts)
+	assert.Greater(t, result.Topics[0].Score, result.Topics[1].Score)
+}
+
+func TestTrendingSkipsPrivateAndBlocked(t *testing.T) {
+	log.Println("TestTrendingSkipsPrivateAndBlocked")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	makeAndAssertPost(service.TEST_USER_ONE, "#shown", []string{"shown"}, []string{}, true, "", r, t)
+	makeAndAssertPost(service.TEST_USER_ONE, "#secret", []string{"secret"}, []string{}, false, "", r, t)
+	blocked := makeAndAssertPost(service.TEST_USER_ONE, "#banned", []string{"banned"}, []string{}, true, "", r, t)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(t, r, service.TEST_USER_ONE, true, "POST", "/twitsnap/block/"+blocked.Post_ID, nil).Code)
+
+	code, result := getTrending(t, r, "?window="+models.TRENDING_HOUR_WINDOW)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.TRENDING_HOUR_WINDOW, result.Window)
+	assert.Equal(t, []string{"shown"}, topicTags(result.Topics))
+}
+
+func TestTrendingPagination(t *testing.T) {
+	log.Println("TestTrendingPagination")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	makeAndAssertPost(service.TEST_USER_ONE, "#a #b #c", []string{"a", "b", "c"}, []string{}, true, "", r, t)
+	makeAndAssertPost(service.TEST_USER_TWO, "#a #b", []string{"a", "b"}, []string{}, true, "", r, t)
+	makeAndAssertPost(service.TEST_USER_THREE, "#a", []string{"a"}, []string{}, true, "", r, t)
+
+	code, first := getTrending(t, r, "?window=7d&limit=2")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"a", "b"}, topicTags(first.Topics))
+	assert.Equal(t, 2, first.Pagination.Limit)
+	assert.Equal(t, 2, first.Pagination.Next_Offset)
+
+	_, second := getTrending(t, r, "?window=7d&limit=2&skip=2")
+
+	assert.Equal(t, []string{"c"}, topicTags(second.Topics))
+	assert.Equal(t, 0, second.Pagination.Next_Offset, "The last page should not point further")
+	assert.True(t, first.Computed_At.Equal(second.Computed_At), "Pages should come from the same snapshot")
+
+	code, _ = getTrending(t, r, "?window=2h")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestTrendingRefresher(t *testing.T) {
+	log.Println("TestTrendingRefresher")
+
+	db := connectToDatabase()
+	r := createRouter(db)
+
+	_, empty := getTrending(t, r, "")
+	assert.Equal(t, 0, len(empty.Topics))
+
+	makeAndAssertPost(service.TEST_USER_ONE, "#late", []string{"late"}, []string{}, true, "", r, t)
+
+	_, cached := getTrending(t, r, "")
+	assert.Equal(t, 0, len(cached.Topics), "Requests should read the saved snapshot")
+
+	refresher := service.NewTrendingRefresher(db, service.LoadTrendingConfig())
+	assert.Equal(t, nil, refresher.RefreshOnce(context.Background()), "Error should be nil")
+
+	for window := range models.TrendingWindows {
+		_, refreshed := getTrending(t, r, "?window="+window)
+		assert.Equal(t, []string{"late"}, topicTags(refreshed.Topics), window)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	postErrors "server/src/all_errors"
+	"server/src/auth"
+	"server/src/database"
+	"server/src/models"
+	"server/src/router"
+	"server/src/service"
+	"server/src/standin"
+)
+
+// testOutboundConfig keeps the backoff of the tests short.
+func testOutboundConfig() service.OutboundConfig {
+	return service.OutboundConfig{
+		Timeout:         time.Second,
+		Retries:         2,
+		BackoffBase:     time.Millisecond,
+		BackoffMax:      5 * time.Millisecond,
+		BreakerFailures: 5,
+		BreakerCooldown: time.Minute,
+	}
+}
+
+func newUsersClient(serverURL string, config service.OutboundConfig) *service.HTTPUsersClient {
+	return service.NewHTTPUsersClient(strings.TrimPrefix(serverURL, "http://"), config)
+}
+
+// createRouterWithUsersService builds a router whose users client talks over
+// HTTP to a stand-in users service serving users.
+func createRouterWithUsersService(t *testing.T, db database.Database, users ...standin.User) *gin.Engine {
+	server := httptest.NewServer(standin.NewUsersService(users...).Handler())
+	t.Cleanup(server.Close)
+
+	return createRouterWithUsersClient(db, newUsersClient(server.URL, testOutboundConfig()))
+}
+
+func createRouterWithUsersClient(db database.Database, users service.UsersClient) *gin.Engine {
+	return router.CreateRouter(db, users, service.NewFakeNotificationClient())
+}
+
+func getFeedRequest(token string, feedType string) *http.Request {
+	from := time.Now().Add(time.Minute).Format(time.RFC3339)
+
+	getFeed, _ := http.NewRequest("GET", "/twitsnap/feed?time="+from+"&skip=0&limit=6&feed_type="+feedType+"&wanted_user_id=", nil)
+	addAuthorization(getFeed, token)
+
+	return getFeed
+}
+
+func getFeedAs(t *testing.T, r *gin.Engine, userID string, feedType string) models.ReturnPaginatedPosts {
+	token, err := auth.GenerateToken(userID, "username", false)
+	assert.Equal(t, nil, err, "Error should be nil")
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, getFeedRequest(token, feedType))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, "Status should be 200")
+
+	result := models.ReturnPaginatedPosts{}
+	assert.Equal(t, nil, json.Unmarshal(recorder.Body.Bytes(), &result), "Error should be nil")
+
+	return result
+}
+
+func TestFollowingFeedFromUsersService(t *testing.T) {
+	log.Println("TestFollowingFeedFromUsersService")
+
+	db := connectToDatabase()
+	r := createRouterWithUsersService(t, db,
+		standin.User{ID: "a", Username: "ada", FirstName: "Ada", LastName: "Lovelace", Following: []string{"b"}},
+		standin.User{ID: "b", Username: "bob", FirstName: "Bob", LastName: "Builder"},
+		standin.User{ID: "c", Username: "cat", FirstName: "Cat", LastName: "Stevens"},
+	)
+
+	seedPosts(t, db, []string{"b", "c"})
+
+	result := getFeedAs(t, r, "a", FEED_TYPE_F)
+
+	assert.Equal(t, 1, len(result.Data))
+	assert.Equal(t, models.AuthorInfo{Author_ID: "b", Username: "bob", Alias: "Bob Builder"}, result.Data[0].Author_Info)
+}
+
+func TestForyouFeedFromUsersService(t *testing.T) {
+	log.Println("TestForyouFeedFromUsersService")
+
+	db := connectToDatabase()
+	r := createRouterWithUsersService(t, db,
+		standin.User{ID: "a", Username: "ada", Interests: []string{"go"}},
+		standin.User{ID: "b", Username: "bob"},
+	)
+
+	for _, tags := range [][]string{{"go"}, {"rust"}} {
+		_, err := db.AddNewPost(context.Background(), models.NewDBPost("b", "#"+tags[0], tags, true, models.MediaInfo{}, []string{}))
+		assert.Equal(t, nil, err, "Error should be nil")
+	}
+
+	result := getFeedAs(t, r, "a", FEED_TYPE_Y)
+
+	assert.Equal(t, 1, len(result.Data))
+	assert.Equal(t, []string{"go"}, result.Data[0].Tags)
+	assert.Equal(t, "bob", result.Data[0].Author_Info.Username)
+}
+
+func TestUserIDFromUsersService(t *testing.T) {
+	log.Println("TestUserIDFromUsersService")
+
+	server := httptest.NewServer(standin.NewUsersService(standin.User{ID: "b", Username: "bob"}).Handler())
+	defer server.Close()
+
+	client := newUsersClient(server.URL, testOutboundConfig())
+
+	id, err := client.GetUserID(context.Background(), "bob", "token")
+	assert.Nil(t, err)
+	assert.Equal(t, "b", id)
+
+	_, err = client.GetUserID(context.Background(), "nobody", "token")
+	assert.True(t, errors.Is(err, service.ErrUnknownUser))
+}
+
+func TestUsersServiceFailure(t *testing.T) {
+	log.Println("TestUsersServiceFailure")
+
+	db := connectToDatabase()
+	users := service.NewFakeUsersClient()
+	users.Fail(service.TEST_USER_ONE, errors.New("users down"))
+	r := router.CreateRouter(db, users, service.NewFakeNotificationClient())
+
+	token, err := auth.GenerateToken(service.TEST_USER_ONE, "username", false)
+	assert.Equal(t, nil, err, "Error should be nil")
+
+	req := newPostRequest(PostBody{Content: "hi", Public: true, Mentions: []string{}})
+	addAuthorization(req, token)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	result := postErrors.TwitSnapError{}
+	assert.Equal(t, nil, json.Unmarshal(recorder.Body.Bytes(), &result), "Error should be nil")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "Error getting user info", result.Title)
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"context"
+	"server/src/models"
+	"testing"
+	"time"
+)
+
+// BenchmarkPageViewerState compares resolving user_liked, user_retweet and
+// bookmark for a whole page at once with resolving them post by post, which
+// is what GetPost still does. The difference is in round trips, so it only
+// runs against MongoDB.
+func BenchmarkPageViewerState(b *testing.B) {
+	if testDatabaseBackend() != MONGO_BACKEND {
+		b.Skip("MONGO_URI is not set")
+	}
+
+	ctx := context.Background()
+	db := connectToDatabase()
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Millisecond)
+
+	postIDs := []string{}
+
+	for i := 0; i < models.MAX_LIMIT; i++ {
+		post := models.NewDBPost("1", "benchmark", []string{"tag"}, true, models.MediaInfo{}, []string{})
+		post.Time = base.Add(time.Duration(i) * time.Second)
+
+		if _, err := db.AddNewPost(ctx, post); err != nil {
+			b.Fatal(err)
+		}
+		postIDs = append(postIDs, post.Post_ID)
+
+		if i%2 == 0 {
+			if err := db.LikeAPost(ctx, post.Post_ID, "2"); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if i%3 == 0 {
+			if err := db.AddFavorite(ctx, post.Post_ID, "2"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	limitConfig := models.NewLimitConfig(time.Now().UTC().Format(time.RFC3339), "0", "30")
+
+	b.Run("Page", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, _, err := db.GetAllPosts(ctx, limitConfig, "2"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("PerPost", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, postID := range postIDs {
+				if _, err := db.GetPost(ctx, postID, "2"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	if err := db.ClearDB(ctx); err != nil {
+		b.Fatal(err)
+	}
+}
@@ -15,7 +15,6 @@ import (
 
 	"server/src/auth"
 	"server/src/models"
-	"server/src/router"
 	"server/src/service"
 )
 
@@ -25,7 +24,7 @@ func TestWordsearch(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	words_wanted := "apple pie pecan"
 
@@ -85,7 +84,7 @@ func TestWordSearchNotFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	words_wanted := "apple pie pecan"
 
@@ -142,7 +141,7 @@ func TestWordSearchFollowing(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	words_wanted := "apple pie pecan"
 
@@ -200,7 +199,7 @@ func TestWordSearchNextOffset(t *testing.T) {
 
 	db := connectToDatabase()
 
-	r := router.CreateRouter(db)
+	r := createRouter(db)
 
 	words_wanted := "apple pie pecan"
 